@@ -3,6 +3,7 @@ package pixel
 import (
 	"fmt"
 	"image/color"
+	"math"
 )
 
 // Batch is a Target that allows for efficient drawing of many objects with the same Picture.
@@ -12,8 +13,9 @@ import (
 type Batch struct {
 	cont Drawer
 
-	mat Matrix
-	col RGBA
+	mat  Matrix
+	col  RGBA
+	cull Rect
 }
 
 var _ BasicTarget = (*Batch)(nil)
@@ -68,12 +70,33 @@ func (b *Batch) SetColorMask(c color.Color) {
 	b.col = ToRGBA(c)
 }
 
+// SetCullRect sets a view rectangle used for culling the following draws onto the Batch. Objects
+// whose bounds, projected by the Batch's Matrix, don't intersect the rectangle are skipped and
+// never get appended to the container.
+//
+// Bounds of the drawn Triangles are cached, so the check is cheap. Only Triangles supporting
+// TrianglesPosition can be culled, others are always drawn.
+//
+// Setting a rectangle of zero area (such as pixel.ZR) disables culling, which is the default.
+//
+//   batch.SetCullRect(win.Bounds())
+func (b *Batch) SetCullRect(r Rect) {
+	b.cull = r.Norm()
+}
+
+// CullRect returns the view rectangle used for culling. A rectangle of zero area means that
+// culling is disabled.
+func (b *Batch) CullRect() Rect {
+	return b.cull
+}
+
 // MakeTriangles returns a specialized copy of the provided Triangles that draws onto this Batch.
 func (b *Batch) MakeTriangles(t Triangles) TargetTriangles {
 	bt := &batchTriangles{
-		tri: t.Copy(),
-		tmp: MakeTrianglesData(t.Len()),
-		dst: b,
+		tri:     t.Copy(),
+		tmp:     MakeTrianglesData(t.Len()),
+		dst:     b,
+		version: new(int),
 	}
 	return bt
 }
@@ -94,6 +117,13 @@ type batchTriangles struct {
 	tri Triangles
 	tmp *TrianglesData
 	dst *Batch
+
+	// version is shared with all slices of the Triangles, which share the vertices, and increased
+	// on each change of any of them, so that all of them recompute their cached bounds
+	version       *int
+	bounds        Rect
+	boundsVersion int
+	boundsValid   bool
 }
 
 func (bt *batchTriangles) Len() int {
@@ -103,29 +133,52 @@ func (bt *batchTriangles) Len() int {
 func (bt *batchTriangles) SetLen(len int) {
 	bt.tri.SetLen(len)
 	bt.tmp.SetLen(len)
+	*bt.version++
 }
 
 func (bt *batchTriangles) Slice(i, j int) Triangles {
 	return &batchTriangles{
-		tri: bt.tri.Slice(i, j),
-		tmp: bt.tmp.Slice(i, j).(*TrianglesData),
-		dst: bt.dst,
+		tri:     bt.tri.Slice(i, j),
+		tmp:     bt.tmp.Slice(i, j).(*TrianglesData),
+		dst:     bt.dst,
+		version: bt.version,
 	}
 }
 
 func (bt *batchTriangles) Update(t Triangles) {
 	bt.tri.Update(t)
+	*bt.version++
 }
 
 func (bt *batchTriangles) Copy() Triangles {
 	return &batchTriangles{
-		tri: bt.tri.Copy(),
-		tmp: bt.tmp.Copy().(*TrianglesData),
-		dst: bt.dst,
+		tri:     bt.tri.Copy(),
+		tmp:     bt.tmp.Copy().(*TrianglesData),
+		dst:     bt.dst,
+		version: new(int),
 	}
 }
 
+// culled reports whether the Triangles lie completely outside of the Batch's cull rectangle.
+func (bt *batchTriangles) culled() bool {
+	if bt.dst.cull.Area() == 0 {
+		return false
+	}
+	if !bt.boundsValid || bt.boundsVersion != *bt.version {
+		bt.bounds, bt.boundsValid = trianglesBounds(bt.tri)
+		bt.boundsVersion = *bt.version
+		if !bt.boundsValid {
+			return false
+		}
+	}
+	return !bt.dst.cull.Intersects(projectRect(bt.dst.mat, bt.bounds))
+}
+
 func (bt *batchTriangles) draw(bp *batchPicture) {
+	if bt.culled() {
+		return
+	}
+
 	bt.tmp.Update(bt.tri)
 
 	for i := range *bt.tmp {
@@ -145,6 +198,40 @@ func (bt *batchTriangles) Draw() {
 	bt.draw(nil)
 }
 
+// trianglesBounds returns the bounding box of positions of all vertices of the Triangles. If the
+// Triangles are empty or don't support TrianglesPosition, false is returned.
+func trianglesBounds(t Triangles) (Rect, bool) {
+	tp, ok := t.(TrianglesPosition)
+	if !ok || t.Len() == 0 {
+		return Rect{}, false
+	}
+	p := tp.Position(0)
+	bounds := Rect{Min: p, Max: p}
+	for i := 1; i < t.Len(); i++ {
+		p := tp.Position(i)
+		bounds.Min.X = math.Min(bounds.Min.X, p.X)
+		bounds.Min.Y = math.Min(bounds.Min.Y, p.Y)
+		bounds.Max.X = math.Max(bounds.Max.X, p.X)
+		bounds.Max.Y = math.Max(bounds.Max.Y, p.Y)
+	}
+	return bounds, true
+}
+
+// projectRect returns the bounding box of the Rect projected by the Matrix.
+func projectRect(m Matrix, r Rect) Rect {
+	v := r.Vertices()
+	p := m.Project(v[0])
+	bounds := Rect{Min: p, Max: p}
+	for _, u := range v[1:] {
+		p := m.Project(u)
+		bounds.Min.X = math.Min(bounds.Min.X, p.X)
+		bounds.Min.Y = math.Min(bounds.Min.Y, p.Y)
+		bounds.Max.X = math.Max(bounds.Max.X, p.X)
+		bounds.Max.Y = math.Max(bounds.Max.Y, p.Y)
+	}
+	return bounds
+}
+
 type batchPicture struct {
 	pic Picture
 	dst *Batch
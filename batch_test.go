@@ -0,0 +1,85 @@
+package pixel_test
+
+import (
+	"testing"
+
+	"github.com/faiface/pixel"
+)
+
+func TestBatch_CullRect(t *testing.T) {
+	container := &pixel.TrianglesData{}
+	batch := pixel.NewBatch(container, nil)
+	batch.SetCullRect(pixel.R(0, 0, 100, 100))
+
+	inside := pixel.Drawer{Triangles: triangleAt(pixel.V(50, 50), pixel.Alpha(1))}
+	outside := pixel.Drawer{Triangles: triangleAt(pixel.V(500, 50), pixel.Alpha(1))}
+
+	inside.Draw(batch)
+	outside.Draw(batch)
+	if got, want := container.Len(), 3; got != want {
+		t.Fatalf("container.Len() = %d, want %d", got, want)
+	}
+
+	// the matrix moves the outside triangle into the view
+	batch.SetMatrix(pixel.IM.Moved(pixel.V(-450, 0)))
+	outside.Draw(batch)
+	if got, want := container.Len(), 6; got != want {
+		t.Fatalf("container.Len() = %d, want %d", got, want)
+	}
+
+	batch.SetMatrix(pixel.IM)
+	batch.SetCullRect(pixel.ZR)
+	outside.Draw(batch)
+	if got, want := container.Len(), 9; got != want {
+		t.Fatalf("container.Len() = %d, want %d", got, want)
+	}
+}
+
+func TestBatch_CullRectSlice(t *testing.T) {
+	container := &pixel.TrianglesData{}
+	batch := pixel.NewBatch(container, nil)
+	batch.SetCullRect(pixel.R(0, 0, 100, 100))
+
+	tri := batch.MakeTriangles(triangleAt(pixel.V(500, 50), pixel.Alpha(1)))
+	tri.Draw()
+	if got, want := container.Len(), 0; got != want {
+		t.Fatalf("container.Len() = %d, want %d", got, want)
+	}
+
+	// moving the triangle into the view through a slice changes the bounds of the whole Triangles
+	slice := tri.Slice(0, tri.Len()).(pixel.TargetTriangles)
+	slice.Update(triangleAt(pixel.V(50, 50), pixel.Alpha(1)))
+	tri.Draw()
+	if got, want := container.Len(), 3; got != want {
+		t.Fatalf("container.Len() = %d, want %d", got, want)
+	}
+
+	// and the other way around
+	slice.Draw()
+	tri.Update(triangleAt(pixel.V(500, 50), pixel.Alpha(1)))
+	slice.Draw()
+	if got, want := container.Len(), 6; got != want {
+		t.Fatalf("container.Len() = %d, want %d", got, want)
+	}
+}
+
+func BenchmarkBatchCulling(b *testing.B) {
+	tiles := make([]pixel.Drawer, 0, 100*100)
+	for y := 0; y < 100; y++ {
+		for x := 0; x < 100; x++ {
+			tiles = append(tiles, pixel.Drawer{
+				Triangles: triangleAt(pixel.V(float64(x)*16, float64(y)*16), pixel.Alpha(1)),
+			})
+		}
+	}
+	batch := pixel.NewBatch(&pixel.TrianglesData{}, nil)
+	batch.SetCullRect(pixel.R(0, 0, 320, 240))
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		batch.Clear()
+		for j := range tiles {
+			tiles[j].Draw(batch)
+		}
+	}
+}
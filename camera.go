@@ -0,0 +1,289 @@
+package pixel
+
+import "math"
+
+// Camera is a 2D camera, which produces a Matrix transforming world coordinates to screen
+// coordinates.
+//
+// The Camera is centered at a position in the world, which is shown in the center of the screen
+// rectangle. It can be zoomed and rotated, smoothly follow a target with a deadzone, shake and be
+// clamped to the bounds of the world.
+//
+//   cam := pixel.NewCamera(win.Bounds())
+//   cam.SetBounds(level.Bounds())
+//   for !win.Closed() {
+//       cam.Follow(player.Pos)
+//       cam.Update(dt)
+//       win.SetMatrix(cam.Matrix())
+//       mouse := cam.ScreenToWorld(win.MousePosition())
+//       ...
+//   }
+//
+// Update the screen rectangle with SetScreen whenever the window is resized.
+type Camera struct {
+	screen Rect
+	pos    Vec
+	zoom   float64
+	angle  float64
+	bounds Rect
+
+	target    Vec
+	following bool
+	deadzone  Vec
+	speed     float64
+
+	trauma      float64
+	decay       float64
+	maxOffset   Vec
+	maxAngle    float64
+	frequency   float64
+	time        float64
+	shakeOffset Vec
+	shakeAngle  float64
+}
+
+// NewCamera creates a new Camera centered at the origin of the world, showing it in the center of
+// the screen rectangle (usually the bounds of the window).
+//
+// The Camera follows instantly without a deadzone and shakes by at most 16 pixels and 0.1
+// radians at full trauma, which decays in one second.
+func NewCamera(screen Rect) *Camera {
+	return &Camera{
+		screen:    screen.Norm(),
+		zoom:      1,
+		decay:     1,
+		maxOffset: V(16, 16),
+		maxAngle:  0.1,
+		frequency: 15,
+	}
+}
+
+// SetScreen sets the rectangle of the screen the Camera projects onto.
+func (c *Camera) SetScreen(r Rect) {
+	c.screen = r.Norm()
+	c.clamp()
+}
+
+// Screen returns the rectangle of the screen the Camera projects onto.
+func (c *Camera) Screen() Rect {
+	return c.screen
+}
+
+// SetPosition sets the point in the world shown in the center of the screen.
+func (c *Camera) SetPosition(pos Vec) {
+	c.pos = pos
+	c.clamp()
+}
+
+// Position returns the point in the world shown in the center of the screen (without shake).
+func (c *Camera) Position() Vec {
+	return c.pos
+}
+
+// SetZoom sets the zoom of the Camera. Zoom of 2 makes everything twice as large. The zoom must be
+// positive.
+func (c *Camera) SetZoom(zoom float64) {
+	c.zoom = zoom
+	c.clamp()
+}
+
+// Zoom returns the zoom of the Camera.
+func (c *Camera) Zoom() float64 {
+	return c.zoom
+}
+
+// ZoomAt multiplies the zoom of the Camera by a factor, while keeping the world point under the
+// provided screen point in place. This is useful for zooming towards the mouse cursor:
+//
+//   cam.ZoomAt(win.MousePosition(), math.Pow(1.2, win.MouseScroll().Y))
+func (c *Camera) ZoomAt(screen Vec, factor float64) {
+	before := c.ScreenToWorld(screen)
+	c.zoom *= factor
+	after := c.ScreenToWorld(screen)
+	c.pos = c.pos.Add(before.Sub(after))
+	c.clamp()
+}
+
+// SetAngle sets the rotation of the Camera in radians. Rotating the Camera counter-clockwise
+// makes the world appear rotated clockwise.
+func (c *Camera) SetAngle(angle float64) {
+	c.angle = angle
+	c.clamp()
+}
+
+// Angle returns the rotation of the Camera in radians.
+func (c *Camera) Angle() float64 {
+	return c.angle
+}
+
+// SetBounds sets the bounds of the world. The Camera never shows anything outside of them (except
+// when shaking). If the visible area is larger than the bounds, the Camera is centered on them.
+//
+// Setting a rectangle of zero area (such as pixel.ZR) disables clamping, which is the default.
+func (c *Camera) SetBounds(r Rect) {
+	c.bounds = r.Norm()
+	c.clamp()
+}
+
+// Bounds returns the bounds of the world the Camera is clamped to.
+func (c *Camera) Bounds() Rect {
+	return c.bounds
+}
+
+// SetFollow sets how the Camera follows it's target.
+//
+// The deadzone is the size of the rectangle (in world units) around the Camera's position, within
+// which the target can move without moving the Camera. Speed controls the smoothness: the Camera
+// covers about 63% of the remaining distance in 1/speed seconds. Speed of zero makes the Camera
+// follow instantly.
+func (c *Camera) SetFollow(deadzone Vec, speed float64) {
+	c.deadzone = deadzone
+	c.speed = speed
+}
+
+// Follow sets the point in the world the Camera follows on Update.
+func (c *Camera) Follow(target Vec) {
+	c.target = target
+	c.following = true
+}
+
+// Unfollow stops the Camera from following it's target.
+func (c *Camera) Unfollow() {
+	c.following = false
+}
+
+// SetShake sets the parameters of the screen shake. The maximal offset (in screen pixels) and
+// angle (in radians) are reached at full trauma. Frequency is the approximate number of direction
+// changes per second and decay is the amount of trauma removed per second.
+func (c *Camera) SetShake(maxOffset Vec, maxAngle, frequency, decay float64) {
+	c.maxOffset = maxOffset
+	c.maxAngle = maxAngle
+	c.frequency = frequency
+	c.decay = decay
+}
+
+// AddTrauma adds to the trauma of the Camera, which makes it shake. Trauma is clamped to [0, 1]
+// and the strength of the shake is proportional to it's square.
+func (c *Camera) AddTrauma(amount float64) {
+	c.trauma = Clamp(c.trauma+amount, 0, 1)
+}
+
+// Trauma returns the current trauma of the Camera.
+func (c *Camera) Trauma() float64 {
+	return c.trauma
+}
+
+// Update moves the Camera towards the followed target and updates the shake. The dt is the
+// elapsed time in seconds.
+func (c *Camera) Update(dt float64) {
+	if c.following {
+		desired := c.pos
+		half := c.deadzone.Scaled(0.5)
+		if c.target.X < c.pos.X-half.X {
+			desired.X = c.target.X + half.X
+		} else if c.target.X > c.pos.X+half.X {
+			desired.X = c.target.X - half.X
+		}
+		if c.target.Y < c.pos.Y-half.Y {
+			desired.Y = c.target.Y + half.Y
+		} else if c.target.Y > c.pos.Y+half.Y {
+			desired.Y = c.target.Y - half.Y
+		}
+		if c.speed > 0 {
+			desired = Lerp(c.pos, desired, 1-math.Exp(-c.speed*dt))
+		}
+		c.pos = desired
+		c.clamp()
+	}
+
+	c.time += dt
+	c.trauma = Clamp(c.trauma-c.decay*dt, 0, 1)
+	shake := c.trauma * c.trauma
+	t := c.time * c.frequency
+	c.shakeOffset = V(
+		c.maxOffset.X*shake*noise(1, t),
+		c.maxOffset.Y*shake*noise(2, t),
+	)
+	c.shakeAngle = c.maxAngle * shake * noise(3, t)
+}
+
+// Matrix returns the Matrix transforming world coordinates to screen coordinates. Pass it to the
+// SetMatrix method of a Target.
+func (c *Camera) Matrix() Matrix {
+	return IM.
+		Moved(c.pos.Scaled(-1)).
+		Rotated(ZV, -c.angle-c.shakeAngle).
+		Scaled(ZV, c.zoom).
+		Moved(c.screen.Center().Add(c.shakeOffset))
+}
+
+// WorldToScreen transforms a point in the world to the screen coordinates.
+func (c *Camera) WorldToScreen(world Vec) Vec {
+	return c.Matrix().Project(world)
+}
+
+// ScreenToWorld transforms a point on the screen (such as the mouse position) to the world
+// coordinates.
+func (c *Camera) ScreenToWorld(screen Vec) Vec {
+	return c.Matrix().Unproject(screen)
+}
+
+// VisibleRect returns the smallest rectangle in the world containing everything visible on the
+// screen. If the Camera is rotated, the rectangle contains some invisible areas as well.
+func (c *Camera) VisibleRect() Rect {
+	m := c.Matrix()
+	vs := c.screen.Vertices()
+	r := Rect{Min: m.Unproject(vs[0]), Max: m.Unproject(vs[0])}
+	for _, v := range vs[1:] {
+		w := m.Unproject(v)
+		r.Min = V(math.Min(r.Min.X, w.X), math.Min(r.Min.Y, w.Y))
+		r.Max = V(math.Max(r.Max.X, w.X), math.Max(r.Max.Y, w.Y))
+	}
+	return r
+}
+
+// clamp moves the Camera's position, so that the visible area stays within the bounds.
+func (c *Camera) clamp() {
+	if c.bounds.Area() == 0 || c.zoom <= 0 {
+		return
+	}
+	// half size of the visible area in the world, including rotation
+	sin, cos := math.Sincos(c.angle)
+	w, h := c.screen.W()/2/c.zoom, c.screen.H()/2/c.zoom
+	half := V(
+		math.Abs(cos)*w+math.Abs(sin)*h,
+		math.Abs(sin)*w+math.Abs(cos)*h,
+	)
+	c.pos.X = clampCenter(c.pos.X, c.bounds.Min.X+half.X, c.bounds.Max.X-half.X)
+	c.pos.Y = clampCenter(c.pos.Y, c.bounds.Min.Y+half.Y, c.bounds.Max.Y-half.Y)
+}
+
+// clampCenter clamps x to [min, max], or returns the center of the interval if min > max.
+func clampCenter(x, min, max float64) float64 {
+	if min > max {
+		return (min + max) / 2
+	}
+	return Clamp(x, min, max)
+}
+
+// noise returns smooth pseudo-random noise in [-1, 1] at time t. Different seeds produce
+// independent noise.
+func noise(seed uint32, t float64) float64 {
+	i := math.Floor(t)
+	f := t - i
+	f = f * f * (3 - 2*f)
+	a := hashNoise(seed, int64(i))
+	b := hashNoise(seed, int64(i)+1)
+	return a + (b-a)*f
+}
+
+// hashNoise returns a pseudo-random value in [-1, 1] for the integer i.
+func hashNoise(seed uint32, i int64) float64 {
+	h := uint32(i)*0x9e3779b1 ^ seed*0x85ebca6b
+	h ^= h >> 15
+	h *= 0x2c1b3c6d
+	h ^= h >> 12
+	h *= 0x297a2d39
+	h ^= h >> 15
+	return float64(h)/float64(math.MaxUint32)*2 - 1
+}
@@ -0,0 +1,120 @@
+package pixel_test
+
+import (
+	"math"
+	"testing"
+
+	"github.com/faiface/pixel"
+)
+
+func TestCamera_Conversion(t *testing.T) {
+	cam := pixel.NewCamera(pixel.R(0, 0, 800, 600))
+	cam.SetPosition(pixel.V(100, 50))
+	cam.SetZoom(2)
+
+	if got, want := cam.WorldToScreen(pixel.V(100, 50)), pixel.V(400, 300); !got.Eq(want) {
+		t.Errorf("WorldToScreen(position) = %v, want %v", got, want)
+	}
+	if got, want := cam.ScreenToWorld(pixel.V(0, 0)), pixel.V(-100, -100); !got.Eq(want) {
+		t.Errorf("ScreenToWorld(0, 0) = %v, want %v", got, want)
+	}
+	if got, want := cam.VisibleRect(), pixel.R(-100, -100, 300, 200); got != want {
+		t.Errorf("VisibleRect() = %v, want %v", got, want)
+	}
+
+	cam.SetAngle(math.Pi / 2)
+	if got, want := cam.WorldToScreen(pixel.V(110, 50)), pixel.V(400, 280); !got.Eq(want) {
+		t.Errorf("rotated WorldToScreen = %v, want %v", got, want)
+	}
+
+	// the world point under the cursor stays in place
+	cam.SetAngle(0)
+	mouse := pixel.V(600, 500)
+	before := cam.ScreenToWorld(mouse)
+	cam.ZoomAt(mouse, 1.5)
+	if got := cam.ScreenToWorld(mouse); !got.Eq(before) {
+		t.Errorf("ZoomAt moved the point under the cursor from %v to %v", before, got)
+	}
+	if cam.Zoom() != 3 {
+		t.Errorf("Zoom() = %v, want 3", cam.Zoom())
+	}
+}
+
+func TestCamera_Bounds(t *testing.T) {
+	cam := pixel.NewCamera(pixel.R(0, 0, 200, 100))
+	cam.SetBounds(pixel.R(0, 0, 1000, 1000))
+
+	cam.SetPosition(pixel.V(-500, 2000))
+	if got, want := cam.Position(), pixel.V(100, 950); got != want {
+		t.Errorf("clamped Position() = %v, want %v", got, want)
+	}
+
+	// the visible area is wider than the bounds
+	cam.SetZoom(0.1)
+	if got, want := cam.Position(), pixel.V(500, 500); got != want {
+		t.Errorf("centered Position() = %v, want %v", got, want)
+	}
+}
+
+func TestCamera_Follow(t *testing.T) {
+	cam := pixel.NewCamera(pixel.R(0, 0, 800, 600))
+	cam.SetFollow(pixel.V(100, 100), 0)
+
+	cam.Follow(pixel.V(40, -30))
+	cam.Update(1.0 / 60)
+	if got := cam.Position(); got != pixel.ZV {
+		t.Errorf("moved to %v within the deadzone", got)
+	}
+
+	cam.Follow(pixel.V(80, 0))
+	cam.Update(1.0 / 60)
+	if got, want := cam.Position(), pixel.V(30, 0); got != want {
+		t.Errorf("Position() = %v, want %v", got, want)
+	}
+
+	// smooth following approaches the target without overshooting
+	cam.SetFollow(pixel.ZV, 5)
+	cam.Follow(pixel.V(100, 0))
+	prev := cam.Position().X
+	for i := 0; i < 120; i++ {
+		cam.Update(1.0 / 60)
+		x := cam.Position().X
+		if x < prev || x > 100 {
+			t.Fatalf("step %d: X = %v, previous %v", i, x, prev)
+		}
+		prev = x
+	}
+	if prev < 99 {
+		t.Errorf("X = %v after 2 seconds, want close to 100", prev)
+	}
+}
+
+func TestCamera_Shake(t *testing.T) {
+	cam := pixel.NewCamera(pixel.R(0, 0, 800, 600))
+	cam.SetShake(pixel.V(10, 10), 0, 20, 1)
+	cam.AddTrauma(2)
+	if cam.Trauma() != 1 {
+		t.Errorf("Trauma() = %v, want 1", cam.Trauma())
+	}
+
+	shaken := false
+	for i := 0; i < 30; i++ {
+		cam.Update(1.0 / 60)
+		off := cam.WorldToScreen(pixel.ZV).Sub(pixel.V(400, 300))
+		if math.Abs(off.X) > 10 || math.Abs(off.Y) > 10 {
+			t.Fatalf("shake offset %v exceeds the maximum", off)
+		}
+		if off != pixel.ZV {
+			shaken = true
+		}
+	}
+	if !shaken {
+		t.Errorf("camera did not shake")
+	}
+
+	// trauma decays completely in one second
+	cam.Update(1)
+	if cam.Trauma() != 0 || cam.WorldToScreen(pixel.ZV) != pixel.V(400, 300) {
+		t.Errorf("camera still shakes with trauma %v", cam.Trauma())
+	}
+}
@@ -0,0 +1,397 @@
+package imdraw
+
+import (
+	"math"
+
+	"github.com/faiface/pixel"
+)
+
+// fringe remembers where the triangles of a shape start, so that a feathered fringe can be added
+// around them once the shape is drawn.
+type fringe struct {
+	off   int
+	width float64
+}
+
+// beginFringe must be called before drawing a shape from the Pushed points and endFringe after
+// it. The width of the fringe is the Feather of the first Pushed point.
+func (imd *IMDraw) beginFringe() fringe {
+	f := fringe{off: imd.tri.Len()}
+	if len(imd.points) > 0 {
+		f.width = imd.points[0].feather
+	}
+	return f
+}
+
+func (imd *IMDraw) endFringe(f fringe) {
+	if f.width > 0 {
+		imd.addFringe(f.off, f.width)
+	}
+}
+
+type fringeEdge struct {
+	a, b   pixel.Vec
+	count  int
+	normal pixel.Vec
+	// indices of the vertices of the edge and of the third vertex of the triangle
+	ia, ib, ic int
+}
+
+// outlinePiece is the part of an outer edge between the parameters t0 and t1, which lies on the
+// outline of the shape.
+type outlinePiece struct {
+	edge   int
+	t0, t1 float64
+	// indices of the vertices of the piece in the normals
+	na, nb int
+}
+
+// fringeBuffers are reused by addFringe for all shapes, because IMDraws are usually redrawn each
+// frame.
+type fringeBuffers struct {
+	edges    map[[4]uint64]int
+	order    []fringeEdge
+	vertices map[[2]int64]int
+	normals  []pixel.Vec
+	outline  []outlinePiece
+	grid     triangleGrid
+}
+
+// addFringe adds a fringe of the given width around the outline of the triangles starting at the
+// offset. The fringe fades from the color of the outline to transparent.
+//
+// The outline consists of the parts of the edges of the triangles, which are not shared by two
+// triangles and not covered by other triangles, so there's no fringe along the seams of
+// overlapping parts of a shape, such as the segments and joints of a line. The fringe is extruded
+// along the averaged normals of the outline at each vertex, so the fringes of adjacent edges
+// connect without gaps. The positions of the triangles are already projected by the matrix, so
+// the width is in the coordinates of the Target.
+func (imd *IMDraw) addFringe(off int, width float64) {
+	tri := (*imd.tri)[off:]
+	buf := &imd.fringe
+	if buf.edges == nil {
+		buf.edges = make(map[[4]uint64]int)
+		buf.vertices = make(map[[2]int64]int)
+	}
+	for k := range buf.edges {
+		delete(buf.edges, k)
+	}
+	for k := range buf.vertices {
+		delete(buf.vertices, k)
+	}
+	edges, order := buf.edges, buf.order[:0]
+	grid := &buf.grid
+	grid.reset(tri)
+
+	for i := 0; i+2 < len(tri); i += 3 {
+		if !grid.valid[i/3] {
+			continue
+		}
+		for _, e := range [...][3]int{{i, i + 1, i + 2}, {i + 1, i + 2, i}, {i + 2, i, i + 1}} {
+			p, q := tri[e[0]].Position, tri[e[1]].Position
+			if q.X < p.X || (q.X == p.X && q.Y < p.Y) {
+				p, q = q, p
+			}
+			k := [4]uint64{math.Float64bits(p.X), math.Float64bits(p.Y), math.Float64bits(q.X), math.Float64bits(q.Y)}
+			if j, ok := edges[k]; ok {
+				order[j].count++
+				continue
+			}
+			edges[k] = len(order)
+			order = append(order, fringeEdge{a: tri[e[0]].Position, b: tri[e[1]].Position, count: 1, ia: e[0], ib: e[1], ic: e[2]})
+		}
+	}
+	buf.order = order
+
+	// outward normals of the outline, summed up at each vertex
+	normals, vertices := buf.normals[:0], buf.vertices
+	vertex := func(at pixel.Vec, normal pixel.Vec) int {
+		k := outlineKey(at)
+		i, ok := vertices[k]
+		if !ok {
+			i = len(normals)
+			vertices[k] = i
+			normals = append(normals, pixel.ZV)
+		}
+		normals[i] = normals[i].Add(normal)
+		return i
+	}
+	outline := buf.outline[:0]
+	for i := range order {
+		e := &order[i]
+		if e.count != 1 {
+			continue
+		}
+		e.normal = e.a.To(e.b).Normal().Unit()
+		if e.normal.Dot(e.a.To(tri[e.ic].Position)) > 0 {
+			e.normal = e.normal.Scaled(-1)
+		}
+		for _, span := range grid.uncoveredSpans(e) {
+			outline = append(outline, outlinePiece{
+				edge: i,
+				t0:   span[0],
+				t1:   span[1],
+				na:   vertex(pixel.Lerp(e.a, e.b, span[0]), e.normal),
+				nb:   vertex(pixel.Lerp(e.a, e.b, span[1]), e.normal),
+			})
+		}
+	}
+	buf.normals, buf.outline = normals, outline
+
+	extrude := func(at pixel.Vec, vertex int, edgeNormal pixel.Vec) pixel.Vec {
+		n := normals[vertex]
+		if n.Len() < 1e-9 {
+			n = edgeNormal
+		}
+		n = n.Unit()
+		// keep the width of the fringe constant along the edge, but avoid long spikes at sharp
+		// corners
+		scale := 1 / math.Max(n.Dot(edgeNormal), 0.25)
+		return at.Add(n.Scaled(width * scale))
+	}
+
+	start := imd.tri.Len()
+	imd.tri.SetLen(start + 6*len(outline))
+	td := *imd.tri
+
+	for i, piece := range outline {
+		e := &order[piece.edge]
+		// the vertices on the outline and the extruded transparent ones
+		va, vb, fa, fb := start+6*i, start+6*i+1, start+6*i+5, start+6*i+2
+		lerpVertex(td, va, off+e.ia, off+e.ib, piece.t0)
+		lerpVertex(td, vb, off+e.ia, off+e.ib, piece.t1)
+		td[fa], td[fb] = td[va], td[vb]
+		td[fa].Position, td[fa].Color = extrude(td[va].Position, piece.na, e.normal), pixel.RGBA{}
+		td[fb].Position, td[fb].Color = extrude(td[vb].Position, piece.nb, e.normal), pixel.RGBA{}
+		td[start+6*i+3], td[start+6*i+4] = td[va], td[fb]
+	}
+
+	// the grid must not keep the triangles alive
+	grid.tri = nil
+	imd.batch.Dirty()
+}
+
+// outlineEpsilon is the distance outside of an edge, at which other triangles are tested for
+// covering it. It's small compared to a pixel, but larger than rounding errors of positions of
+// the triangles.
+const outlineEpsilon = 1e-4
+
+// triangleGrid is a uniform grid of cells, each listing the triangles with bounds overlapping it,
+// for finding the triangles near an edge quickly.
+type triangleGrid struct {
+	tri    pixel.TrianglesData
+	bounds []pixel.Rect
+	valid  []bool
+
+	min    pixel.Vec
+	cell   float64
+	nx, ny int
+	// the triangles of the cell i are tris[start[i]:start[i+1]]
+	start []int
+	tris  []int
+
+	// seen marks the triangles already tested for the current edge
+	seen  []int
+	stamp int
+
+	covered, spans [][2]float64
+}
+
+// reset sorts the non-degenerate triangles into cells about as large as the average triangle.
+func (g *triangleGrid) reset(tri pixel.TrianglesData) {
+	n := len(tri) / 3
+	g.tri = tri
+	g.bounds = append(g.bounds[:0], make([]pixel.Rect, n)...)
+	g.valid = append(g.valid[:0], make([]bool, n)...)
+	g.seen = append(g.seen[:0], make([]int, n)...)
+	g.stamp = 0
+	g.nx, g.ny = 0, 0
+
+	var all pixel.Rect
+	size, count := 0.0, 0
+	for t := range g.bounds {
+		a, b, c := tri[3*t].Position, tri[3*t+1].Position, tri[3*t+2].Position
+		if a.To(b).Cross(a.To(c)) == 0 {
+			continue
+		}
+		r := pixel.R(a.X, a.Y, a.X, a.Y).Union(pixel.R(b.X, b.Y, c.X, c.Y).Norm())
+		if count == 0 {
+			all = r
+		}
+		g.bounds[t], g.valid[t], all = r, true, all.Union(r)
+		size += math.Max(r.W(), r.H())
+		count++
+	}
+	if count == 0 {
+		return
+	}
+
+	// cells as large as the average triangle, but not many more cells than triangles
+	g.min = all.Min
+	g.cell = math.Max(size/float64(count), outlineEpsilon)
+	g.cell = math.Max(g.cell, math.Sqrt(all.Area()/float64(4*count)))
+	g.nx = int(all.W()/g.cell) + 1
+	g.ny = int(all.H()/g.cell) + 1
+
+	cells := g.nx * g.ny
+	g.start = append(g.start[:0], make([]int, cells+1)...)
+	for t, r := range g.bounds {
+		if !g.valid[t] {
+			continue
+		}
+		x0, y0, x1, y1 := g.cells(r)
+		for y := y0; y <= y1; y++ {
+			for x := x0; x <= x1; x++ {
+				g.start[y*g.nx+x+1]++
+			}
+		}
+	}
+	for i := 1; i < len(g.start); i++ {
+		g.start[i] += g.start[i-1]
+	}
+	g.tris = append(g.tris[:0], make([]int, g.start[cells])...)
+	for t, r := range g.bounds {
+		if !g.valid[t] {
+			continue
+		}
+		x0, y0, x1, y1 := g.cells(r)
+		for y := y0; y <= y1; y++ {
+			for x := x0; x <= x1; x++ {
+				// start is shifted by one cell while filling and restored afterwards
+				i := y*g.nx + x
+				g.tris[g.start[i]] = t
+				g.start[i]++
+			}
+		}
+	}
+	copy(g.start[1:], g.start[:cells])
+	g.start[0] = 0
+}
+
+// cells returns the range of cells overlapping the rectangle, clamped to the grid.
+func (g *triangleGrid) cells(r pixel.Rect) (x0, y0, x1, y1 int) {
+	clamp := func(v float64, n int) int {
+		switch i := int(v / g.cell); {
+		case v < 0:
+			return 0
+		case i >= n:
+			return n - 1
+		default:
+			return i
+		}
+	}
+	return clamp(r.Min.X-g.min.X, g.nx), clamp(r.Min.Y-g.min.Y, g.ny),
+		clamp(r.Max.X-g.min.X, g.nx), clamp(r.Max.Y-g.min.Y, g.ny)
+}
+
+// uncoveredSpans returns the spans of the edge, which are not covered by other triangles, as pairs
+// of parameters from 0 (the start of the edge) to 1 (the end of the edge). The returned spans are
+// only valid until the next call.
+func (g *triangleGrid) uncoveredSpans(e *fringeEdge) [][2]float64 {
+	own := e.ia / 3
+	// the edge moved slightly outside, which is covered where it lies inside of another triangle
+	a, b := e.a.Add(e.normal.Scaled(outlineEpsilon)), e.b.Add(e.normal.Scaled(outlineEpsilon))
+	eb := pixel.R(a.X, a.Y, b.X, b.Y).Norm()
+
+	g.stamp++
+	covered := g.covered[:0]
+	x0, y0, x1, y1 := g.cells(eb)
+	for y := y0; y <= y1 && g.nx > 0; y++ {
+		for x := x0; x <= x1; x++ {
+			i := y*g.nx + x
+			for _, t := range g.tris[g.start[i]:g.start[i+1]] {
+				if t == own || g.seen[t] == g.stamp {
+					continue
+				}
+				g.seen[t] = g.stamp
+				if !overlaps(eb, g.bounds[t]) {
+					continue
+				}
+				if lo, hi := g.clip(t, a, b); lo < hi {
+					covered = append(covered, [2]float64{lo, hi})
+				}
+			}
+		}
+	}
+	g.covered = covered
+
+	// there are only a few covered spans, so they are sorted by insertion
+	for i := 1; i < len(covered); i++ {
+		for j := i; j > 0 && covered[j][0] < covered[j-1][0]; j-- {
+			covered[j], covered[j-1] = covered[j-1], covered[j]
+		}
+	}
+	// spans shorter than this are dropped
+	minSpan := outlineEpsilon / math.Max(e.a.To(e.b).Len(), outlineEpsilon)
+
+	spans := g.spans[:0]
+	t := 0.0
+	for _, c := range covered {
+		if c[0]-t > minSpan {
+			spans = append(spans, [2]float64{t, c[0]})
+		}
+		t = math.Max(t, c[1])
+	}
+	if 1-t > minSpan {
+		spans = append(spans, [2]float64{t, 1})
+	}
+	g.spans = spans
+	return spans
+}
+
+// clip returns the range of parameters of the segment from a to b, which lies inside of the
+// triangle t.
+func (g *triangleGrid) clip(t int, a, b pixel.Vec) (lo, hi float64) {
+	p := [...]pixel.Vec{g.tri[3*t].Position, g.tri[3*t+1].Position, g.tri[3*t+2].Position}
+	orientation := 1.0
+	if p[0].To(p[1]).Cross(p[0].To(p[2])) < 0 {
+		orientation = -1
+	}
+
+	// clip the segment by the half-planes of the edges of the triangle
+	lo, hi = 0, 1
+	for k := 0; k < 3 && lo < hi; k++ {
+		from, dir := p[k], p[k].To(p[(k+1)%3])
+		// the point a + t*(b-a) is inside, when f0 + t*f1 > 0
+		f0 := dir.Cross(from.To(a)) * orientation
+		f1 := dir.Cross(a.To(b)) * orientation
+		switch {
+		case f1 > 0:
+			lo = math.Max(lo, -f0/f1)
+		case f1 < 0:
+			hi = math.Min(hi, -f0/f1)
+		case f0 <= 0:
+			hi = lo
+		}
+	}
+	return lo, hi
+}
+
+func overlaps(r, s pixel.Rect) bool {
+	return r.Min.X <= s.Max.X && s.Min.X <= r.Max.X && r.Min.Y <= s.Max.Y && s.Min.Y <= r.Max.Y
+}
+
+// lerpVertex sets the vertex dst of the TrianglesData to the vertex at the parameter t between
+// the vertices a and b.
+func lerpVertex(td pixel.TrianglesData, dst, a, b int, t float64) {
+	switch t {
+	case 0:
+		td[dst] = td[a]
+		return
+	case 1:
+		td[dst] = td[b]
+		return
+	}
+	td[dst] = td[a]
+	td[dst].Position = pixel.Lerp(td[a].Position, td[b].Position, t)
+	td[dst].Color = td[a].Color.Scaled(1 - t).Add(td[b].Color.Scaled(t))
+	td[dst].Picture = pixel.Lerp(td[a].Picture, td[b].Picture, t)
+	td[dst].Intensity = td[a].Intensity*(1-t) + td[b].Intensity*t
+}
+
+// outlineKey rounds the position of a vertex of the outline, so that the pieces of the outline
+// split at the same point of different edges meet in the same vertex.
+func outlineKey(v pixel.Vec) [2]int64 {
+	const grid = 1 << 12
+	return [2]int64{int64(math.Round(v.X * grid)), int64(math.Round(v.Y * grid))}
+}
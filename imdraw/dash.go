@@ -0,0 +1,154 @@
+package imdraw
+
+import (
+	"math"
+
+	"github.com/faiface/pixel"
+)
+
+// dashPattern returns a normalized dash pattern of even length. If the pattern is empty, has a
+// negative entry or it's total length is zero, nil is returned, which means a solid line.
+func dashPattern(dash []float64) []float64 {
+	total := 0.0
+	for _, d := range dash {
+		if d < 0 {
+			return nil
+		}
+		total += d
+	}
+	if total <= 0 {
+		return nil
+	}
+	if len(dash)%2 == 1 {
+		// like in SVG, an odd pattern is repeated to make it even
+		return append(append([]float64{}, dash...), dash...)
+	}
+	return dash
+}
+
+// lerpPoint returns a point between a and b, interpolating the position, color and picture. Other
+// properties are taken from a.
+func lerpPoint(a, b point, t float64) point {
+	p := a
+	p.pos = pixel.Lerp(a.pos, b.pos, t)
+	p.col = a.col.Scaled(1 - t).Add(b.col.Scaled(t))
+	p.pic = pixel.Lerp(a.pic, b.pic, t)
+	p.in = a.in*(1-t) + b.in*t
+	return p
+}
+
+// dash is a single dash of a dashed line. dir is the direction of the line at the end of the dash,
+// which is used to orient the end shapes of zero-length dashes.
+type dash struct {
+	points []point
+	dir    pixel.Vec
+}
+
+// dashedPolyline splits the polyline into dashes according to the pattern and draws each of them
+// as a separate line.
+func (imd *IMDraw) dashedPolyline(points []point, pattern []float64, thickness float64, closed bool) {
+	total := 0.0
+	for _, d := range pattern {
+		total += d
+	}
+
+	// find the position within the pattern at the start of the line
+	offset := math.Mod(points[0].dashOffset, total)
+	if offset < 0 {
+		offset += total
+	}
+	idx := 0
+	for offset >= pattern[idx] && offset > 0 {
+		offset -= pattern[idx]
+		idx = (idx + 1) % len(pattern)
+	}
+	remaining := pattern[idx] - offset
+	on := idx%2 == 0
+	startsOn := on
+
+	var (
+		dashes []dash
+		cur    []point
+	)
+	if on {
+		cur = append(cur, points[0])
+	}
+
+	segments := len(points) - 1
+	if closed {
+		segments++
+	}
+	for s := 0; s < segments; s++ {
+		a, b := points[s], points[(s+1)%len(points)]
+		length := a.pos.To(b.pos).Len()
+		dir := a.pos.To(b.pos).Unit()
+
+		t := 0.0
+		for length-t > remaining {
+			t += remaining
+			p := lerpPoint(a, b, t/length)
+			if on {
+				dashes = append(dashes, dash{points: append(cur, p), dir: dir})
+				cur = nil
+			} else {
+				cur = []point{p}
+			}
+			on = !on
+			idx = (idx + 1) % len(pattern)
+			remaining = pattern[idx]
+		}
+		remaining -= length - t
+		if on {
+			cur = append(cur, b)
+		}
+		if s == segments-1 && on {
+			dashes = append(dashes, dash{points: cur, dir: dir})
+		}
+	}
+
+	// a closed line may start and end within the same dash
+	if closed && startsOn && on && len(dashes) > 1 {
+		last := dashes[len(dashes)-1]
+		dashes[0].points = append(last.points, dashes[0].points[1:]...)
+		dashes = dashes[:len(dashes)-1]
+	}
+
+	for _, d := range dashes {
+		n := 0
+		for _, p := range d.points {
+			if n > 0 && imd.points[len(imd.points)-1].pos == p.pos {
+				continue
+			}
+			p.dash = nil
+			imd.pushPt(p.pos, p)
+			n++
+		}
+		if n == 1 {
+			// zero-length dash, still needs a direction for the end shapes
+			p := imd.points[len(imd.points)-1]
+			imd.pushPt(p.pos.Add(d.dir.Scaled(1e-6)), p)
+		}
+		imd.polyline(thickness, false)
+	}
+}
+
+// dashedEllipseArc draws a dashed ellipse arc outline around the point, by approximating it with a
+// dashed polyline. Unlike solid arcs, the end shapes of the dashes are drawn even for full
+// ellipses.
+func (imd *IMDraw) dashedEllipseArc(pt point, radius pixel.Vec, low, high, thickness float64, doEndShape bool) {
+	num := imd.segments(pt, radius, high-low)
+	delta := (high - low) / num
+
+	// a full ellipse is a closed line, so the dashes continue across the start
+	closed := !doEndShape
+	end := int(num)
+	if closed {
+		end--
+	}
+
+	for i := 0; i <= end; i++ {
+		sin, cos := math.Sincos(low + float64(i)*delta)
+		imd.pushPt(pt.pos.Add(pixel.V(radius.X*cos, radius.Y*sin)), pt)
+	}
+	imd.polyline(thickness, closed)
+}
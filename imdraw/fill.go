@@ -0,0 +1,323 @@
+package imdraw
+
+import (
+	"image/color"
+	"math"
+
+	"github.com/faiface/pixel"
+)
+
+// Fill determines the colors and the Picture coordinates of shapes drawn by IMDraw. It's evaluated
+// for every vertex of a shape in the coordinates of the Pushed points, before the matrix set by
+// SetMatrix is applied.
+//
+// Fill is implemented by LinearGradient, RadialGradient and PictureFill.
+type Fill interface {
+	// params returns the parameters of the Fill at the position. The parameters are interpolated
+	// linearly across triangles.
+	params(pos pixel.Vec) [2]float64
+
+	// levels returns the values between min and max of the i-th parameter, at which triangles
+	// need to be split, so that the Fill is correct inside of them.
+	levels(i int, min, max float64) []float64
+
+	// apply sets the properties of a vertex from it's parameters. The cell is the integer part of
+	// the parameters of the center of the triangle the vertex belongs to.
+	apply(v *fillVertex, cell [2]float64)
+}
+
+// GradientStop is a color at an offset along a gradient, where 0 is the start and 1 is the end of
+// the gradient.
+type GradientStop struct {
+	Offset float64
+	Color  color.Color
+}
+
+// LinearGradient is a Fill changing color along the line from the Start to the End. The colors
+// are given by the Stops, which must be sorted by their offsets. Before the first and after the
+// last stop, the color of the stop is used.
+//
+// The colors of the gradient are multiplied by the Color of the points, so the default white
+// Color leaves them unchanged.
+type LinearGradient struct {
+	Start, End pixel.Vec
+	Stops      []GradientStop
+}
+
+func (lg LinearGradient) params(pos pixel.Vec) [2]float64 {
+	d := lg.Start.To(lg.End)
+	if d == pixel.ZV {
+		return [2]float64{}
+	}
+	return [2]float64{lg.Start.To(pos).Dot(d) / d.Dot(d), 0}
+}
+
+func (lg LinearGradient) levels(i int, min, max float64) []float64 {
+	if i != 0 {
+		return nil
+	}
+	return stopLevels(lg.Stops, min, max)
+}
+
+func (lg LinearGradient) apply(v *fillVertex, cell [2]float64) {
+	v.col = v.col.Mul(gradientColor(lg.Stops, v.params[0]))
+}
+
+// RadialGradient is a Fill changing color from the Center outwards, reaching the end of the
+// gradient at the Radius. Otherwise, it's just like LinearGradient.
+//
+// Unlike LinearGradient, RadialGradient isn't linear, so the colors between the vertices of a shape
+// are only approximated. Shapes with more vertices, such as circles around the Center, are
+// more precise.
+type RadialGradient struct {
+	Center pixel.Vec
+	Radius float64
+	Stops  []GradientStop
+}
+
+func (rg RadialGradient) params(pos pixel.Vec) [2]float64 {
+	if rg.Radius == 0 {
+		return [2]float64{}
+	}
+	return [2]float64{rg.Center.To(pos).Len() / rg.Radius, 0}
+}
+
+func (rg RadialGradient) levels(i int, min, max float64) []float64 {
+	if i != 0 {
+		return nil
+	}
+	return stopLevels(rg.Stops, min, max)
+}
+
+func (rg RadialGradient) apply(v *fillVertex, cell [2]float64) {
+	v.col = v.col.Mul(gradientColor(rg.Stops, v.params[0]))
+}
+
+// stopLevels returns the offsets of the stops strictly between min and max.
+func stopLevels(stops []GradientStop, min, max float64) []float64 {
+	var levels []float64
+	for _, s := range stops {
+		if min < s.Offset && s.Offset < max {
+			levels = append(levels, s.Offset)
+		}
+	}
+	return levels
+}
+
+// gradientColor returns the color of a gradient at the offset. Gradients without stops are
+// transparent.
+func gradientColor(stops []GradientStop, t float64) pixel.RGBA {
+	if len(stops) == 0 {
+		return pixel.RGBA{}
+	}
+	if t <= stops[0].Offset {
+		return pixel.ToRGBA(stops[0].Color)
+	}
+	for i := 1; i < len(stops); i++ {
+		a, b := stops[i-1], stops[i]
+		if t > b.Offset {
+			continue
+		}
+		if b.Offset == a.Offset {
+			return pixel.ToRGBA(b.Color)
+		}
+		s := (t - a.Offset) / (b.Offset - a.Offset)
+		return pixel.ToRGBA(a.Color).Scaled(1 - s).Add(pixel.ToRGBA(b.Color).Scaled(s))
+	}
+	return pixel.ToRGBA(stops[len(stops)-1].Color)
+}
+
+// PictureFill is a Fill mapping the Picture of the IMDraw onto shapes. The UV Matrix maps the
+// positions of the vertices to the Picture coordinates.
+//
+// If the Tile is not empty, the Picture coordinates are wrapped into it, so that the part of the
+// Picture in the Tile is repeated over and over, for example:
+//
+//   imd.Fill = imdraw.TilePicture(pixel.ZV, pic.Bounds())
+//
+// The vertices are given the intensity of 1, so the Picture is multiplied by the Color of the
+// points, ignoring their Intensity.
+type PictureFill struct {
+	UV   pixel.Matrix
+	Tile pixel.Rect
+}
+
+// StretchPicture returns a PictureFill that stretches the frame of the Picture over the bounds.
+func StretchPicture(bounds, frame pixel.Rect) PictureFill {
+	return PictureFill{UV: pixel.IM.
+		Moved(bounds.Min.Scaled(-1)).
+		ScaledXY(pixel.ZV, pixel.V(frame.W()/bounds.W(), frame.H()/bounds.H())).
+		Moved(frame.Min),
+	}
+}
+
+// FitPicture returns a PictureFill that scales the frame of the Picture to fit inside of the
+// bounds and centers it, keeping it's aspect ratio. The edges of the frame extend over the rest of
+// the bounds.
+func FitPicture(bounds, frame pixel.Rect) PictureFill {
+	scale := math.Max(frame.W()/bounds.W(), frame.H()/bounds.H())
+	return PictureFill{UV: pixel.IM.
+		Moved(bounds.Center().Scaled(-1)).
+		Scaled(pixel.ZV, scale).
+		Moved(frame.Center()),
+	}
+}
+
+// TilePicture returns a PictureFill that repeats the frame of the Picture in it's original size,
+// with a corner of one of the tiles at the origin.
+func TilePicture(origin pixel.Vec, frame pixel.Rect) PictureFill {
+	return PictureFill{
+		UV:   pixel.IM.Moved(frame.Min.Sub(origin)),
+		Tile: frame,
+	}
+}
+
+func (pf PictureFill) params(pos pixel.Vec) [2]float64 {
+	pic := pf.UV.Project(pos)
+	if pf.Tile.Area() == 0 {
+		return [2]float64{pic.X, pic.Y}
+	}
+	return [2]float64{
+		(pic.X - pf.Tile.Min.X) / pf.Tile.W(),
+		(pic.Y - pf.Tile.Min.Y) / pf.Tile.H(),
+	}
+}
+
+func (pf PictureFill) levels(i int, min, max float64) []float64 {
+	if pf.Tile.Area() == 0 {
+		return nil
+	}
+	var levels []float64
+	for x := math.Floor(min) + 1; x < max; x++ {
+		levels = append(levels, x)
+	}
+	return levels
+}
+
+func (pf PictureFill) apply(v *fillVertex, cell [2]float64) {
+	v.in = 1
+	if pf.Tile.Area() == 0 {
+		v.pic = pixel.V(v.params[0], v.params[1])
+		return
+	}
+	v.pic = pixel.V(
+		pf.Tile.Min.X+(v.params[0]-cell[0])*pf.Tile.W(),
+		pf.Tile.Min.Y+(v.params[1]-cell[1])*pf.Tile.H(),
+	)
+}
+
+type fillVertex struct {
+	pos    pixel.Vec
+	col    pixel.RGBA
+	pic    pixel.Vec
+	in     float64
+	params [2]float64
+}
+
+// splitEdge returns the point on the edge between two vertices, where the i-th parameter equals
+// the level. The vertices are ordered by their positions first, so that the result doesn't depend
+// on the direction of the edge. This way, triangles sharing an edge split it at exactly the same
+// point.
+func splitEdge(a, b fillVertex, i int, level float64) fillVertex {
+	if b.pos.X < a.pos.X || (b.pos.X == a.pos.X && b.pos.Y < a.pos.Y) {
+		a, b = b, a
+	}
+	s := (level - a.params[i]) / (b.params[i] - a.params[i])
+	v := fillVertex{
+		pos: pixel.Lerp(a.pos, b.pos, s),
+		col: a.col.Scaled(1 - s).Add(b.col.Scaled(s)),
+		pic: pixel.Lerp(a.pic, b.pic, s),
+		in:  a.in*(1-s) + b.in*s,
+	}
+	for j := range v.params {
+		v.params[j] = a.params[j]*(1-s) + b.params[j]*s
+	}
+	v.params[i] = level
+	return v
+}
+
+// splitPolygon splits a convex polygon into the parts with the i-th parameter below and above the
+// level.
+func splitPolygon(poly []fillVertex, i int, level float64) (below, above []fillVertex) {
+	for j := range poly {
+		p, q := poly[j], poly[(j+1)%len(poly)]
+		dp, dq := p.params[i]-level, q.params[i]-level
+		if dp <= 0 {
+			below = append(below, p)
+		}
+		if dp >= 0 {
+			above = append(above, p)
+		}
+		if (dp < 0 && dq > 0) || (dp > 0 && dq < 0) {
+			v := splitEdge(p, q, i, level)
+			below = append(below, v)
+			above = append(above, v)
+		}
+	}
+	return below, above
+}
+
+// applyFill evaluates the Fill for the triangles starting at the offset. The triangles are split
+// where needed, so their number may change.
+func (imd *IMDraw) applyFill(off int, fill Fill) {
+	if fill == nil {
+		return
+	}
+
+	var out []fillVertex
+	tri := (*imd.tri)[off:]
+	for i := 0; i+2 < len(tri); i += 3 {
+		poly := make([]fillVertex, 3)
+		for j := range poly {
+			t := tri[i+j]
+			poly[j] = fillVertex{pos: t.Position, col: t.Color, pic: t.Picture, in: t.Intensity}
+			poly[j].params = fill.params(t.Position)
+		}
+
+		pieces := [][]fillVertex{poly}
+		for p := range poly[0].params {
+			min, max := math.Inf(+1), math.Inf(-1)
+			for _, v := range poly {
+				min, max = math.Min(min, v.params[p]), math.Max(max, v.params[p])
+			}
+			for _, level := range fill.levels(p, min, max) {
+				var split [][]fillVertex
+				for _, piece := range pieces {
+					below, above := splitPolygon(piece, p, level)
+					if len(below) >= 3 {
+						split = append(split, below)
+					}
+					if len(above) >= 3 {
+						split = append(split, above)
+					}
+				}
+				pieces = split
+			}
+		}
+
+		for _, piece := range pieces {
+			var cell [2]float64
+			for _, v := range piece {
+				cell[0] += v.params[0] / float64(len(piece))
+				cell[1] += v.params[1] / float64(len(piece))
+			}
+			cell[0], cell[1] = math.Floor(cell[0]), math.Floor(cell[1])
+
+			for j := range piece {
+				fill.apply(&piece[j], cell)
+			}
+			for j := 1; j+1 < len(piece); j++ {
+				out = append(out, piece[0], piece[j], piece[j+1])
+			}
+		}
+	}
+
+	imd.tri.SetLen(off + len(out))
+	for i, v := range out {
+		t := &(*imd.tri)[off+i]
+		t.Position = v.pos
+		t.Color = v.col
+		t.Picture = v.pic
+		t.Intensity = v.in
+	}
+}
@@ -30,45 +30,105 @@ import (
 //   imd.Circle(400, 0)
 //
 // Here is the list of all available point properties (need to be set before Pushing a point):
-//   - Color     - applies to all
-//   - Picture   - coordinates, only applies to filled polygons
-//   - Intensity - picture intensity, only applies to filled polygons
-//   - Precision - curve drawing precision, only applies to circles and ellipses
-//   - EndShape  - shape of the end of a line, only applies to lines and outlines
+//   - Color      - applies to all
+//   - Picture    - coordinates, only applies to filled polygons
+//   - Intensity  - picture intensity, only applies to filled polygons
+//   - Precision  - curve drawing precision, only applies to circles, ellipses and round shapes
+//   - EndShape   - shape of the end of a line, only applies to lines and outlines
+//   - LineJoin   - shape of the joints of a line, only applies to lines and outlines
+//   - MiterLimit - maximal length of miter joints, only applies to lines and outlines
+//   - Dash       - dash pattern, only applies to lines and outlines
+//   - DashOffset - offset of the dash pattern, only applies to lines and outlines
+//   - Tolerance  - maximal error of flattened curves, only applies to paths and AdaptivePrecision
+//   - FillRule   - rule determining the inside of a path, only applies to filled paths
+//   - Feather    - width of anti-aliased edges, applies to all
+//   - Fill       - gradient or Picture mapping, applies to all
 //
-// And here's the list of all shapes that can be drawn (all, except for line, can be filled or
-// outlined):
+// And here's the list of all shapes that can be drawn (all, except for line and arrow, can be
+// filled or outlined):
 //   - Line
+//   - Arrow
+//   - Rectangle
+//   - Rounded rectangle
 //   - Polygon
+//   - Regular polygon
+//   - Star
 //   - Circle
 //   - Circle arc
+//   - Pie
+//   - Ring
+//   - Capsule
 //   - Ellipse
 //   - Ellipse arc
+//   - Path (see Path)
 type IMDraw struct {
 	Color     color.Color
 	Picture   pixel.Vec
 	Intensity float64
-	Precision int
 	EndShape  EndShape
 
+	// Precision is the number of segments full circles and ellipses are drawn with, arcs get a
+	// proportional part of them. With AdaptivePrecision, the number of segments is chosen from the
+	// size of each curve on the Target instead, see Tolerance.
+	Precision int
+
+	// LineJoin is the shape of the joints between the segments of lines and outlines. MiterLimit
+	// is the maximal ratio of the length of a miter joint to the thickness of the line, longer
+	// miter joints are drawn as bevel joints.
+	LineJoin   LineJoin
+	MiterLimit float64
+
+	// Dash is a dash pattern of alternating lengths of dashes and gaps, starting with a dash.
+	// DashOffset shifts the start of the pattern along the line. A nil or empty Dash means solid
+	// lines. See Line for details.
+	Dash       []float64
+	DashOffset float64
+
+	// Tolerance is the maximal distance between curves and the lines they're approximated with,
+	// measured in the coordinates of the Target, so the scale of the matrix set by SetMatrix is
+	// taken into account. It applies to Paths and to curves drawn with AdaptivePrecision.
+	// FillRule determines which areas of a filled Path are inside of it.
+	Tolerance float64
+	FillRule  FillRule
+
+	// Feather is the width of a fringe added along the outer edges of shapes, which fades to
+	// transparent and makes the edges look smooth without multisampling. It's measured in the
+	// coordinates of the Target the IMDraw is drawn onto and 1 is a good value for drawing
+	// directly onto a window. Zero disables the fringe.
+	Feather float64
+
+	// Fill, if not nil, computes the colors and the Picture coordinates of all vertices of shapes
+	// from their positions, see Fill.
+	Fill Fill
+
 	points []point
 	pool   [][]point
 	matrix pixel.Matrix
 	mask   pixel.RGBA
 
-	tri   *pixel.TrianglesData
-	batch *pixel.Batch
+	tri    *pixel.TrianglesData
+	batch  *pixel.Batch
+	pic    pixel.Picture
+	fringe fringeBuffers
 }
 
 var _ pixel.BasicTarget = (*IMDraw)(nil)
 
 type point struct {
-	pos       pixel.Vec
-	col       pixel.RGBA
-	pic       pixel.Vec
-	in        float64
-	precision int
-	endshape  EndShape
+	pos        pixel.Vec
+	col        pixel.RGBA
+	pic        pixel.Vec
+	in         float64
+	precision  int
+	endshape   EndShape
+	linejoin   LineJoin
+	miterLimit float64
+	dash       []float64
+	dashOffset float64
+	tolerance  float64
+	fillRule   FillRule
+	feather    float64
+	fill       Fill
 }
 
 // EndShape specifies the shape of an end of a line or a curve.
@@ -83,8 +143,36 @@ const (
 
 	// RoundEndShape is a circular end shape.
 	RoundEndShape
+
+	// SquareEndShape is a square end shape, which extends the line by half of it's thickness.
+	SquareEndShape
 )
 
+// LineJoin specifies the shape of a joint between two segments of a line.
+type LineJoin int
+
+const (
+	// EndShapeLineJoin draws the joint according to the EndShape of the point: no joint for
+	// NoEndShape, a round joint for RoundEndShape and a bevel joint otherwise. Outlines of rounded
+	// rectangles, regular polygons, stars, pies, capsules and paths use MiterLineJoin instead.
+	EndShapeLineJoin LineJoin = iota
+
+	// MiterLineJoin extends the outer edges of the segments until they meet. If the joint would be
+	// longer than MiterLimit times the thickness, a bevel joint is drawn instead.
+	MiterLineJoin
+
+	// BevelLineJoin connects the outer corners of the segments with a straight edge.
+	BevelLineJoin
+
+	// RoundLineJoin rounds the outer corner of the joint.
+	RoundLineJoin
+)
+
+// AdaptivePrecision is a Precision, which chooses the number of segments of each circle, ellipse
+// and round shape from it's radius on the Target, so that it deviates from the segments by at most
+// the Tolerance. Small curves get only a few segments, while large or zoomed-in ones stay smooth.
+const AdaptivePrecision = 0
+
 // New creates a new empty IMDraw. An optional Picture can be used to draw with a Picture.
 //
 // If you just want to draw primitive shapes, pass nil as the Picture.
@@ -93,6 +181,7 @@ func New(pic pixel.Picture) *IMDraw {
 	im := &IMDraw{
 		tri:   tri,
 		batch: pixel.NewBatch(tri, pic),
+		pic:   pic,
 	}
 	im.SetMatrix(pixel.IM)
 	im.SetColorMask(pixel.Alpha(1))
@@ -116,6 +205,14 @@ func (imd *IMDraw) Reset() {
 	imd.Intensity = 0
 	imd.Precision = 64
 	imd.EndShape = NoEndShape
+	imd.LineJoin = EndShapeLineJoin
+	imd.MiterLimit = 4
+	imd.Dash = nil
+	imd.DashOffset = 0
+	imd.Tolerance = 0.25
+	imd.FillRule = NonZeroFillRule
+	imd.Feather = 0
+	imd.Fill = nil
 }
 
 // Draw draws all currently drawn shapes inside the IM onto another Target.
@@ -128,18 +225,31 @@ func (imd *IMDraw) Draw(t pixel.Target) {
 // Push adds some points to the IM queue. All Pushed points will have the same properties except for
 // the position.
 func (imd *IMDraw) Push(pts ...pixel.Vec) {
+	opts := imd.pointOpts()
+	for _, pt := range pts {
+		imd.pushPt(pt, opts)
+	}
+}
+
+// pointOpts returns a point with the current properties.
+func (imd *IMDraw) pointOpts() point {
 	if _, ok := imd.Color.(pixel.RGBA); !ok {
 		imd.Color = pixel.ToRGBA(imd.Color)
 	}
-	opts := point{
-		col:       imd.Color.(pixel.RGBA),
-		pic:       imd.Picture,
-		in:        imd.Intensity,
-		precision: imd.Precision,
-		endshape:  imd.EndShape,
-	}
-	for _, pt := range pts {
-		imd.pushPt(pt, opts)
+	return point{
+		col:        imd.Color.(pixel.RGBA),
+		pic:        imd.Picture,
+		in:         imd.Intensity,
+		precision:  imd.Precision,
+		endshape:   imd.EndShape,
+		linejoin:   imd.LineJoin,
+		miterLimit: imd.MiterLimit,
+		dash:       imd.Dash,
+		dashOffset: imd.DashOffset,
+		tolerance:  imd.Tolerance,
+		fillRule:   imd.FillRule,
+		feather:    imd.Feather,
+		fill:       imd.Fill,
 	}
 }
 
@@ -171,8 +281,20 @@ func (imd *IMDraw) MakePicture(p pixel.Picture) pixel.TargetPicture {
 }
 
 // Line draws a polyline of the specified thickness between the Pushed points.
+//
+// If the first Pushed point has a Dash pattern, the line is split into dashes following the
+// pattern. The pattern continues across the points, so a single dash may go around a corner. Each
+// dash is drawn as a separate line with the EndShape of the point it starts at on both ends, so
+// zero-length dashes with RoundEndShape draw dots:
+//
+//   imd.Dash = []float64{0, 8}
+//   imd.EndShape = imdraw.RoundEndShape
+//
+// The same applies to all outlines.
 func (imd *IMDraw) Line(thickness float64) {
+	f := imd.beginFringe()
 	imd.polyline(thickness, false)
+	imd.endFringe(f)
 }
 
 // Rectangle draws a rectangle between each two subsequent Pushed points. Drawing a rectangle
@@ -182,11 +304,13 @@ func (imd *IMDraw) Line(thickness float64) {
 // If the thickness is 0, rectangles will be filled, otherwise will be outlined with the given
 // thickness.
 func (imd *IMDraw) Rectangle(thickness float64) {
+	f := imd.beginFringe()
 	if thickness == 0 {
 		imd.fillRectangle()
 	} else {
 		imd.outlineRectangle(thickness)
 	}
+	imd.endFringe(f)
 }
 
 // Polygon draws a polygon from the Pushed points. If the thickness is 0, the convex polygon will be
@@ -197,21 +321,25 @@ func (imd *IMDraw) Rectangle(thickness float64) {
 // triangle is drawn between each two adjacent points and the first Pushed point. You can use this
 // property to draw certain kinds of concave polygons.
 func (imd *IMDraw) Polygon(thickness float64) {
+	f := imd.beginFringe()
 	if thickness == 0 {
 		imd.fillPolygon()
 	} else {
 		imd.polyline(thickness, true)
 	}
+	imd.endFringe(f)
 }
 
 // Circle draws a circle of the specified radius around each Pushed point. If the thickness is 0,
 // the circle will be filled, otherwise a circle outline of the specified thickness will be drawn.
 func (imd *IMDraw) Circle(radius, thickness float64) {
+	f := imd.beginFringe()
 	if thickness == 0 {
 		imd.fillEllipseArc(pixel.V(radius, radius), 0, 2*math.Pi)
 	} else {
 		imd.outlineEllipseArc(pixel.V(radius, radius), 0, 2*math.Pi, thickness, false)
 	}
+	imd.endFringe(f)
 }
 
 // CircleArc draws a circle arc of the specified radius around each Pushed point. If the thickness
@@ -223,22 +351,26 @@ func (imd *IMDraw) Circle(radius, thickness float64) {
 //
 // This line will fill the whole circle 4 times.
 func (imd *IMDraw) CircleArc(radius, low, high, thickness float64) {
+	f := imd.beginFringe()
 	if thickness == 0 {
 		imd.fillEllipseArc(pixel.V(radius, radius), low, high)
 	} else {
 		imd.outlineEllipseArc(pixel.V(radius, radius), low, high, thickness, true)
 	}
+	imd.endFringe(f)
 }
 
 // Ellipse draws an ellipse of the specified radius in each axis around each Pushed points. If the
 // thickness is 0, the ellipse will be filled, otherwise an ellipse outline of the specified
 // thickness will be drawn.
 func (imd *IMDraw) Ellipse(radius pixel.Vec, thickness float64) {
+	f := imd.beginFringe()
 	if thickness == 0 {
 		imd.fillEllipseArc(radius, 0, 2*math.Pi)
 	} else {
 		imd.outlineEllipseArc(radius, 0, 2*math.Pi, thickness, false)
 	}
+	imd.endFringe(f)
 }
 
 // EllipseArc draws an ellipse arc of the specified radius in each axis around each Pushed point. If
@@ -250,11 +382,13 @@ func (imd *IMDraw) Ellipse(radius pixel.Vec, thickness float64) {
 //
 // This line will fill the whole ellipse 4 times.
 func (imd *IMDraw) EllipseArc(radius pixel.Vec, low, high, thickness float64) {
+	f := imd.beginFringe()
 	if thickness == 0 {
 		imd.fillEllipseArc(radius, low, high)
 	} else {
 		imd.outlineEllipseArc(radius, low, high, thickness, true)
 	}
+	imd.endFringe(f)
 }
 
 func (imd *IMDraw) getAndClearPoints() []point {
@@ -282,6 +416,43 @@ func (imd *IMDraw) applyMatrixAndMask(off int) {
 	}
 }
 
+// segments returns the number of segments of an ellipse arc with the radius and the angle, drawn
+// with the Precision and the Tolerance of the point.
+func (imd *IMDraw) segments(pt point, radius pixel.Vec, angle float64) float64 {
+	angle = math.Abs(angle)
+	if pt.precision != AdaptivePrecision {
+		return math.Ceil(angle / (2 * math.Pi) * float64(pt.precision))
+	}
+
+	tolerance := imd.tolerance(pt)
+	r := math.Max(math.Abs(radius.X), math.Abs(radius.Y))
+	// maximal angle of a chord, which is at most tolerance away from the arc, but at least four
+	// segments per full turn
+	step := math.Pi / 2
+	if tolerance < r {
+		step = math.Min(step, 2*math.Acos(1-tolerance/r))
+	}
+	return math.Ceil(angle / step)
+}
+
+// tolerance returns the Tolerance of the point in the coordinates of the Pushed points, which
+// differ from the coordinates of the Target by the matrix.
+func (imd *IMDraw) tolerance(pt point) float64 {
+	tolerance := pt.tolerance
+	if tolerance <= 0 {
+		tolerance = 0.25
+	}
+	return tolerance / matrixScale(imd.matrix)
+}
+
+// matrixScale returns the maximal factor the Matrix scales lengths by.
+func matrixScale(m pixel.Matrix) float64 {
+	// the largest singular value of the linear part of the matrix
+	sum := m[0]*m[0] + m[1]*m[1] + m[2]*m[2] + m[3]*m[3]
+	det := m[0]*m[3] - m[1]*m[2]
+	return math.Sqrt((sum + math.Sqrt(math.Max(0, sum*sum-4*det*det))) / 2)
+}
+
 func (imd *IMDraw) fillRectangle() {
 	points := imd.getAndClearPoints()
 
@@ -316,6 +487,7 @@ func (imd *IMDraw) fillRectangle() {
 		}
 	}
 
+	imd.applyFill(off, points[0].fill)
 	imd.applyMatrixAndMask(off)
 	imd.batch.Dirty()
 
@@ -367,6 +539,7 @@ func (imd *IMDraw) fillPolygon() {
 		}
 	}
 
+	imd.applyFill(off, points[0].fill)
 	imd.applyMatrixAndMask(off)
 	imd.batch.Dirty()
 
@@ -377,7 +550,7 @@ func (imd *IMDraw) fillEllipseArc(radius pixel.Vec, low, high float64) {
 	points := imd.getAndClearPoints()
 
 	for _, pt := range points {
-		num := math.Ceil(math.Abs(high-low) / (2 * math.Pi) * float64(pt.precision))
+		num := imd.segments(pt, radius, high-low)
 		delta := (high - low) / num
 
 		off := imd.tri.Len()
@@ -409,6 +582,7 @@ func (imd *IMDraw) fillEllipseArc(radius pixel.Vec, low, high float64) {
 			(*imd.tri)[j+2].Position = b
 		}
 
+		imd.applyFill(off, pt.fill)
 		imd.applyMatrixAndMask(off)
 		imd.batch.Dirty()
 	}
@@ -420,7 +594,12 @@ func (imd *IMDraw) outlineEllipseArc(radius pixel.Vec, low, high, thickness floa
 	points := imd.getAndClearPoints()
 
 	for _, pt := range points {
-		num := math.Ceil(math.Abs(high-low) / (2 * math.Pi) * float64(pt.precision))
+		if dashPattern(pt.dash) != nil {
+			imd.dashedEllipseArc(pt, radius, low, high, thickness, doEndShape)
+			continue
+		}
+
+		num := imd.segments(pt, radius, high-low)
 		delta := (high - low) / num
 
 		off := imd.tri.Len()
@@ -465,6 +644,7 @@ func (imd *IMDraw) outlineEllipseArc(radius pixel.Vec, low, high, thickness floa
 			(*imd.tri)[j+5].Position = d
 		}
 
+		imd.applyFill(off, pt.fill)
 		imd.applyMatrixAndMask(off)
 		imd.batch.Dirty()
 
@@ -504,6 +684,21 @@ func (imd *IMDraw) outlineEllipseArc(radius pixel.Vec, low, high, thickness floa
 				imd.pushPt(highCenter.Sub(thick), pt)
 				imd.pushPt(highCenter.Add(thick.Normal().Scaled(orientation)), pt)
 				imd.fillPolygon()
+			case SquareEndShape:
+				thick := pixel.V(thickness/2, 0).Rotated(normalLow)
+				back := thick.Normal().Scaled(-orientation)
+				imd.pushPt(lowCenter.Add(thick), pt)
+				imd.pushPt(lowCenter.Add(thick).Add(back), pt)
+				imd.pushPt(lowCenter.Sub(thick).Add(back), pt)
+				imd.pushPt(lowCenter.Sub(thick), pt)
+				imd.fillPolygon()
+				thick = pixel.V(thickness/2, 0).Rotated(normalHigh)
+				fwd := thick.Normal().Scaled(orientation)
+				imd.pushPt(highCenter.Add(thick), pt)
+				imd.pushPt(highCenter.Add(thick).Add(fwd), pt)
+				imd.pushPt(highCenter.Sub(thick).Add(fwd), pt)
+				imd.pushPt(highCenter.Sub(thick), pt)
+				imd.fillPolygon()
 			case RoundEndShape:
 				imd.pushPt(lowCenter, pt)
 				imd.fillEllipseArc(pixel.V(thickness/2, thickness/2), normalLow, normalLow-math.Pi*orientation)
@@ -527,6 +722,11 @@ func (imd *IMDraw) polyline(thickness float64, closed bool) {
 		// one point special case
 		points = append(points, points[0])
 	}
+	if pattern := dashPattern(points[0].dash); pattern != nil {
+		imd.dashedPolyline(points, pattern, thickness, closed)
+		imd.restorePoints(points)
+		return
+	}
 
 	// first point
 	j, i := 0, 1
@@ -541,6 +741,13 @@ func (imd *IMDraw) polyline(thickness float64, closed bool) {
 			imd.pushPt(points[j].pos.Sub(ijNormal), points[j])
 			imd.pushPt(points[j].pos.Add(ijNormal.Normal()), points[j])
 			imd.fillPolygon()
+		case SquareEndShape:
+			back := ijNormal.Normal()
+			imd.pushPt(points[j].pos.Add(ijNormal), points[j])
+			imd.pushPt(points[j].pos.Add(ijNormal).Add(back), points[j])
+			imd.pushPt(points[j].pos.Sub(ijNormal).Add(back), points[j])
+			imd.pushPt(points[j].pos.Sub(ijNormal), points[j])
+			imd.fillPolygon()
 		case RoundEndShape:
 			imd.pushPt(points[j].pos, points[j])
 			imd.fillEllipseArc(pixel.V(thickness/2, thickness/2), ijNormal.Angle(), ijNormal.Angle()+math.Pi)
@@ -577,20 +784,7 @@ func (imd *IMDraw) polyline(thickness float64, closed bool) {
 		imd.pushPt(points[j].pos.Add(ijNormal), points[j])
 		imd.fillPolygon()
 
-		switch points[j].endshape {
-		case NoEndShape:
-			// nothing
-		case SharpEndShape:
-			imd.pushPt(points[j].pos, points[j])
-			imd.pushPt(points[j].pos.Add(ijNormal.Scaled(orientation)), points[j])
-			imd.pushPt(points[j].pos.Add(jkNormal.Scaled(orientation)), points[j])
-			imd.fillPolygon()
-		case RoundEndShape:
-			imd.pushPt(points[j].pos, points[j])
-			imd.fillEllipseArc(pixel.V(thickness/2, thickness/2), ijNormal.Angle(), ijNormal.Angle()-math.Pi)
-			imd.pushPt(points[j].pos, points[j])
-			imd.fillEllipseArc(pixel.V(thickness/2, thickness/2), jkNormal.Angle(), jkNormal.Angle()+math.Pi)
-		}
+		imd.lineJoin(points[j], ijNormal, jkNormal, orientation, thickness)
 
 		if !closing {
 			imd.pushPt(points[j].pos.Add(jkNormal), points[j])
@@ -617,6 +811,13 @@ func (imd *IMDraw) polyline(thickness float64, closed bool) {
 			imd.pushPt(points[j].pos.Sub(ijNormal), points[j])
 			imd.pushPt(points[j].pos.Add(ijNormal.Normal().Scaled(-1)), points[j])
 			imd.fillPolygon()
+		case SquareEndShape:
+			fwd := ijNormal.Normal().Scaled(-1)
+			imd.pushPt(points[j].pos.Add(ijNormal), points[j])
+			imd.pushPt(points[j].pos.Add(ijNormal).Add(fwd), points[j])
+			imd.pushPt(points[j].pos.Sub(ijNormal).Add(fwd), points[j])
+			imd.pushPt(points[j].pos.Sub(ijNormal), points[j])
+			imd.fillPolygon()
 		case RoundEndShape:
 			imd.pushPt(points[j].pos, points[j])
 			imd.fillEllipseArc(pixel.V(thickness/2, thickness/2), ijNormal.Angle(), ijNormal.Angle()-math.Pi)
@@ -625,3 +826,67 @@ func (imd *IMDraw) polyline(thickness float64, closed bool) {
 
 	imd.restorePoints(points)
 }
+
+// lineJoin draws the joint of two line segments at the point. The length of the normals is half of
+// the thickness and the orientation flips them to the outer side of the joint.
+func (imd *IMDraw) lineJoin(pt point, ijNormal, jkNormal pixel.Vec, orientation, thickness float64) {
+	outerIJ, outerJK := ijNormal.Scaled(orientation), jkNormal.Scaled(orientation)
+
+	switch pt.linejoin {
+	case EndShapeLineJoin:
+		switch pt.endshape {
+		case NoEndShape:
+			// nothing
+		case SharpEndShape, SquareEndShape:
+			imd.bevelJoin(pt, outerIJ, outerJK)
+		case RoundEndShape:
+			imd.pushPt(pt.pos, pt)
+			imd.fillEllipseArc(pixel.V(thickness/2, thickness/2), ijNormal.Angle(), ijNormal.Angle()-math.Pi)
+			imd.pushPt(pt.pos, pt)
+			imd.fillEllipseArc(pixel.V(thickness/2, thickness/2), jkNormal.Angle(), jkNormal.Angle()+math.Pi)
+		}
+	case MiterLineJoin:
+		// the tip lies on the bisector of the normals, where the outer edges meet, which has no
+		// direction when the line reverses
+		sum := outerIJ.Add(outerJK)
+		if sum.Len() < 1e-9*thickness {
+			imd.bevelJoin(pt, outerIJ, outerJK)
+			return
+		}
+		bisector := sum.Unit()
+		cos := bisector.Dot(outerIJ) / (thickness / 2)
+		if cos <= 0 || 1/cos > pt.miterLimit {
+			imd.bevelJoin(pt, outerIJ, outerJK)
+			return
+		}
+		imd.pushPt(pt.pos, pt)
+		imd.pushPt(pt.pos.Add(outerIJ), pt)
+		imd.pushPt(pt.pos.Add(bisector.Scaled(thickness/2/cos)), pt)
+		imd.pushPt(pt.pos.Add(outerJK), pt)
+		imd.fillPolygon()
+	case BevelLineJoin:
+		imd.bevelJoin(pt, outerIJ, outerJK)
+	case RoundLineJoin:
+		angle := outerIJ.Angle()
+		delta := outerJK.Angle() - angle
+		for delta > math.Pi {
+			delta -= 2 * math.Pi
+		}
+		for delta < -math.Pi {
+			delta += 2 * math.Pi
+		}
+		if delta == 0 {
+			return
+		}
+		imd.pushPt(pt.pos, pt)
+		imd.fillEllipseArc(pixel.V(thickness/2, thickness/2), angle, angle+delta)
+	}
+}
+
+// bevelJoin fills the triangle between the point and the outer corners of the segments.
+func (imd *IMDraw) bevelJoin(pt point, outerIJ, outerJK pixel.Vec) {
+	imd.pushPt(pt.pos, pt)
+	imd.pushPt(pt.pos.Add(outerIJ), pt)
+	imd.pushPt(pt.pos.Add(outerJK), pt)
+	imd.fillPolygon()
+}
@@ -1,14 +1,881 @@
 package imdraw_test
 
 import (
+	"bytes"
 	"fmt"
+	"image/color"
+	"math"
 	"math/rand"
+	"reflect"
 	"testing"
 
 	"github.com/faiface/pixel"
 	"github.com/faiface/pixel/imdraw"
+	"github.com/faiface/pixel/pixeltest"
 )
 
+func TestIMDraw_Golden(t *testing.T) {
+	img := pixeltest.Render(pixel.R(0, 0, 128, 64), func(target pixel.Target) {
+		imd := imdraw.New(nil)
+
+		imd.Color = pixel.RGB(1, 0, 0)
+		imd.Push(pixel.V(32, 32))
+		imd.Circle(24, 0)
+
+		imd.Color = pixel.RGB(0, 1, 0)
+		imd.Push(pixel.V(68, 8), pixel.V(120, 56))
+		imd.Rectangle(4)
+
+		imd.Color = pixel.RGB(0, 0, 1)
+		imd.EndShape = imdraw.RoundEndShape
+		imd.Push(pixel.V(16, 48), pixel.V(64, 16), pixel.V(112, 48))
+		imd.Line(6)
+
+		imd.Draw(target)
+	})
+	pixeltest.AssertGolden(t, "testdata/shapes.png", img, pixeltest.Options{Threshold: 0.1})
+}
+
+func TestIMDraw_Dash(t *testing.T) {
+	draw := func(setup func(imd *imdraw.IMDraw)) *pixeltest.Canvas {
+		c := pixeltest.NewCanvas(pixel.R(0, 0, 128, 64))
+		imd := imdraw.New(nil)
+		setup(imd)
+		imd.Draw(c)
+		return c
+	}
+	check := func(t *testing.T, c *pixeltest.Canvas, on, off []pixel.Vec) {
+		t.Helper()
+		for _, p := range on {
+			if c.Color(p).A == 0 {
+				t.Errorf("pixel %v is not drawn", p)
+			}
+		}
+		for _, p := range off {
+			if c.Color(p).A != 0 {
+				t.Errorf("pixel %v is drawn", p)
+			}
+		}
+	}
+
+	t.Run("Line", func(t *testing.T) {
+		c := draw(func(imd *imdraw.IMDraw) {
+			imd.Dash = []float64{10, 10}
+			imd.DashOffset = 5
+			imd.Push(pixel.V(0, 5), pixel.V(100, 5))
+			imd.Line(4)
+		})
+		check(t, c,
+			[]pixel.Vec{pixel.V(2.5, 5.5), pixel.V(17.5, 5.5), pixel.V(97.5, 5.5)},
+			[]pixel.Vec{pixel.V(7.5, 5.5), pixel.V(12.5, 5.5), pixel.V(92.5, 5.5)},
+		)
+	})
+
+	t.Run("Corner", func(t *testing.T) {
+		c := draw(func(imd *imdraw.IMDraw) {
+			imd.Dash = []float64{60, 10}
+			imd.Push(pixel.V(0, 5), pixel.V(50, 5), pixel.V(50, 55))
+			imd.Line(4)
+		})
+		check(t, c,
+			[]pixel.Vec{pixel.V(45.5, 5.5), pixel.V(50.5, 12.5), pixel.V(50.5, 27.5)},
+			[]pixel.Vec{pixel.V(50.5, 17.5), pixel.V(50.5, 22.5)},
+		)
+	})
+
+	t.Run("Dots", func(t *testing.T) {
+		c := draw(func(imd *imdraw.IMDraw) {
+			imd.Dash = []float64{0, 10}
+			imd.EndShape = imdraw.RoundEndShape
+			imd.Push(pixel.V(10, 5), pixel.V(60, 5))
+			imd.Line(4)
+		})
+		check(t, c,
+			[]pixel.Vec{pixel.V(10.5, 5.5), pixel.V(9.5, 6.5), pixel.V(30.5, 4.5)},
+			[]pixel.Vec{pixel.V(15.5, 5.5), pixel.V(5.5, 5.5), pixel.V(10.5, 8.5)},
+		)
+	})
+
+	t.Run("Golden", func(t *testing.T) {
+		c := draw(func(imd *imdraw.IMDraw) {
+			imd.Color = pixel.RGB(1, 0.5, 0)
+			imd.Dash = []float64{8, 4}
+			imd.Push(pixel.V(8, 8), pixel.V(56, 56))
+			imd.Rectangle(2)
+
+			imd.Color = pixel.RGB(0, 0.5, 1)
+			imd.Dash = []float64{0, 6}
+			imd.EndShape = imdraw.RoundEndShape
+			imd.Push(pixel.V(92, 32))
+			imd.Circle(24, 3)
+		})
+		pixeltest.AssertGolden(t, "testdata/dashes.png", c.Image(), pixeltest.Options{Threshold: 0.1})
+	})
+}
+
+func TestIMDraw_LineJoin(t *testing.T) {
+	draw := func(join imdraw.LineJoin, miterLimit float64, end imdraw.EndShape) *pixeltest.Canvas {
+		c := pixeltest.NewCanvas(pixel.R(0, 0, 64, 64))
+		imd := imdraw.New(nil)
+		imd.LineJoin = join
+		imd.MiterLimit = miterLimit
+		imd.EndShape = end
+		imd.Push(pixel.V(10, 10), pixel.V(30, 50), pixel.V(50, 10))
+		imd.Line(8)
+		imd.Draw(c)
+		return c
+	}
+	drawn := func(c *pixeltest.Canvas, x, y float64) bool {
+		return c.Color(pixel.V(x, y)).A != 0
+	}
+
+	tests := []struct {
+		name       string
+		join       imdraw.LineJoin
+		miterLimit float64
+		end        imdraw.EndShape
+		tip, round bool
+	}{
+		{"Miter", imdraw.MiterLineJoin, 4, imdraw.NoEndShape, true, true},
+		{"MiterLimit", imdraw.MiterLineJoin, 2, imdraw.NoEndShape, false, false},
+		{"Bevel", imdraw.BevelLineJoin, 4, imdraw.NoEndShape, false, false},
+		{"Round", imdraw.RoundLineJoin, 4, imdraw.NoEndShape, false, true},
+		{"EndShape", imdraw.EndShapeLineJoin, 4, imdraw.RoundEndShape, false, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := draw(tt.join, tt.miterLimit, tt.end)
+			if got := drawn(c, 30.5, 56.5); got != tt.tip {
+				t.Errorf("miter tip drawn: %v, want %v", got, tt.tip)
+			}
+			if got := drawn(c, 30.5, 53.5); got != tt.round {
+				t.Errorf("round joint drawn: %v, want %v", got, tt.round)
+			}
+		})
+	}
+}
+
+func TestIMDraw_MiterReversal(t *testing.T) {
+	a, b := pixel.V(10, 32), pixel.V(50, 33)
+	for _, pts := range [][]pixel.Vec{{a, b, a}, {b, a, b}} {
+		c := pixeltest.NewCanvas(pixel.R(0, 0, 64, 64))
+		imd := imdraw.New(nil)
+		imd.LineJoin = imdraw.MiterLineJoin
+		imd.MiterLimit = 100
+		imd.Push(pts...)
+		imd.Line(8)
+		imd.Draw(c)
+
+		// nothing may be drawn further than half of the thickness from the segment
+		seg := pixel.L(a, b)
+		for x := 0.5; x < 64; x++ {
+			for y := 0.5; y < 64; y++ {
+				at := pixel.V(x, y)
+				if c.Color(at).A != 0 && seg.Closest(at).To(at).Len() > 5 {
+					t.Fatalf("%v: pixel %v drawn outside of the line", pts, at)
+				}
+			}
+		}
+	}
+}
+
+func TestIMDraw_SquareEndShape(t *testing.T) {
+	for _, end := range []imdraw.EndShape{imdraw.NoEndShape, imdraw.SquareEndShape} {
+		c := pixeltest.NewCanvas(pixel.R(0, 0, 64, 16))
+		imd := imdraw.New(nil)
+		imd.EndShape = end
+		imd.Push(pixel.V(10, 5), pixel.V(50, 5))
+		imd.Line(4)
+		imd.Push(pixel.V(32, 10))
+		imd.CircleArc(20, 0, math.Pi/2, 4)
+		imd.Draw(c)
+
+		want := end == imdraw.SquareEndShape
+		for _, p := range []pixel.Vec{pixel.V(8.5, 5.5), pixel.V(51.5, 4.5), pixel.V(52.5, 8.5)} {
+			if got := c.Color(p).A != 0; got != want {
+				t.Errorf("EndShape %v: pixel %v drawn: %v, want %v", end, p, got, want)
+			}
+		}
+	}
+}
+
+func TestIMDraw_Path(t *testing.T) {
+	square := func(p *imdraw.Path, min, max pixel.Vec, ccw bool) {
+		pts := []pixel.Vec{min, pixel.V(max.X, min.Y), max, pixel.V(min.X, max.Y)}
+		if !ccw {
+			pts[1], pts[3] = pts[3], pts[1]
+		}
+		p.MoveTo(pts[0])
+		for _, pt := range pts[1:] {
+			p.LineTo(pt)
+		}
+		p.Close()
+	}
+	draw := func(p *imdraw.Path, thickness float64, setup func(imd *imdraw.IMDraw)) *pixeltest.Canvas {
+		c := pixeltest.NewCanvas(pixel.R(0, 0, 64, 64))
+		imd := imdraw.New(nil)
+		if setup != nil {
+			setup(imd)
+		}
+		imd.Path(p, thickness)
+		imd.Draw(c)
+		return c
+	}
+	check := func(t *testing.T, c *pixeltest.Canvas, inside, outside []pixel.Vec) {
+		t.Helper()
+		for _, p := range inside {
+			if c.Color(p).A == 0 {
+				t.Errorf("pixel %v not drawn", p)
+			}
+		}
+		for _, p := range outside {
+			if c.Color(p).A != 0 {
+				t.Errorf("pixel %v drawn", p)
+			}
+		}
+	}
+
+	t.Run("Holes", func(t *testing.T) {
+		tests := []struct {
+			name string
+			rule imdraw.FillRule
+			ccw  bool
+			hole bool
+		}{
+			{"NonZeroSameWinding", imdraw.NonZeroFillRule, true, false},
+			{"NonZeroOppositeWinding", imdraw.NonZeroFillRule, false, true},
+			{"EvenOddSameWinding", imdraw.EvenOddFillRule, true, true},
+			{"EvenOddOppositeWinding", imdraw.EvenOddFillRule, false, true},
+		}
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				var p imdraw.Path
+				square(&p, pixel.V(8, 8), pixel.V(56, 56), true)
+				square(&p, pixel.V(24, 24), pixel.V(40, 40), tt.ccw)
+				c := draw(&p, 0, func(imd *imdraw.IMDraw) {
+					imd.FillRule = tt.rule
+				})
+
+				ring := []pixel.Vec{pixel.V(12.5, 12.5), pixel.V(51.5, 32.5), pixel.V(32.5, 20.5)}
+				hole := []pixel.Vec{pixel.V(32.5, 32.5), pixel.V(25.5, 38.5)}
+				out := []pixel.Vec{pixel.V(4.5, 4.5), pixel.V(60.5, 32.5)}
+				if tt.hole {
+					check(t, c, ring, append(out, hole...))
+				} else {
+					check(t, c, append(ring, hole...), out)
+				}
+			})
+		}
+	})
+
+	t.Run("SelfIntersecting", func(t *testing.T) {
+		// a pentagram, the center is wound around twice
+		var p imdraw.Path
+		for i := 0; i < 5; i++ {
+			angle := math.Pi/2 + float64(i)*4*math.Pi/5
+			p.LineTo(pixel.V(32, 32).Add(pixel.V(28, 0).Rotated(angle)))
+		}
+		p.Close()
+
+		points := []pixel.Vec{pixel.V(32.5, 50.5)}
+		center := []pixel.Vec{pixel.V(32.5, 32.5)}
+		check(t, draw(&p, 0, nil), append(points, center...), nil)
+		check(t, draw(&p, 0, func(imd *imdraw.IMDraw) {
+			imd.FillRule = imdraw.EvenOddFillRule
+		}), points, center)
+	})
+
+	t.Run("Curves", func(t *testing.T) {
+		var p imdraw.Path
+		p.MoveTo(pixel.V(4, 4))
+		p.QuadTo(pixel.V(32, 60), pixel.V(60, 4))
+		p.Close()
+		p.MoveTo(pixel.V(4, 60))
+		p.CubicTo(pixel.V(4, 40), pixel.V(24, 40), pixel.V(24, 60))
+		p.Arc(pixel.V(44, 60), 10, math.Pi, 2*math.Pi)
+
+		// the quad peaks at y=32, the cubic at y=45 and the arc at y=50
+		check(t, draw(&p, 0, nil),
+			[]pixel.Vec{pixel.V(32.5, 30.5), pixel.V(14.5, 46.5), pixel.V(44.5, 51.5)},
+			[]pixel.Vec{pixel.V(32.5, 33.5), pixel.V(14.5, 44.5), pixel.V(44.5, 49.5)},
+		)
+	})
+
+	t.Run("ArcTo", func(t *testing.T) {
+		// a square with the bottom-right corner rounded around (32, 32)
+		var p imdraw.Path
+		p.MoveTo(pixel.V(8, 8))
+		p.ArcTo(pixel.V(56, 8), pixel.V(56, 56), 24)
+		if got, want := p.Current(), pixel.V(56, 32); got.To(want).Len() > 1e-9 {
+			t.Errorf("Current() = %v, want %v", got, want)
+		}
+		p.LineTo(pixel.V(56, 56))
+		p.LineTo(pixel.V(8, 56))
+		p.Close()
+
+		check(t, draw(&p, 0, nil),
+			[]pixel.Vec{pixel.V(32.5, 8.5), pixel.V(48.5, 15.5), pixel.V(55.5, 32.5)},
+			[]pixel.Vec{pixel.V(52.5, 11.5), pixel.V(55.5, 8.5)},
+		)
+
+		// points on a line add a straight line to the corner
+		p.Clear()
+		p.MoveTo(pixel.V(8, 8))
+		p.ArcTo(pixel.V(32, 8), pixel.V(56, 8), 24)
+		if got, want := p.Current(), pixel.V(32, 8); got != want {
+			t.Errorf("Current() = %v, want %v", got, want)
+		}
+	})
+
+	t.Run("Stroke", func(t *testing.T) {
+		var p imdraw.Path
+		square(&p, pixel.V(8, 8), pixel.V(56, 56), true)
+		p.MoveTo(pixel.V(20, 32))
+		p.LineTo(pixel.V(44, 32))
+
+		c := draw(&p, 4, func(imd *imdraw.IMDraw) {
+			imd.LineJoin = imdraw.MiterLineJoin
+			imd.Push(pixel.V(32, 20))
+		})
+		check(t, c,
+			[]pixel.Vec{pixel.V(7.5, 7.5), pixel.V(32.5, 56.5), pixel.V(20.5, 32.5), pixel.V(43.5, 31.5)},
+			[]pixel.Vec{pixel.V(32.5, 20.5), pixel.V(18.5, 32.5), pixel.V(45.5, 32.5), pixel.V(32.5, 44.5)},
+		)
+	})
+
+	t.Run("PushedPoints", func(t *testing.T) {
+		var p imdraw.Path
+		square(&p, pixel.V(8, 8), pixel.V(16, 16), true)
+		c := draw(&p, 2, func(imd *imdraw.IMDraw) {
+			imd.Push(pixel.V(40, 40))
+			imd.Path(&p, 0)
+			imd.Circle(4, 0)
+		})
+		check(t, c, []pixel.Vec{pixel.V(12.5, 12.5), pixel.V(40.5, 40.5)}, nil)
+	})
+
+	t.Run("Golden", func(t *testing.T) {
+		var p imdraw.Path
+		p.MoveTo(pixel.V(64, 8))
+		p.CubicTo(pixel.V(8, 40), pixel.V(24, 72), pixel.V(64, 48))
+		p.CubicTo(pixel.V(104, 72), pixel.V(120, 40), pixel.V(64, 8))
+		p.Close()
+		p.MoveTo(pixel.V(64, 36).Add(pixel.V(16, 0).Rotated(math.Pi / 8)))
+		p.EllipseArc(pixel.V(64, 36), pixel.V(16, 8), math.Pi/8, 0, 2*math.Pi)
+		p.Close()
+
+		img := pixeltest.Render(pixel.R(0, 0, 128, 64), func(target pixel.Target) {
+			imd := imdraw.New(nil)
+			imd.Color = pixel.RGB(1, 0.5, 0)
+			imd.FillRule = imdraw.EvenOddFillRule
+			imd.Path(&p, 0)
+			imd.Color = pixel.RGB(0, 0, 0.5)
+			imd.LineJoin = imdraw.RoundLineJoin
+			imd.Path(&p, 2)
+			imd.Draw(target)
+		})
+		pixeltest.AssertGolden(t, "testdata/path.png", img, pixeltest.Options{Threshold: 0.1})
+	})
+}
+
+func TestIMDraw_Shapes(t *testing.T) {
+	tests := []struct {
+		name    string
+		draw    func(imd *imdraw.IMDraw)
+		in, out []pixel.Vec
+	}{
+		{
+			name: "RoundedRectangle",
+			draw: func(imd *imdraw.IMDraw) {
+				imd.Push(pixel.V(4, 4), pixel.V(60, 28))
+				imd.RoundedRectangle(8, 0)
+			},
+			in:  []pixel.Vec{pixel.V(8.5, 8.5), pixel.V(32.5, 16.5), pixel.V(32.5, 4.5)},
+			out: []pixel.Vec{pixel.V(4.5, 4.5), pixel.V(59.5, 27.5)},
+		},
+		{
+			name: "RoundedRectangleCorners",
+			draw: func(imd *imdraw.IMDraw) {
+				imd.Push(pixel.V(4, 4), pixel.V(60, 28))
+				imd.RoundedRectangleCorners(0, 8, 100, 8, 2)
+			},
+			in:  []pixel.Vec{pixel.V(4.5, 4.5), pixel.V(4.5, 16.5), pixel.V(56.5, 24.5)},
+			out: []pixel.Vec{pixel.V(32.5, 16.5), pixel.V(59.5, 27.5), pixel.V(58.5, 26.5)},
+		},
+		{
+			name: "RegularPolygon",
+			draw: func(imd *imdraw.IMDraw) {
+				imd.Push(pixel.V(32, 16))
+				imd.RegularPolygon(4, 10, 0, 0)
+			},
+			in:  []pixel.Vec{pixel.V(32.5, 16.5), pixel.V(40.5, 16.5), pixel.V(32.5, 24.5)},
+			out: []pixel.Vec{pixel.V(38.5, 22.5), pixel.V(25.5, 9.5)},
+		},
+		{
+			name: "Star",
+			draw: func(imd *imdraw.IMDraw) {
+				imd.Push(pixel.V(32, 16))
+				imd.Star(5, 12, 5, math.Pi/2, 0)
+			},
+			in:  []pixel.Vec{pixel.V(32.5, 16.5), pixel.V(32.5, 26.5)},
+			out: []pixel.Vec{pixel.V(26.5, 23.5), pixel.V(32.5, 6.5)},
+		},
+		{
+			name: "Ring",
+			draw: func(imd *imdraw.IMDraw) {
+				imd.Push(pixel.V(32, 16))
+				imd.Ring(6, 12, 0)
+			},
+			in:  []pixel.Vec{pixel.V(41.5, 16.5), pixel.V(32.5, 7.5)},
+			out: []pixel.Vec{pixel.V(32.5, 16.5), pixel.V(35.5, 18.5), pixel.V(45.5, 16.5)},
+		},
+		{
+			name: "RingOutline",
+			draw: func(imd *imdraw.IMDraw) {
+				imd.Push(pixel.V(32, 16))
+				imd.Ring(6, 12, 2)
+			},
+			in:  []pixel.Vec{pixel.V(38.5, 16.5), pixel.V(43.5, 16.5)},
+			out: []pixel.Vec{pixel.V(32.5, 16.5), pixel.V(41.5, 16.5)},
+		},
+		{
+			name: "Pie",
+			draw: func(imd *imdraw.IMDraw) {
+				imd.Push(pixel.V(32, 16))
+				imd.Pie(12, 0, math.Pi/2, 2)
+			},
+			in:  []pixel.Vec{pixel.V(38.5, 16.5), pixel.V(32.5, 22.5), pixel.V(40.5, 24.5)},
+			out: []pixel.Vec{pixel.V(37.5, 21.5), pixel.V(28.5, 12.5)},
+		},
+		{
+			name: "Capsule",
+			draw: func(imd *imdraw.IMDraw) {
+				imd.Push(pixel.V(16, 16), pixel.V(48, 16))
+				imd.Capsule(8, 0)
+			},
+			in:  []pixel.Vec{pixel.V(8.5, 16.5), pixel.V(32.5, 23.5), pixel.V(55.5, 16.5)},
+			out: []pixel.Vec{pixel.V(9.5, 10.5), pixel.V(54.5, 22.5)},
+		},
+		{
+			name: "Arrow",
+			draw: func(imd *imdraw.IMDraw) {
+				imd.Push(pixel.V(8, 16), pixel.V(56, 16))
+				imd.Arrow(imdraw.ArrowHead{}, imdraw.ArrowHead{Length: 12, Width: 12}, 2)
+			},
+			in:  []pixel.Vec{pixel.V(8.5, 16.5), pixel.V(53.5, 16.5), pixel.V(46.5, 20.5), pixel.V(45.5, 18.5)},
+			out: []pixel.Vec{pixel.V(7.5, 16.5), pixel.V(40.5, 20.5)},
+		},
+		{
+			name: "BarbedArrow",
+			draw: func(imd *imdraw.IMDraw) {
+				imd.Push(pixel.V(8, 16), pixel.V(56, 16))
+				head := imdraw.ArrowHead{Length: 12, Width: 12, Inset: 6}
+				imd.Arrow(head, head, 2)
+			},
+			in:  []pixel.Vec{pixel.V(10.5, 16.5), pixel.V(45.5, 16.5), pixel.V(45.5, 20.5)},
+			out: []pixel.Vec{pixel.V(45.5, 18.5), pixel.V(18.5, 18.5)},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := pixeltest.NewCanvas(pixel.R(0, 0, 64, 32))
+			imd := imdraw.New(nil)
+			tt.draw(imd)
+			imd.Draw(c)
+			for _, p := range tt.in {
+				if c.Color(p).A == 0 {
+					t.Errorf("pixel %v not drawn", p)
+				}
+			}
+			for _, p := range tt.out {
+				if c.Color(p).A != 0 {
+					t.Errorf("pixel %v drawn", p)
+				}
+			}
+		})
+	}
+
+	t.Run("Golden", func(t *testing.T) {
+		img := pixeltest.Render(pixel.R(0, 0, 128, 64), func(target pixel.Target) {
+			imd := imdraw.New(nil)
+			imd.Precision = 32
+
+			imd.Color = pixel.RGB(0.2, 0.4, 1)
+			imd.Push(pixel.V(4, 36), pixel.V(40, 60))
+			imd.RoundedRectangleCorners(0, 4, 10, 4, 0)
+			imd.Push(pixel.V(56, 48))
+			imd.RegularPolygon(6, 11, math.Pi/2, 2)
+			imd.Push(pixel.V(84, 48))
+			imd.Star(5, 12, 5, math.Pi/2, 0)
+			imd.Push(pixel.V(112, 48))
+			imd.Ring(5, 11, 0)
+
+			imd.Color = pixel.RGB(1, 0.3, 0.1)
+			imd.Push(pixel.V(16, 16))
+			imd.Pie(12, math.Pi/6, 2*math.Pi, 0)
+			imd.Push(pixel.V(36, 16), pixel.V(60, 16))
+			imd.Capsule(7, 2)
+			imd.Push(pixel.V(76, 4), pixel.V(100, 28), pixel.V(120, 8))
+			imd.Arrow(imdraw.ArrowHead{Length: 6, Width: 6, Inset: -4}, imdraw.ArrowHead{Length: 10, Width: 10, Inset: 3}, 2)
+
+			imd.Draw(target)
+		})
+		pixeltest.AssertGolden(t, "testdata/primitives.png", img, pixeltest.Options{Threshold: 0.1})
+	})
+}
+
+func TestIMDraw_Feather(t *testing.T) {
+	draw := func(feather float64) *pixeltest.Canvas {
+		c := pixeltest.NewCanvas(pixel.R(0, 0, 64, 64))
+		imd := imdraw.New(nil)
+		imd.Feather = feather
+		imd.Push(pixel.V(32, 32))
+		imd.Circle(20, 0)
+		imd.Push(pixel.V(4, 4), pixel.V(12, 12))
+		imd.Rectangle(0)
+		imd.Draw(c)
+		return c
+	}
+	partial := func(c *pixeltest.Canvas, p pixel.Vec) bool {
+		a := c.Color(p).A
+		return a > 0.02 && a < 0.98
+	}
+
+	c := draw(0)
+	for _, p := range []pixel.Vec{pixel.V(52.5, 32.5), pixel.V(12.5, 8.5)} {
+		if partial(c, p) {
+			t.Errorf("Feather 0: pixel %v partially drawn: %v", p, c.Color(p))
+		}
+	}
+
+	c = draw(2)
+	for _, p := range []pixel.Vec{pixel.V(32.5, 32.5), pixel.V(50.5, 32.5), pixel.V(8.5, 8.5)} {
+		if a := c.Color(p).A; a < 0.98 {
+			t.Errorf("Feather 2: pixel %v inside not opaque: %v", p, a)
+		}
+	}
+	for _, p := range []pixel.Vec{pixel.V(53, 32.5), pixel.V(32.5, 53), pixel.V(13, 8.5), pixel.V(8.5, 3)} {
+		if !partial(c, p) {
+			t.Errorf("Feather 2: pixel %v in fringe not partially drawn: %v", p, c.Color(p))
+		}
+	}
+	for _, p := range []pixel.Vec{pixel.V(55.5, 32.5), pixel.V(15.5, 8.5)} {
+		if a := c.Color(p).A; a != 0 {
+			t.Errorf("Feather 2: pixel %v outside drawn: %v", p, a)
+		}
+	}
+}
+
+func TestIMDraw_FeatherSeams(t *testing.T) {
+	inside := func(p pixel.Vec, a, b, c pixel.Vec) bool {
+		d1, d2, d3 := a.To(b).Cross(a.To(p)), b.To(c).Cross(b.To(p)), c.To(a).Cross(c.To(p))
+		return (d1 > 0 && d2 > 0 && d3 > 0) || (d1 < 0 && d2 < 0 && d3 < 0)
+	}
+
+	joins := []imdraw.LineJoin{imdraw.MiterLineJoin, imdraw.BevelLineJoin, imdraw.RoundLineJoin}
+	for _, join := range joins {
+		tri := &pixel.TrianglesData{}
+		imd := imdraw.New(nil)
+		imd.Color = pixel.RGB(1, 0, 0).Mul(pixel.Alpha(0.5))
+		imd.Feather = 1
+		imd.LineJoin = join
+		imd.Push(pixel.V(10, 10), pixel.V(90, 10), pixel.V(50, 70), pixel.V(50, 30))
+		imd.Line(8)
+		imd.Draw(pixel.NewBatch(tri, nil))
+
+		// the fringe fades to transparent, so it's triangles have a transparent vertex
+		var stroke, fringe [][]pixel.Vec
+		for i := 0; i+2 < tri.Len(); i += 3 {
+			v := (*tri)[i : i+3]
+			pts := []pixel.Vec{v[0].Position, v[1].Position, v[2].Position}
+			if v[0].Color.A != 0 && v[1].Color.A != 0 && v[2].Color.A != 0 {
+				stroke = append(stroke, pts)
+			} else {
+				fringe = append(fringe, pts)
+			}
+		}
+		if len(fringe) == 0 {
+			t.Fatalf("join %v: no fringe drawn", join)
+		}
+		for _, f := range fringe {
+			center := f[0].Add(f[1]).Add(f[2]).Scaled(1.0 / 3)
+			for _, s := range stroke {
+				if inside(center, s[0], s[1], s[2]) {
+					t.Fatalf("join %v: fringe triangle %v inside of the stroke triangle %v", join, f, s)
+				}
+			}
+		}
+	}
+}
+
+func TestIMDraw_Fill(t *testing.T) {
+	red, green, blue := pixel.RGB(1, 0, 0), pixel.RGB(0, 1, 0), pixel.RGB(0, 0, 1)
+	near := func(a, b pixel.RGBA) bool {
+		d := a.Sub(b)
+		return math.Abs(d.R) < 0.05 && math.Abs(d.G) < 0.05 && math.Abs(d.B) < 0.05 && math.Abs(d.A) < 0.05
+	}
+	check := func(t *testing.T, c *pixeltest.Canvas, want map[pixel.Vec]pixel.RGBA) {
+		t.Helper()
+		for p, col := range want {
+			if got := c.Color(p); !near(got, col) {
+				t.Errorf("pixel %v: got %v, want %v", p, got, col)
+			}
+		}
+	}
+
+	// quadrants of different colors
+	pic := pixel.MakePictureData(pixel.R(0, 0, 2, 2))
+	pic.Pix[pic.Index(pixel.V(0.5, 0.5))] = color.RGBA{255, 0, 0, 255}
+	pic.Pix[pic.Index(pixel.V(1.5, 0.5))] = color.RGBA{0, 255, 0, 255}
+	pic.Pix[pic.Index(pixel.V(0.5, 1.5))] = color.RGBA{0, 0, 255, 255}
+	pic.Pix[pic.Index(pixel.V(1.5, 1.5))] = color.RGBA{255, 255, 255, 255}
+
+	t.Run("LinearGradient", func(t *testing.T) {
+		c := pixeltest.NewCanvas(pixel.R(0, 0, 64, 16))
+		imd := imdraw.New(nil)
+		imd.Fill = imdraw.LinearGradient{
+			Start: pixel.V(0, 0),
+			End:   pixel.V(64, 0),
+			Stops: []imdraw.GradientStop{{0, red}, {0.5, green}, {1, blue}},
+		}
+		imd.Push(pixel.V(0, 0), pixel.V(64, 16))
+		imd.Rectangle(0)
+		imd.Draw(c)
+
+		// the middle stop is exact, even though there are no vertices there
+		check(t, c, map[pixel.Vec]pixel.RGBA{
+			pixel.V(0.5, 8.5):  red,
+			pixel.V(32, 8.5):   green,
+			pixel.V(63.5, 8.5): blue,
+			pixel.V(16, 2.5):   pixel.RGB(0.5, 0.5, 0),
+		})
+	})
+
+	t.Run("RadialGradient", func(t *testing.T) {
+		c := pixeltest.NewCanvas(pixel.R(0, 0, 64, 64))
+		imd := imdraw.New(nil)
+		imd.Color = pixel.RGB(1, 1, 0)
+		imd.Fill = imdraw.RadialGradient{
+			Center: pixel.V(32, 32),
+			Radius: 30,
+			Stops:  []imdraw.GradientStop{{0, pixel.RGB(1, 1, 1)}, {0.5, red}, {1, green}},
+		}
+		imd.Push(pixel.V(32, 32))
+		imd.Circle(30, 0)
+		imd.Draw(c)
+
+		// the colors of the gradient are multiplied by the Color
+		check(t, c, map[pixel.Vec]pixel.RGBA{
+			pixel.V(32, 32):   pixel.RGB(1, 1, 0),
+			pixel.V(47, 32):   red,
+			pixel.V(32, 17):   red,
+			pixel.V(32, 2.5):  pixel.RGB(0, 0.97, 0),
+			pixel.V(39.5, 32): pixel.RGB(1, 0.5, 0),
+		})
+	})
+
+	t.Run("StretchPicture", func(t *testing.T) {
+		c := pixeltest.NewCanvas(pixel.R(0, 0, 64, 64))
+		imd := imdraw.New(pic)
+		imd.Fill = imdraw.StretchPicture(pixel.R(0, 0, 32, 32), pic.Bounds())
+		imd.SetMatrix(pixel.IM.Moved(pixel.V(16, 16)))
+		imd.Push(pixel.V(16, 16))
+		imd.Circle(16, 0)
+		imd.Draw(c)
+
+		check(t, c, map[pixel.Vec]pixel.RGBA{
+			pixel.V(24, 24): red,
+			pixel.V(40, 24): green,
+			pixel.V(24, 40): blue,
+			pixel.V(40, 40): pixel.RGB(1, 1, 1),
+		})
+	})
+
+	t.Run("FitPicture", func(t *testing.T) {
+		c := pixeltest.NewCanvas(pixel.R(0, 0, 64, 32))
+		imd := imdraw.New(pic)
+		imd.Fill = imdraw.FitPicture(pixel.R(0, 0, 64, 32), pic.Bounds())
+		imd.Push(pixel.V(0, 0), pixel.V(64, 32))
+		imd.Rectangle(0)
+		imd.Draw(c)
+
+		check(t, c, map[pixel.Vec]pixel.RGBA{
+			pixel.V(20, 8):  red,
+			pixel.V(44, 8):  green,
+			pixel.V(20, 24): blue,
+			pixel.V(4, 8):   red,
+			pixel.V(60, 24): pixel.RGB(1, 1, 1),
+		})
+	})
+
+	t.Run("TilePicture", func(t *testing.T) {
+		c := pixeltest.NewCanvas(pixel.R(0, 0, 16, 16))
+		imd := imdraw.New(pic)
+		imd.Fill = imdraw.TilePicture(pixel.V(1, 1), pic.Bounds())
+		imd.Push(pixel.V(0, 0), pixel.V(16, 16))
+		imd.Rectangle(0)
+		imd.Draw(c)
+
+		for x := 0.0; x < 16; x++ {
+			for y := 0.0; y < 16; y++ {
+				at := pixel.V(x+0.5, y+0.5)
+				want := pic.Color(pixel.V(math.Mod(x+1, 2)+0.5, math.Mod(y+1, 2)+0.5))
+				if got := c.Color(at); !near(got, want) {
+					t.Fatalf("pixel %v: got %v, want %v", at, got, want)
+				}
+			}
+		}
+	})
+}
+
+func TestIMDraw_AdaptivePrecision(t *testing.T) {
+	count := func(setup func(imd *imdraw.IMDraw)) int {
+		imd := imdraw.New(nil)
+		imd.Precision = imdraw.AdaptivePrecision
+		setup(imd)
+		tri := &pixel.TrianglesData{}
+		imd.Draw(pixel.NewBatch(tri, nil))
+		return tri.Len()
+	}
+	circle := func(radius float64, m pixel.Matrix) func(imd *imdraw.IMDraw) {
+		return func(imd *imdraw.IMDraw) {
+			imd.SetMatrix(m)
+			imd.Push(pixel.ZV)
+			imd.Circle(radius, 0)
+		}
+	}
+
+	// chords of the circle deviate from it by at most the tolerance
+	step := 2 * math.Acos(1-0.25/100)
+	if got, want := count(circle(100, pixel.IM)), 3*int(math.Ceil(2*math.Pi/step)); got != want {
+		t.Errorf("circle of radius 100: got %d vertices, want %d", got, want)
+	}
+	if got, want := count(circle(0.2, pixel.IM)), 3*4; got != want {
+		t.Errorf("circle of radius 0.2: got %d vertices, want %d", got, want)
+	}
+
+	scaled := count(circle(10, pixel.IM.Scaled(pixel.ZV, 10).Rotated(pixel.ZV, 1)))
+	if plain := count(circle(100, pixel.IM)); scaled != plain {
+		t.Errorf("scaled circle: got %d vertices, want %d", scaled, plain)
+	}
+	if coarse := count(func(imd *imdraw.IMDraw) {
+		imd.Tolerance = 2
+		circle(100, pixel.IM)(imd)
+	}); coarse >= scaled {
+		t.Errorf("circle with larger Tolerance: got %d vertices, want less than %d", coarse, scaled)
+	}
+
+	// round end shapes follow the thickness
+	line := func(thickness float64) func(imd *imdraw.IMDraw) {
+		return func(imd *imdraw.IMDraw) {
+			imd.EndShape = imdraw.RoundEndShape
+			imd.Push(pixel.V(0, 0), pixel.V(100, 0))
+			imd.Line(thickness)
+		}
+	}
+	if thin, thick := count(line(2)), count(line(100)); thin >= thick {
+		t.Errorf("round end shapes: got %d vertices for thin and %d for thick line", thin, thick)
+	}
+
+	// fixed precision is not affected
+	if got, want := count(func(imd *imdraw.IMDraw) {
+		imd.Precision = 64
+		circle(1, pixel.IM.Scaled(pixel.ZV, 100))(imd)
+	}), 3*64; got != want {
+		t.Errorf("fixed precision: got %d vertices, want %d", got, want)
+	}
+}
+
+func TestMesh(t *testing.T) {
+	imd := imdraw.New(nil)
+	imd.Color = pixel.RGB(1, 0, 0)
+	imd.Push(pixel.V(0, 0), pixel.V(8, 8))
+	imd.Rectangle(0)
+	mesh := imd.Mesh()
+
+	// the Mesh is a snapshot
+	imd.Clear()
+	imd.Push(pixel.V(0, 0))
+	imd.Circle(4, 0)
+	if got := mesh.Triangles().Len(); got != 6 {
+		t.Fatalf("got %d vertices, want 6", got)
+	}
+	if got, want := mesh.Bounds(), pixel.R(0, 0, 8, 8); got != want {
+		t.Errorf("Bounds: got %v, want %v", got, want)
+	}
+
+	t.Run("Draw", func(t *testing.T) {
+		c := pixeltest.NewCanvas(pixel.R(0, 0, 32, 32))
+		mesh.DrawColorMask(c, pixel.IM.Moved(pixel.V(16, 16)), pixel.Alpha(0.5))
+		if got, want := c.Color(pixel.V(20, 20)), pixel.RGB(1, 0, 0).Mul(pixel.Alpha(0.5)); math.Abs(got.R-want.R) > 0.01 || math.Abs(got.A-want.A) > 0.01 {
+			t.Errorf("pixel inside: got %v, want %v", got, want)
+		}
+		if got := c.Color(pixel.V(4, 4)); got.A != 0 {
+			t.Errorf("pixel outside: got %v", got)
+		}
+
+		// drawing again with a different Matrix updates the cached triangles
+		c.Clear(pixel.Alpha(0))
+		mesh.Draw(c, pixel.IM)
+		if got := c.Color(pixel.V(4, 4)); got != pixel.RGB(1, 0, 0) {
+			t.Errorf("pixel after moving: got %v", got)
+		}
+	})
+
+	t.Run("Merge", func(t *testing.T) {
+		merged, err := imdraw.Merge(mesh, mesh.Transformed(pixel.IM.Moved(pixel.V(16, 0))))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got, want := merged.Bounds(), pixel.R(0, 0, 24, 8); got != want {
+			t.Errorf("Bounds: got %v, want %v", got, want)
+		}
+		c := pixeltest.NewCanvas(pixel.R(0, 0, 32, 32))
+		merged.Draw(c, pixel.IM)
+		for _, p := range []pixel.Vec{pixel.V(4, 4), pixel.V(20, 4)} {
+			if got := c.Color(p); got.A == 0 {
+				t.Errorf("pixel %v not drawn", p)
+			}
+		}
+
+		a := imdraw.New(pixel.MakePictureData(pixel.R(0, 0, 1, 1))).Mesh()
+		b := imdraw.New(pixel.MakePictureData(pixel.R(0, 0, 1, 1))).Mesh()
+		if _, err := imdraw.Merge(a, mesh, b); err == nil {
+			t.Error("merging meshes with different pictures succeeded")
+		}
+		if m, err := imdraw.Merge(mesh, a); err != nil || m.Picture() != a.Picture() {
+			t.Errorf("merging mesh without a picture: got %v, %v", m, err)
+		}
+	})
+
+	t.Run("Encode", func(t *testing.T) {
+		pic := pixel.MakePictureData(pixel.R(0, 0, 2, 2))
+		pic.Pix[1] = color.RGBA{0, 255, 0, 255}
+		withPic := imdraw.NewMesh(mesh.Triangles(), pic)
+
+		for _, m := range []*imdraw.Mesh{mesh, withPic, imdraw.New(nil).Mesh()} {
+			var buf bytes.Buffer
+			if err := m.Encode(&buf); err != nil {
+				t.Fatal(err)
+			}
+			decoded, err := imdraw.DecodeMesh(&buf)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(decoded.Triangles(), m.Triangles()) {
+				t.Errorf("triangles differ after decoding")
+			}
+			if m.Picture() == nil {
+				if decoded.Picture() != nil {
+					t.Errorf("decoded picture: got %v, want nil", decoded.Picture())
+				}
+			} else if !reflect.DeepEqual(decoded.Picture(), m.Picture()) {
+				t.Errorf("picture differs after decoding")
+			}
+		}
+	})
+}
+
 func BenchmarkPush(b *testing.B) {
 	imd := imdraw.New(nil)
 	for i := 0; i < b.N; i++ {
@@ -0,0 +1,170 @@
+package imdraw
+
+import (
+	"encoding/gob"
+	"image/color"
+	"io"
+	"math"
+
+	"github.com/faiface/pixel"
+	"github.com/pkg/errors"
+)
+
+// Mesh is an immutable snapshot of triangles with a Picture, usually taken from an IMDraw using
+// the Mesh method. Unlike IMDraw, a Mesh doesn't need to be redrawn every frame, it's drawn with a
+// Matrix and a color mask, just like a Sprite:
+//
+//   imd.Circle(50, 0) // and many other shapes
+//   mesh := imd.Mesh()
+//   ...
+//   mesh.Draw(win, pixel.IM.Moved(pos))
+//
+// Note, that Mesh caches the results of MakeTriangles and MakePicture from Targets it's drawn to,
+// just like Sprite.
+type Mesh struct {
+	tri    *pixel.TrianglesData
+	bounds pixel.Rect
+
+	d      pixel.Drawer
+	drawn  *pixel.TrianglesData
+	matrix pixel.Matrix
+	mask   pixel.RGBA
+}
+
+// NewMesh creates a Mesh from a copy of the triangles, drawn with the Picture. The Picture may be
+// nil.
+func NewMesh(tri *pixel.TrianglesData, pic pixel.Picture) *Mesh {
+	m := &Mesh{tri: tri.Copy().(*pixel.TrianglesData)}
+	m.bounds = trianglesBounds(m.tri)
+	m.drawn = m.tri.Copy().(*pixel.TrianglesData)
+	m.d = pixel.Drawer{Triangles: m.drawn, Picture: pic}
+	m.matrix = pixel.IM
+	m.mask = pixel.Alpha(1)
+	return m
+}
+
+// Mesh returns a Mesh of all shapes currently drawn in the IMDraw, with it's Picture. Drawing more
+// shapes or clearing the IMDraw doesn't affect the Mesh.
+func (imd *IMDraw) Mesh() *Mesh {
+	return NewMesh(imd.tri, imd.pic)
+}
+
+// Triangles returns a copy of the triangles of the Mesh.
+func (m *Mesh) Triangles() *pixel.TrianglesData {
+	return m.tri.Copy().(*pixel.TrianglesData)
+}
+
+// Picture returns the Picture the Mesh is drawn with.
+func (m *Mesh) Picture() pixel.Picture {
+	return m.d.Picture
+}
+
+// Bounds returns the smallest Rect containing all triangles of the Mesh, before applying the
+// Matrix.
+func (m *Mesh) Bounds() pixel.Rect {
+	return m.bounds
+}
+
+// Transformed returns a new Mesh with all triangles projected by the Matrix. This is useful for
+// merging copies of a Mesh at different places.
+func (m *Mesh) Transformed(matrix pixel.Matrix) *Mesh {
+	tri := m.Triangles()
+	for i := range *tri {
+		(*tri)[i].Position = matrix.Project((*tri)[i].Position)
+	}
+	return NewMesh(tri, m.d.Picture)
+}
+
+// Merge returns a new Mesh containing the triangles of all Meshes in order. All Meshes must have
+// the same Picture, or no Picture.
+func Merge(meshes ...*Mesh) (*Mesh, error) {
+	tri := &pixel.TrianglesData{}
+	var pic pixel.Picture
+	for _, m := range meshes {
+		if m.d.Picture != nil {
+			if pic != nil && pic != m.d.Picture {
+				return nil, errors.New("can't merge meshes with different pictures")
+			}
+			pic = m.d.Picture
+		}
+		*tri = append(*tri, *m.tri...)
+	}
+	return NewMesh(tri, pic), nil
+}
+
+// Draw draws the Mesh onto the provided Target, transformed by the Matrix.
+//
+// This method is equivalent to calling DrawColorMask with nil color mask.
+func (m *Mesh) Draw(t pixel.Target, matrix pixel.Matrix) {
+	m.DrawColorMask(t, matrix, nil)
+}
+
+// DrawColorMask draws the Mesh onto the provided Target. The Mesh will be transformed by the given
+// Matrix and all of it's color will be multiplied by the given mask.
+//
+// If the mask is nil, a fully opaque white mask will be used, which causes no effect.
+func (m *Mesh) DrawColorMask(t pixel.Target, matrix pixel.Matrix, mask color.Color) {
+	if mask == nil {
+		mask = pixel.Alpha(1)
+	}
+	rgba := pixel.ToRGBA(mask)
+
+	if matrix != m.matrix || rgba != m.mask {
+		m.matrix, m.mask = matrix, rgba
+		for i := range *m.drawn {
+			(*m.drawn)[i].Position = matrix.Project((*m.tri)[i].Position)
+			(*m.drawn)[i].Color = rgba.Mul((*m.tri)[i].Color)
+		}
+		m.d.Dirty()
+	}
+
+	m.d.Draw(t)
+}
+
+// gobMesh is the serialized form of a Mesh. The Picture is converted to PictureData.
+type gobMesh struct {
+	Triangles *pixel.TrianglesData
+	Picture   *pixel.PictureData
+}
+
+// Encode serializes the Mesh into the Writer using encoding/gob.
+//
+// The Picture is converted to PictureData, which may be lossy (see pixel.PictureDataFromPicture).
+func (m *Mesh) Encode(w io.Writer) error {
+	gm := gobMesh{Triangles: m.tri}
+	if m.d.Picture != nil {
+		gm.Picture = pixel.PictureDataFromPicture(m.d.Picture)
+	}
+	return errors.Wrap(gob.NewEncoder(w).Encode(&gm), "failed to encode mesh")
+}
+
+// DecodeMesh decodes a Mesh previously serialized using Encode.
+func DecodeMesh(r io.Reader) (*Mesh, error) {
+	var gm gobMesh
+	if err := gob.NewDecoder(r).Decode(&gm); err != nil {
+		return nil, errors.Wrap(err, "failed to decode mesh")
+	}
+	// gob doesn't transmit empty slices
+	if gm.Triangles == nil {
+		gm.Triangles = &pixel.TrianglesData{}
+	}
+	var pic pixel.Picture
+	if gm.Picture != nil {
+		pic = gm.Picture
+	}
+	return NewMesh(gm.Triangles, pic), nil
+}
+
+// trianglesBounds returns the smallest Rect containing all vertices.
+func trianglesBounds(tri *pixel.TrianglesData) pixel.Rect {
+	if tri.Len() == 0 {
+		return pixel.ZR
+	}
+	min := pixel.V(math.Inf(+1), math.Inf(+1))
+	max := pixel.V(math.Inf(-1), math.Inf(-1))
+	for _, v := range *tri {
+		min = pixel.V(math.Min(min.X, v.Position.X), math.Min(min.Y, v.Position.Y))
+		max = pixel.V(math.Max(max.X, v.Position.X), math.Max(max.Y, v.Position.Y))
+	}
+	return pixel.Rect{Min: min, Max: max}
+}
@@ -0,0 +1,468 @@
+package imdraw
+
+import (
+	"math"
+	"sort"
+
+	"github.com/faiface/pixel"
+)
+
+// FillRule specifies which areas of a Path are inside of it and get filled.
+type FillRule int
+
+const (
+	// NonZeroFillRule fills all areas, which the subpaths wind around a non-zero number of times.
+	// Holes need to wind in the opposite direction than the surrounding subpath.
+	NonZeroFillRule FillRule = iota
+
+	// EvenOddFillRule fills all areas enclosed by an odd number of subpaths. Holes can wind in
+	// any direction.
+	EvenOddFillRule
+)
+
+func (fr FillRule) filled(winding int) bool {
+	if fr == EvenOddFillRule {
+		return winding%2 != 0
+	}
+	return winding != 0
+}
+
+// Path is a shape made of subpaths of lines, quadratic and cubic Bézier curves and elliptical
+// arcs, similar to paths in HTML Canvas. The zero value is an empty Path.
+//
+//   var p imdraw.Path
+//   p.MoveTo(pixel.V(0, 0))
+//   p.LineTo(pixel.V(100, 0))
+//   p.QuadTo(pixel.V(100, 100), pixel.V(0, 100))
+//   p.Close()
+//
+// Path only describes the geometry, use IMDraw.Path to fill or stroke it.
+type Path struct {
+	subpaths []subpath
+	current  pixel.Vec
+}
+
+type segmentKind int
+
+const (
+	lineSegment segmentKind = iota
+	quadSegment
+	cubicSegment
+	arcSegment
+)
+
+// segment is a single segment of a subpath. For lines and curves, pts contains the control points
+// and the end point. For arcs, pts contains the center and the radius.
+type segment struct {
+	kind      segmentKind
+	pts       [3]pixel.Vec
+	rotation  float64
+	low, high float64
+}
+
+type subpath struct {
+	start    pixel.Vec
+	segments []segment
+	closed   bool
+}
+
+// Clear removes all subpaths from the Path.
+func (p *Path) Clear() {
+	p.subpaths = p.subpaths[:0]
+	p.current = pixel.ZV
+}
+
+// Current returns the current point of the Path, which is where the next segment starts.
+func (p *Path) Current() pixel.Vec {
+	return p.current
+}
+
+// MoveTo starts a new subpath at the point.
+func (p *Path) MoveTo(pt pixel.Vec) {
+	p.subpaths = append(p.subpaths, subpath{start: pt})
+	p.current = pt
+}
+
+// LineTo adds a straight line from the current point to the point.
+func (p *Path) LineTo(pt pixel.Vec) {
+	p.add(pt, segment{kind: lineSegment, pts: [3]pixel.Vec{pt}})
+	p.current = pt
+}
+
+// QuadTo adds a quadratic Bézier curve from the current point to the point, with one control point.
+func (p *Path) QuadTo(ctrl, pt pixel.Vec) {
+	p.add(ctrl, segment{kind: quadSegment, pts: [3]pixel.Vec{ctrl, pt}})
+	p.current = pt
+}
+
+// CubicTo adds a cubic Bézier curve from the current point to the point, with two control points.
+func (p *Path) CubicTo(ctrl1, ctrl2, pt pixel.Vec) {
+	p.add(ctrl1, segment{kind: cubicSegment, pts: [3]pixel.Vec{ctrl1, ctrl2, pt}})
+	p.current = pt
+}
+
+// Arc adds a circle arc around the center, which starts at the low angle and continues to the
+// high angle, just like in IMDraw.CircleArc. If the current point is not the start of the arc, a
+// straight line is added between them.
+func (p *Path) Arc(center pixel.Vec, radius, low, high float64) {
+	p.EllipseArc(center, pixel.V(radius, radius), 0, low, high)
+}
+
+// EllipseArc adds an ellipse arc around the center, with the specified radius in each axis and the
+// axes rotated by the rotation angle. Otherwise, it's the same as Arc.
+func (p *Path) EllipseArc(center, radius pixel.Vec, rotation, low, high float64) {
+	start := arcPoint(center, radius, rotation, low)
+	p.add(start, segment{kind: lineSegment, pts: [3]pixel.Vec{start}})
+	p.add(start, segment{
+		kind:     arcSegment,
+		pts:      [3]pixel.Vec{center, radius},
+		rotation: rotation,
+		low:      low,
+		high:     high,
+	})
+	p.current = arcPoint(center, radius, rotation, high)
+}
+
+// ArcTo adds a circle arc of the radius, which is tangent to the line from the current point to p1
+// and to the line from p1 to p2, connected to the current point by a straight line. This rounds
+// the corner at p1, just like arcTo in HTML Canvas. The arc ends on the line to p2, which isn't
+// added.
+//
+// If the points lie on a line or the radius is not positive, a straight line to p1 is added
+// instead.
+func (p *Path) ArcTo(p1, p2 pixel.Vec, radius float64) {
+	if len(p.subpaths) == 0 {
+		p.MoveTo(p1)
+	}
+	u, v := p1.To(p.current), p1.To(p2)
+	if radius <= 0 || u.Len() == 0 || v.Len() == 0 || u.Cross(v) == 0 {
+		p.LineTo(p1)
+		return
+	}
+	u, v = u.Unit(), v.Unit()
+
+	// the center lies on the bisector of the corner, the arc touches both lines
+	half := math.Acos(pixel.Clamp(u.Dot(v), -1, 1)) / 2
+	center := p1.Add(u.Add(v).Unit().Scaled(radius / math.Sin(half)))
+	start := p1.Add(u.Scaled(radius / math.Tan(half)))
+	end := p1.Add(v.Scaled(radius / math.Tan(half)))
+
+	// the arc is always shorter than a half circle
+	low := center.To(start).Angle()
+	delta := center.To(end).Angle() - low
+	switch {
+	case delta > math.Pi:
+		delta -= 2 * math.Pi
+	case delta < -math.Pi:
+		delta += 2 * math.Pi
+	}
+	p.Arc(center, radius, low, low+delta)
+}
+
+// Close closes the current subpath with a straight line back to it's start. The next segment will
+// start a new subpath at the same point.
+func (p *Path) Close() {
+	if len(p.subpaths) == 0 || p.subpaths[len(p.subpaths)-1].closed {
+		return
+	}
+	sp := &p.subpaths[len(p.subpaths)-1]
+	sp.closed = true
+	p.current = sp.start
+}
+
+// add adds a segment to the current subpath. If there's no subpath, a new one is started at the
+// first point. If the last subpath is closed, a new one is started at the current point.
+func (p *Path) add(first pixel.Vec, seg segment) {
+	switch {
+	case len(p.subpaths) == 0:
+		p.MoveTo(first)
+	case p.subpaths[len(p.subpaths)-1].closed:
+		p.MoveTo(p.current)
+	}
+	sp := &p.subpaths[len(p.subpaths)-1]
+	sp.segments = append(sp.segments, seg)
+}
+
+func arcPoint(center, radius pixel.Vec, rotation, angle float64) pixel.Vec {
+	sin, cos := math.Sincos(angle)
+	return center.Add(pixel.V(radius.X*cos, radius.Y*sin).Rotated(rotation))
+}
+
+// flatPath is a flattened subpath.
+type flatPath struct {
+	pts    []pixel.Vec
+	closed bool
+}
+
+// flatten approximates all subpaths with polylines, which deviate from the curves by at most the
+// tolerance. Consecutive duplicate points are removed.
+func (p *Path) flatten(tolerance float64) []flatPath {
+	if tolerance <= 0 {
+		tolerance = 0.25
+	}
+
+	lines := make([]flatPath, 0, len(p.subpaths))
+	for _, sp := range p.subpaths {
+		pts := []pixel.Vec{sp.start}
+		for _, seg := range sp.segments {
+			last := pts[len(pts)-1]
+			switch seg.kind {
+			case lineSegment:
+				pts = append(pts, seg.pts[0])
+			case quadSegment:
+				pts = flattenQuad(pts, last, seg.pts[0], seg.pts[1], tolerance)
+			case cubicSegment:
+				pts = flattenCubic(pts, last, seg.pts[0], seg.pts[1], seg.pts[2], tolerance)
+			case arcSegment:
+				pts = flattenArc(pts, seg, tolerance)
+			}
+		}
+
+		// remove duplicates, which would make line normals undefined
+		dedup := pts[:1]
+		for _, pt := range pts[1:] {
+			if pt != dedup[len(dedup)-1] {
+				dedup = append(dedup, pt)
+			}
+		}
+		if sp.closed && len(dedup) > 1 && dedup[0] == dedup[len(dedup)-1] {
+			dedup = dedup[:len(dedup)-1]
+		}
+		lines = append(lines, flatPath{pts: dedup, closed: sp.closed})
+	}
+	return lines
+}
+
+// The number of subdivisions of Bézier curves is estimated from their second differences, which
+// bound the distance between the curve and the subdivided polyline.
+
+func flattenQuad(pts []pixel.Vec, p0, p1, p2 pixel.Vec, tolerance float64) []pixel.Vec {
+	dd := p0.Sub(p1.Scaled(2)).Add(p2).Len()
+	n := math.Max(1, math.Ceil(math.Sqrt(dd/(4*tolerance))))
+	for i := 1.0; i <= n; i++ {
+		t := i / n
+		pts = append(pts, p0.Scaled((1-t)*(1-t)).Add(p1.Scaled(2*t*(1-t))).Add(p2.Scaled(t*t)))
+	}
+	return pts
+}
+
+func flattenCubic(pts []pixel.Vec, p0, p1, p2, p3 pixel.Vec, tolerance float64) []pixel.Vec {
+	dd := math.Max(
+		p0.Sub(p1.Scaled(2)).Add(p2).Len(),
+		p1.Sub(p2.Scaled(2)).Add(p3).Len(),
+	)
+	n := math.Max(1, math.Ceil(math.Sqrt(3*dd/(4*tolerance))))
+	for i := 1.0; i <= n; i++ {
+		t := i / n
+		u := 1 - t
+		pts = append(pts, p0.Scaled(u*u*u).
+			Add(p1.Scaled(3*u*u*t)).
+			Add(p2.Scaled(3*u*t*t)).
+			Add(p3.Scaled(t*t*t)))
+	}
+	return pts
+}
+
+func flattenArc(pts []pixel.Vec, seg segment, tolerance float64) []pixel.Vec {
+	center, radius := seg.pts[0], seg.pts[1]
+	r := math.Max(math.Abs(radius.X), math.Abs(radius.Y))
+	if r == 0 {
+		return append(pts, center)
+	}
+	// maximal angle of a chord, which is at most tolerance away from the arc
+	step := 2 * math.Acos(pixel.Clamp(1-tolerance/r, -1, 1))
+	n := math.Max(1, math.Ceil(math.Abs(seg.high-seg.low)/step))
+	for i := 1.0; i <= n; i++ {
+		angle := seg.low + (seg.high-seg.low)*i/n
+		pts = append(pts, arcPoint(center, radius, seg.rotation, angle))
+	}
+	return pts
+}
+
+// Path draws the Path. If the thickness is 0, the Path is filled according to the FillRule, with
+// all subpaths treated as closed. Otherwise, each subpath is drawn as a line of the specified
+// thickness, or as a polygon outline if it's closed.
+//
+// The whole Path is drawn with the current properties of the IMDraw (as if all of it's points were
+// Pushed at once) and curves are flattened so that they deviate from the Path by at most the
+// Tolerance on the Target. Pushed points are not affected.
+func (imd *IMDraw) Path(p *Path, thickness float64) {
+	pt := imd.pointOpts()
+	lines := p.flatten(imd.tolerance(pt))
+	f := fringe{off: imd.tri.Len(), width: pt.feather}
+	if thickness == 0 {
+		imd.fillPath(lines, pt)
+	} else {
+		imd.strokePath(lines, pt, thickness)
+	}
+	imd.endFringe(f)
+}
+
+func (imd *IMDraw) strokePath(lines []flatPath, pt point, thickness float64) {
+	pushed := imd.getAndClearPoints()
+
+	// no joints would leave gaps between the segments of curves
+	if pt.linejoin == EndShapeLineJoin {
+		pt.linejoin = MiterLineJoin
+	}
+
+	for _, l := range lines {
+		if len(l.pts) < 2 {
+			continue
+		}
+		for _, pos := range l.pts {
+			imd.pushPt(pos, pt)
+		}
+		imd.polyline(thickness, l.closed && len(l.pts) > 2)
+	}
+
+	imd.pool = append(imd.pool, imd.points)
+	imd.points = pushed
+}
+
+func (imd *IMDraw) fillPath(lines []flatPath, pt point) {
+	polygons := make([][]pixel.Vec, len(lines))
+	for i := range lines {
+		polygons[i] = lines[i].pts
+	}
+	tris := tessellate(polygons, pt.fillRule)
+
+	off := imd.tri.Len()
+	imd.tri.SetLen(imd.tri.Len() + len(tris))
+
+	for i, pos := range tris {
+		tri := &(*imd.tri)[off+i]
+		tri.Position = pos
+		tri.Color = pt.col
+		tri.Picture = pt.pic
+		tri.Intensity = pt.in
+	}
+
+	imd.applyFill(off, pt.fill)
+	imd.applyMatrixAndMask(off)
+	imd.batch.Dirty()
+}
+
+// pathEdge is a non-horizontal edge of a polygon, with lo being the lower end. The winding is +1 if
+// the edge goes up and -1 if it goes down.
+type pathEdge struct {
+	lo, hi  pixel.Vec
+	winding int
+}
+
+func (e pathEdge) x(y float64) float64 {
+	return e.lo.X + (e.hi.X-e.lo.X)*(y-e.lo.Y)/(e.hi.Y-e.lo.Y)
+}
+
+// crossing returns the y coordinate at which the two edges cross, if they do.
+func (e pathEdge) crossing(f pathEdge) (float64, bool) {
+	y0, y1 := math.Max(e.lo.Y, f.lo.Y), math.Min(e.hi.Y, f.hi.Y)
+	if y0 >= y1 {
+		return 0, false
+	}
+	d0, d1 := e.x(y0)-f.x(y0), e.x(y1)-f.x(y1)
+	if d0*d1 >= 0 {
+		return 0, false
+	}
+	return y0 + (y1-y0)*d0/(d0-d1), true
+}
+
+// tessellate triangulates the area of the polygons according to the fill rule. The polygons may
+// intersect themselves and each other.
+//
+// The area is split into horizontal strips at every vertex and every crossing of the edges. No
+// edges cross inside of a strip, so they can be sorted from left to right and the filled spans
+// between them are trapezoids.
+func tessellate(polygons [][]pixel.Vec, rule FillRule) []pixel.Vec {
+	var (
+		edges []pathEdge
+		ys    []float64
+	)
+	for _, poly := range polygons {
+		for i := range poly {
+			a, b := poly[i], poly[(i+1)%len(poly)]
+			ys = append(ys, a.Y)
+			switch {
+			case a.Y < b.Y:
+				edges = append(edges, pathEdge{lo: a, hi: b, winding: 1})
+			case a.Y > b.Y:
+				edges = append(edges, pathEdge{lo: b, hi: a, winding: -1})
+			}
+		}
+	}
+	if len(edges) < 2 {
+		return nil
+	}
+
+	sort.Slice(edges, func(i, j int) bool { return edges[i].lo.Y < edges[j].lo.Y })
+	for i := range edges {
+		for j := i + 1; j < len(edges) && edges[j].lo.Y < edges[i].hi.Y; j++ {
+			if y, ok := edges[i].crossing(edges[j]); ok {
+				ys = append(ys, y)
+			}
+		}
+	}
+	sort.Float64s(ys)
+
+	type span struct {
+		x0, x1  float64
+		winding int
+	}
+
+	var (
+		tris   []pixel.Vec
+		active []pathEdge
+		spans  []span
+		next   int
+	)
+	for i := 0; i+1 < len(ys); i++ {
+		y0, y1 := ys[i], ys[i+1]
+		if y0 == y1 {
+			continue
+		}
+
+		// update the edges spanning the strip
+		kept := active[:0]
+		for _, e := range active {
+			if e.hi.Y > y0 {
+				kept = append(kept, e)
+			}
+		}
+		active = kept
+		for next < len(edges) && edges[next].lo.Y <= y0 {
+			if edges[next].hi.Y > y0 {
+				active = append(active, edges[next])
+			}
+			next++
+		}
+
+		spans = spans[:0]
+		for _, e := range active {
+			spans = append(spans, span{x0: e.x(y0), x1: e.x(y1), winding: e.winding})
+		}
+		sort.Slice(spans, func(i, j int) bool {
+			return spans[i].x0+spans[i].x1 < spans[j].x0+spans[j].x1
+		})
+
+		winding, left := 0, span{}
+		for _, s := range spans {
+			inside := rule.filled(winding)
+			winding += s.winding
+			switch {
+			case !inside && rule.filled(winding):
+				left = s
+			case inside && !rule.filled(winding):
+				a, b := pixel.V(left.x0, y0), pixel.V(s.x0, y0)
+				c, d := pixel.V(s.x1, y1), pixel.V(left.x1, y1)
+				if a != b {
+					tris = append(tris, a, b, c)
+				}
+				if c != d {
+					tris = append(tris, a, c, d)
+				}
+			}
+		}
+	}
+
+	return tris
+}
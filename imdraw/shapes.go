@@ -0,0 +1,307 @@
+package imdraw
+
+import (
+	"math"
+
+	"github.com/faiface/pixel"
+)
+
+// RoundedRectangle draws a rectangle with rounded corners between each two subsequent Pushed
+// points, just like Rectangle. The radius of the corners is limited to half of the shorter side of
+// the rectangle and the corners are drawn with the Precision of the first point, each of them as a
+// quarter of a circle.
+//
+// If the thickness is 0, rectangles will be filled, otherwise will be outlined with the given
+// thickness.
+func (imd *IMDraw) RoundedRectangle(radius, thickness float64) {
+	imd.RoundedRectangleCorners(radius, radius, radius, radius, thickness)
+}
+
+// RoundedRectangleCorners is like RoundedRectangle, but with a different radius of each corner.
+func (imd *IMDraw) RoundedRectangleCorners(bottomLeft, bottomRight, topRight, topLeft, thickness float64) {
+	f := imd.beginFringe()
+	points := imd.getAndClearPoints()
+
+	for i := 0; i+1 < len(points); i++ {
+		a, b := points[i], points[i+1]
+		r := pixel.R(a.pos.X, a.pos.Y, b.pos.X, b.pos.Y).Norm()
+		max := math.Min(r.W(), r.H()) / 2
+		corner := func(radius float64) pixel.Vec {
+			radius = pixel.Clamp(radius, 0, max)
+			return pixel.V(radius, radius)
+		}
+
+		var outline []pixel.Vec
+		br, tr, tl, bl := corner(bottomRight), corner(topRight), corner(topLeft), corner(bottomLeft)
+		quarter := func(radius pixel.Vec) float64 { return imd.segments(a, radius, math.Pi/2) }
+		outline = arcPoints(outline, pixel.V(r.Max.X-br.X, r.Min.Y+br.Y), br, -math.Pi/2, 0, quarter(br))
+		outline = arcPoints(outline, r.Max.Sub(tr), tr, 0, math.Pi/2, quarter(tr))
+		outline = arcPoints(outline, pixel.V(r.Min.X+tl.X, r.Max.Y-tl.Y), tl, math.Pi/2, math.Pi, quarter(tl))
+		outline = arcPoints(outline, r.Min.Add(bl), bl, math.Pi, 3*math.Pi/2, quarter(bl))
+
+		imd.closedShape(a, r.Center(), outline, thickness)
+	}
+
+	imd.restorePoints(points)
+	imd.endFringe(f)
+}
+
+// RegularPolygon draws a regular polygon with the specified number of sides around each Pushed
+// point. The radius is the distance of the vertices from the point and the angle is the direction
+// of the first vertex, so an angle of math.Pi/2 makes the polygon point up.
+//
+// If the thickness is 0, the polygon will be filled, otherwise will be outlined with the given
+// thickness.
+func (imd *IMDraw) RegularPolygon(sides int, radius, angle, thickness float64) {
+	f := imd.beginFringe()
+	points := imd.getAndClearPoints()
+
+	if sides >= 3 {
+		for _, pt := range points {
+			outline := make([]pixel.Vec, sides)
+			for i := range outline {
+				a := angle + 2*math.Pi*float64(i)/float64(sides)
+				outline[i] = pt.pos.Add(pixel.V(radius, 0).Rotated(a))
+			}
+			imd.closedShape(pt, pt.pos, outline, thickness)
+		}
+	}
+
+	imd.restorePoints(points)
+	imd.endFringe(f)
+}
+
+// Star draws a star with the specified number of points around each Pushed point. The points of
+// the star are at the outer radius and the corners between them are at the inner radius. The
+// angle is the direction of the first point, just like in RegularPolygon.
+//
+// If the thickness is 0, the star will be filled, otherwise will be outlined with the given
+// thickness.
+func (imd *IMDraw) Star(points int, outerRadius, innerRadius, angle, thickness float64) {
+	f := imd.beginFringe()
+	pts := imd.getAndClearPoints()
+
+	if points >= 2 {
+		for _, pt := range pts {
+			outline := make([]pixel.Vec, 2*points)
+			for i := range outline {
+				radius := outerRadius
+				if i%2 == 1 {
+					radius = innerRadius
+				}
+				a := angle + math.Pi*float64(i)/float64(points)
+				outline[i] = pt.pos.Add(pixel.V(radius, 0).Rotated(a))
+			}
+			imd.closedShape(pt, pt.pos, outline, thickness)
+		}
+	}
+
+	imd.restorePoints(pts)
+	imd.endFringe(f)
+}
+
+// Ring draws a ring (a circle with a hole) between the inner and the outer radius around each
+// Pushed point.
+//
+// If the thickness is 0, the ring will be filled, otherwise both of it's circles will be outlined
+// with the given thickness.
+func (imd *IMDraw) Ring(innerRadius, outerRadius, thickness float64) {
+	f := imd.beginFringe()
+	points := imd.getAndClearPoints()
+
+	for _, pt := range points {
+		if thickness != 0 {
+			for _, radius := range [...]float64{innerRadius, outerRadius} {
+				imd.pushPt(pt.pos, pt)
+				imd.outlineEllipseArc(pixel.V(radius, radius), 0, 2*math.Pi, thickness, false)
+			}
+			continue
+		}
+
+		// both circles need the same number of segments, the larger one decides
+		r := pixel.V(math.Max(innerRadius, outerRadius), 0)
+		num := imd.segments(pt, r, 2*math.Pi)
+		inner := arcPoints(nil, pt.pos, pixel.V(innerRadius, innerRadius), 0, 2*math.Pi, num)
+		outer := arcPoints(nil, pt.pos, pixel.V(outerRadius, outerRadius), 0, 2*math.Pi, num)
+
+		off := imd.tri.Len()
+		imd.tri.SetLen(imd.tri.Len() + 6*(len(inner)-1))
+
+		for i, j := 0, off; i+1 < len(inner); i, j = i+1, j+6 {
+			for k, pos := range [...]pixel.Vec{inner[i], outer[i], outer[i+1], inner[i], outer[i+1], inner[i+1]} {
+				tri := &(*imd.tri)[j+k]
+				tri.Position = pos
+				tri.Color = pt.col
+				tri.Picture = pt.pic
+				tri.Intensity = pt.in
+			}
+		}
+
+		imd.applyFill(off, pt.fill)
+		imd.applyMatrixAndMask(off)
+		imd.batch.Dirty()
+	}
+
+	imd.restorePoints(points)
+	imd.endFringe(f)
+}
+
+// Pie draws a pie sector (a slice of a circle) of the specified radius around each Pushed point.
+// The sector starts at the low angle and continues to the high angle, just like in CircleArc.
+//
+// If the thickness is 0, the sector will be filled, which is the same as a filled CircleArc.
+// Otherwise, the whole sector, including the two radii, will be outlined with the given thickness.
+func (imd *IMDraw) Pie(radius, low, high, thickness float64) {
+	if thickness == 0 {
+		imd.CircleArc(radius, low, high, 0)
+		return
+	}
+
+	f := imd.beginFringe()
+	points := imd.getAndClearPoints()
+
+	for _, pt := range points {
+		r := pixel.V(radius, radius)
+		outline := arcPoints([]pixel.Vec{pt.pos}, pt.pos, r, low, high, imd.segments(pt, r, high-low))
+		imd.closedShape(pt, pt.pos, outline, thickness)
+	}
+
+	imd.restorePoints(points)
+	imd.endFringe(f)
+}
+
+// Capsule draws a capsule (a rectangle with semicircular ends) of the specified radius between
+// each two subsequent Pushed points, which are the centers of the semicircles.
+//
+// If the thickness is 0, capsules will be filled, otherwise will be outlined with the given
+// thickness.
+func (imd *IMDraw) Capsule(radius, thickness float64) {
+	f := imd.beginFringe()
+	points := imd.getAndClearPoints()
+
+	for i := 0; i+1 < len(points); i++ {
+		a, b := points[i], points[i+1]
+		angle := a.pos.To(b.pos).Angle()
+		r := pixel.V(radius, radius)
+
+		var outline []pixel.Vec
+		num := imd.segments(a, r, math.Pi)
+		outline = arcPoints(outline, b.pos, r, angle-math.Pi/2, angle+math.Pi/2, num)
+		outline = arcPoints(outline, a.pos, r, angle+math.Pi/2, angle+3*math.Pi/2, num)
+
+		imd.closedShape(a, pixel.Lerp(a.pos, b.pos, 0.5), outline, thickness)
+	}
+
+	imd.restorePoints(points)
+	imd.endFringe(f)
+}
+
+// ArrowHead is the shape of a head of an arrow drawn by Arrow. The zero value means no head.
+type ArrowHead struct {
+	// Length is the length of the head along the line and Width is it's width across the line.
+	Length, Width float64
+
+	// Inset moves the middle of the back of the head towards the tip, making a barbed head. A
+	// negative Inset moves it away from the tip, making a diamond.
+	Inset float64
+}
+
+// Arrow draws a line of the specified thickness between the Pushed points, just like Line, with
+// filled arrow heads at it's start and end. The line is shortened so that it doesn't stick out of
+// the heads.
+func (imd *IMDraw) Arrow(start, end ArrowHead, thickness float64) {
+	f := imd.beginFringe()
+	points := imd.getAndClearPoints()
+
+	if len(points) < 2 {
+		imd.restorePoints(points)
+		return
+	}
+
+	shaft := append([]point(nil), points...)
+	first, last := 0, len(points)-1
+	shaft[first].pos = imd.arrowHead(points[first], points[first+1], start)
+	shaft[last].pos = imd.arrowHead(points[last], points[last-1], end)
+
+	for _, p := range shaft {
+		imd.pushPt(p.pos, p)
+	}
+	imd.polyline(thickness, false)
+
+	imd.restorePoints(points)
+	imd.endFringe(f)
+}
+
+// arrowHead draws an arrow head with the tip at the point, pointing away from the previous point,
+// and returns where the line should end.
+func (imd *IMDraw) arrowHead(tip, prev point, head ArrowHead) pixel.Vec {
+	if head.Length <= 0 || head.Width <= 0 || tip.pos == prev.pos {
+		return tip.pos
+	}
+	back := prev.pos.To(tip.pos).Unit()
+	side := back.Normal().Scaled(head.Width / 2)
+	base := tip.pos.Sub(back.Scaled(head.Length))
+	middle := tip.pos.Sub(back.Scaled(head.Length - head.Inset))
+
+	imd.pushPt(tip.pos, tip)
+	imd.pushPt(base.Add(side), tip)
+	imd.pushPt(middle, tip)
+	imd.pushPt(base.Sub(side), tip)
+	imd.fillPolygon()
+
+	// the line ends at the back of the head, but not beyond the previous point
+	length := math.Min(head.Length-math.Max(head.Inset, 0), prev.pos.To(tip.pos).Len())
+	return tip.pos.Sub(back.Scaled(length))
+}
+
+// arcPoints appends the points of an ellipse arc split into num segments around the center,
+// including both of it's ends. An arc with a zero radius is a single point.
+func arcPoints(pts []pixel.Vec, center, radius pixel.Vec, low, high, num float64) []pixel.Vec {
+	if radius == pixel.ZV {
+		return append(pts, center)
+	}
+	num = math.Max(1, num)
+	delta := (high - low) / num
+	for i := 0.0; i <= num; i++ {
+		sin, cos := math.Sincos(low + i*delta)
+		pts = append(pts, center.Add(pixel.V(radius.X*cos, radius.Y*sin)))
+	}
+	return pts
+}
+
+// closedShape fills or outlines a shape given by it's outline with the properties of the point.
+// The shape is filled as a fan around the center, so it must be star-shaped around it.
+func (imd *IMDraw) closedShape(pt point, center pixel.Vec, outline []pixel.Vec, thickness float64) {
+	// duplicate points would make line normals undefined
+	dedup := outline[:0]
+	for _, pos := range outline {
+		if len(dedup) == 0 || pos != dedup[len(dedup)-1] {
+			dedup = append(dedup, pos)
+		}
+	}
+	for len(dedup) > 1 && dedup[0] == dedup[len(dedup)-1] {
+		dedup = dedup[:len(dedup)-1]
+	}
+	if len(dedup) < 2 {
+		return
+	}
+
+	if thickness == 0 {
+		imd.pushPt(center, pt)
+		for _, pos := range dedup {
+			imd.pushPt(pos, pt)
+		}
+		imd.pushPt(dedup[0], pt)
+		imd.fillPolygon()
+		return
+	}
+
+	// no joints would leave gaps between the segments of curves
+	if pt.linejoin == EndShapeLineJoin {
+		pt.linejoin = MiterLineJoin
+	}
+	for _, pos := range dedup {
+		imd.pushPt(pos, pt)
+	}
+	imd.polyline(thickness, true)
+}
@@ -131,3 +131,19 @@ type PictureColor interface {
 	Picture
 	Color(at Vec) RGBA
 }
+
+// DynamicPicture is a PictureColor, whose content changes over time, such as a cache of glyphs.
+//
+// Targets, which copy Pictures in MakePicture, check the Version of a DynamicPicture before drawing
+// with it and update the Changed part of their copy. If the Bounds of the Picture change, the whole
+// copy is made again.
+type DynamicPicture interface {
+	PictureColor
+
+	// Version returns a number, which increases whenever the content of the Picture changes.
+	Version() uint64
+
+	// Changed returns a rectangle containing all changes since the version. If the changes are
+	// not known anymore, the whole Bounds are returned.
+	Changed(since uint64) Rect
+}
@@ -0,0 +1,320 @@
+package pixel
+
+import (
+	"fmt"
+	"image/color"
+	"sort"
+)
+
+// LayerBatch is a Target similar to Batch, which sorts the drawn objects by a layer and a depth
+// key before drawing them onto another Target. This allows for drawing objects in an arbitrary
+// order, for example sorted by their Y coordinate in isometric and top-down games.
+//
+// To put an object into a LayerBatch, set the layer and depth and draw it onto it:
+//   batch.SetLayer(1)
+//   batch.SetDepth(-pos.Y)
+//   object.Draw(batch)
+//
+// Layers are drawn in ascending order. Within a layer, objects are drawn in ascending order of
+// their depth, so objects with a greater depth appear on top. Objects with the same depth are
+// drawn in the order they were drawn onto the LayerBatch (the sorting is stable).
+//
+// Each layer has it's own Matrix and color mask, which are applied to all of it's objects when
+// the LayerBatch is drawn. A layer can be marked static with SetLayerStatic. Static layers are not
+// affected by Clear and their sorted geometry is reused across frames until they change.
+type LayerBatch struct {
+	pic    Picture
+	layers []*batchLayer
+
+	layer int
+	depth float64
+	mat   Matrix
+	col   RGBA
+}
+
+var _ BasicTarget = (*LayerBatch)(nil)
+
+type batchLayer struct {
+	id     int
+	mat    Matrix
+	col    RGBA
+	static bool
+
+	items  []layerItem
+	data   TrianglesData
+	sorted TrianglesData
+	d      Drawer
+	dirty  bool
+}
+
+type layerItem struct {
+	depth    float64
+	off, len int
+}
+
+// NewLayerBatch creates an empty LayerBatch with the specified Picture. If the Picture is nil,
+// objects are drawn without a Picture.
+func NewLayerBatch(pic Picture) *LayerBatch {
+	b := &LayerBatch{pic: pic}
+	b.SetMatrix(IM)
+	b.SetColorMask(Alpha(1))
+	return b
+}
+
+// Clear removes all objects from all non-static layers of the LayerBatch. Layer settings (Matrix,
+// color mask and the static flag) are preserved.
+//
+// Use ClearLayer to remove objects from a static layer.
+func (b *LayerBatch) Clear() {
+	for _, l := range b.layers {
+		if !l.static {
+			l.clear()
+		}
+	}
+}
+
+// ClearLayer removes all objects from the given layer, regardless of whether it's static or not.
+func (b *LayerBatch) ClearLayer(layer int) {
+	if l := b.find(layer); l != nil {
+		l.clear()
+	}
+}
+
+// Draw draws all objects that are currently in the LayerBatch onto another Target, layer by
+// layer, each layer sorted by depth.
+func (b *LayerBatch) Draw(t Target) {
+	for _, l := range b.layers {
+		if l.dirty {
+			l.sort()
+		}
+		l.d.Draw(t)
+	}
+}
+
+// SetLayer sets the layer that the following draws onto the LayerBatch will be put into.
+func (b *LayerBatch) SetLayer(layer int) {
+	b.layer = layer
+}
+
+// Layer returns the layer that the following draws onto the LayerBatch will be put into.
+func (b *LayerBatch) Layer() int {
+	return b.layer
+}
+
+// SetDepth sets the depth key of the following draws onto the LayerBatch. Objects with a greater
+// depth are drawn on top of objects with a lower depth within the same layer.
+func (b *LayerBatch) SetDepth(depth float64) {
+	b.depth = depth
+}
+
+// Depth returns the depth key of the following draws onto the LayerBatch.
+func (b *LayerBatch) Depth() float64 {
+	return b.depth
+}
+
+// SetMatrix sets a Matrix that every point will be projected by.
+func (b *LayerBatch) SetMatrix(m Matrix) {
+	b.mat = m
+}
+
+// SetColorMask sets a mask color used in the following draws onto the LayerBatch.
+func (b *LayerBatch) SetColorMask(c color.Color) {
+	if c == nil {
+		b.col = Alpha(1)
+		return
+	}
+	b.col = ToRGBA(c)
+}
+
+// SetLayerMatrix sets a Matrix that all points in the given layer will be projected by when the
+// LayerBatch is drawn. The Matrix is applied after the Matrix set by SetMatrix.
+func (b *LayerBatch) SetLayerMatrix(layer int, m Matrix) {
+	l := b.get(layer)
+	if l.mat != m {
+		l.mat = m
+		l.dirty = true
+	}
+}
+
+// SetLayerColorMask sets a color that all colors in the given layer will be multiplied by when the
+// LayerBatch is drawn.
+func (b *LayerBatch) SetLayerColorMask(layer int, c color.Color) {
+	rgba := Alpha(1)
+	if c != nil {
+		rgba = ToRGBA(c)
+	}
+	l := b.get(layer)
+	if l.col != rgba {
+		l.col = rgba
+		l.dirty = true
+	}
+}
+
+// SetLayerStatic sets whether the given layer is static. Objects in a static layer are not removed
+// by Clear and the layer is only re-sorted when it changes, which makes static layers suitable for
+// geometry that doesn't change from frame to frame, such as a level background.
+func (b *LayerBatch) SetLayerStatic(layer int, static bool) {
+	b.get(layer).static = static
+}
+
+// LayerStatic returns whether the given layer is static.
+func (b *LayerBatch) LayerStatic(layer int) bool {
+	if l := b.find(layer); l != nil {
+		return l.static
+	}
+	return false
+}
+
+// MakeTriangles returns a specialized copy of the provided Triangles that draws onto this
+// LayerBatch.
+func (b *LayerBatch) MakeTriangles(t Triangles) TargetTriangles {
+	return &layerBatchTriangles{
+		tri: t.Copy(),
+		tmp: MakeTrianglesData(t.Len()),
+		dst: b,
+	}
+}
+
+// MakePicture returns a specialized copy of the provided Picture that draws onto this LayerBatch.
+func (b *LayerBatch) MakePicture(p Picture) TargetPicture {
+	if p != b.pic {
+		panic(fmt.Errorf("(%T).MakePicture: Picture is not the LayerBatch's Picture", b))
+	}
+	return &layerBatchPicture{
+		pic: p,
+		dst: b,
+	}
+}
+
+// find returns the layer with the given id, or nil if there's no such layer.
+func (b *LayerBatch) find(id int) *batchLayer {
+	i := sort.Search(len(b.layers), func(i int) bool { return b.layers[i].id >= id })
+	if i < len(b.layers) && b.layers[i].id == id {
+		return b.layers[i]
+	}
+	return nil
+}
+
+// get returns the layer with the given id, creating it if it doesn't exist yet.
+func (b *LayerBatch) get(id int) *batchLayer {
+	i := sort.Search(len(b.layers), func(i int) bool { return b.layers[i].id >= id })
+	if i < len(b.layers) && b.layers[i].id == id {
+		return b.layers[i]
+	}
+	l := &batchLayer{
+		id:  id,
+		mat: IM,
+		col: Alpha(1),
+	}
+	l.d.Triangles = &l.sorted
+	l.d.Picture = b.pic
+	b.layers = append(b.layers, nil)
+	copy(b.layers[i+1:], b.layers[i:])
+	b.layers[i] = l
+	return l
+}
+
+func (l *batchLayer) clear() {
+	if len(l.items) == 0 {
+		return
+	}
+	l.items = l.items[:0]
+	l.data = l.data[:0]
+	l.dirty = true
+}
+
+func (l *batchLayer) add(depth float64, t *TrianglesData) {
+	l.items = append(l.items, layerItem{
+		depth: depth,
+		off:   len(l.data),
+		len:   len(*t),
+	})
+	l.data = append(l.data, *t...)
+	l.dirty = true
+}
+
+// sort rebuilds the sorted geometry of the layer and applies the layer's Matrix and color mask.
+func (l *batchLayer) sort() {
+	sort.SliceStable(l.items, func(i, j int) bool {
+		return l.items[i].depth < l.items[j].depth
+	})
+
+	l.sorted = l.sorted[:0]
+	for _, it := range l.items {
+		l.sorted = append(l.sorted, l.data[it.off:it.off+it.len]...)
+	}
+	for i := range l.sorted {
+		l.sorted[i].Position = l.mat.Project(l.sorted[i].Position)
+		l.sorted[i].Color = l.col.Mul(l.sorted[i].Color)
+	}
+
+	l.d.Dirty()
+	l.dirty = false
+}
+
+type layerBatchTriangles struct {
+	tri Triangles
+	tmp *TrianglesData
+	dst *LayerBatch
+}
+
+func (lt *layerBatchTriangles) Len() int {
+	return lt.tri.Len()
+}
+
+func (lt *layerBatchTriangles) SetLen(len int) {
+	lt.tri.SetLen(len)
+	lt.tmp.SetLen(len)
+}
+
+func (lt *layerBatchTriangles) Slice(i, j int) Triangles {
+	return &layerBatchTriangles{
+		tri: lt.tri.Slice(i, j),
+		tmp: lt.tmp.Slice(i, j).(*TrianglesData),
+		dst: lt.dst,
+	}
+}
+
+func (lt *layerBatchTriangles) Update(t Triangles) {
+	lt.tri.Update(t)
+}
+
+func (lt *layerBatchTriangles) Copy() Triangles {
+	return &layerBatchTriangles{
+		tri: lt.tri.Copy(),
+		tmp: lt.tmp.Copy().(*TrianglesData),
+		dst: lt.dst,
+	}
+}
+
+func (lt *layerBatchTriangles) draw(lp *layerBatchPicture) {
+	lt.tmp.Update(lt.tri)
+
+	for i := range *lt.tmp {
+		(*lt.tmp)[i].Position = lt.dst.mat.Project((*lt.tmp)[i].Position)
+		(*lt.tmp)[i].Color = lt.dst.col.Mul((*lt.tmp)[i].Color)
+	}
+
+	lt.dst.get(lt.dst.layer).add(lt.dst.depth, lt.tmp)
+}
+
+func (lt *layerBatchTriangles) Draw() {
+	lt.draw(nil)
+}
+
+type layerBatchPicture struct {
+	pic Picture
+	dst *LayerBatch
+}
+
+func (lp *layerBatchPicture) Bounds() Rect {
+	return lp.pic.Bounds()
+}
+
+func (lp *layerBatchPicture) Draw(t TargetTriangles) {
+	lt := t.(*layerBatchTriangles)
+	if lp.dst != lt.dst {
+		panic(fmt.Errorf("(%T).Draw: TargetTriangles generated by different LayerBatch", lp))
+	}
+	lt.draw(lp)
+}
@@ -0,0 +1,99 @@
+package pixel_test
+
+import (
+	"testing"
+
+	"github.com/faiface/pixel"
+)
+
+// triangleAt returns a single-triangle TrianglesData with all vertices colored col.
+func triangleAt(pos pixel.Vec, col pixel.RGBA) *pixel.TrianglesData {
+	tri := pixel.MakeTrianglesData(3)
+	(*tri)[0].Position = pos
+	(*tri)[1].Position = pos.Add(pixel.V(1, 0))
+	(*tri)[2].Position = pos.Add(pixel.V(0, 1))
+	for i := range *tri {
+		(*tri)[i].Color = col
+	}
+	return tri
+}
+
+func TestLayerBatch_Sorting(t *testing.T) {
+	batch := pixel.NewLayerBatch(nil)
+
+	draws := []struct {
+		layer int
+		depth float64
+		x     float64
+	}{
+		{layer: 1, depth: 5, x: 0},
+		{layer: 0, depth: 3, x: 1},
+		{layer: 1, depth: 2, x: 2},
+		{layer: 0, depth: 3, x: 3},
+		{layer: 0, depth: 1, x: 4},
+	}
+	for _, d := range draws {
+		batch.SetLayer(d.layer)
+		batch.SetDepth(d.depth)
+		d := pixel.Drawer{Triangles: triangleAt(pixel.V(d.x, 0), pixel.Alpha(1))}
+		d.Draw(batch)
+	}
+
+	out := &pixel.TrianglesData{}
+	batch.Draw(pixel.NewBatch(out, nil))
+
+	want := []float64{4, 1, 3, 2, 0}
+	if out.Len() != 3*len(want) {
+		t.Fatalf("out.Len() = %d, want %d", out.Len(), 3*len(want))
+	}
+	for i, x := range want {
+		if got := (*out)[3*i].Position.X; got != x {
+			t.Errorf("triangle %d: X = %v, want %v", i, got, x)
+		}
+	}
+}
+
+func TestLayerBatch_LayerSettings(t *testing.T) {
+	batch := pixel.NewLayerBatch(nil)
+	batch.SetLayerMatrix(0, pixel.IM.Moved(pixel.V(10, 0)))
+	batch.SetLayerColorMask(0, pixel.RGB(1, 0, 0))
+
+	d := pixel.Drawer{Triangles: triangleAt(pixel.ZV, pixel.Alpha(1))}
+	d.Draw(batch)
+
+	out := &pixel.TrianglesData{}
+	batch.Draw(pixel.NewBatch(out, nil))
+
+	if got, want := (*out)[0].Position, pixel.V(10, 0); got != want {
+		t.Errorf("Position = %v, want %v", got, want)
+	}
+	if got, want := (*out)[0].Color, pixel.RGB(1, 0, 0); got != want {
+		t.Errorf("Color = %v, want %v", got, want)
+	}
+}
+
+func TestLayerBatch_Static(t *testing.T) {
+	batch := pixel.NewLayerBatch(nil)
+	batch.SetLayerStatic(0, true)
+
+	d := pixel.Drawer{Triangles: triangleAt(pixel.ZV, pixel.Alpha(1))}
+	d.Draw(batch)
+	batch.SetLayer(1)
+	d.Draw(batch)
+
+	batch.Clear()
+
+	out := &pixel.TrianglesData{}
+	batch.Draw(pixel.NewBatch(out, nil))
+	if got, want := out.Len(), 3; got != want {
+		t.Fatalf("after Clear: out.Len() = %d, want %d", got, want)
+	}
+
+	batch.ClearLayer(0)
+
+	out.SetLen(0)
+	batch.Draw(pixel.NewBatch(out, nil))
+	if got, want := out.Len(), 0; got != want {
+		t.Fatalf("after ClearLayer: out.Len() = %d, want %d", got, want)
+	}
+}
@@ -0,0 +1,70 @@
+package particles
+
+import "github.com/faiface/pixel"
+
+// Stop is a value of a Curve at a point in time. T is normalized to the lifetime of a particle,
+// 0 is the birth and 1 is the death.
+type Stop struct {
+	T     float64
+	Value float64
+}
+
+// Curve is a scalar value changing over the lifetime of a particle. The value is linearly
+// interpolated between the stops, which must be sorted by T.
+//
+// An empty Curve has the value 1.
+type Curve []Stop
+
+// At returns the value of the Curve at t.
+func (c Curve) At(t float64) float64 {
+	if len(c) == 0 {
+		return 1
+	}
+	if t <= c[0].T {
+		return c[0].Value
+	}
+	for i := 1; i < len(c); i++ {
+		if t <= c[i].T {
+			a, b := c[i-1], c[i]
+			if b.T == a.T {
+				return b.Value
+			}
+			return a.Value + (b.Value-a.Value)*(t-a.T)/(b.T-a.T)
+		}
+	}
+	return c[len(c)-1].Value
+}
+
+// ColorStop is a color of a ColorCurve at a point in time. T is normalized to the lifetime of a
+// particle, 0 is the birth and 1 is the death.
+type ColorStop struct {
+	T     float64
+	Color pixel.RGBA
+}
+
+// ColorCurve is a color changing over the lifetime of a particle. The color is linearly
+// interpolated between the stops, which must be sorted by T.
+//
+// An empty ColorCurve is fully opaque white.
+type ColorCurve []ColorStop
+
+// At returns the color of the ColorCurve at t.
+func (c ColorCurve) At(t float64) pixel.RGBA {
+	if len(c) == 0 {
+		return pixel.Alpha(1)
+	}
+	if t <= c[0].T {
+		return c[0].Color
+	}
+	for i := 1; i < len(c); i++ {
+		if t <= c[i].T {
+			a, b := c[i-1], c[i]
+			if b.T == a.T {
+				return b.Color
+			}
+			f := (t - a.T) / (b.T - a.T)
+			return a.Color.Scaled(1 - f).Add(b.Color.Scaled(f))
+		}
+	}
+	return c[len(c)-1].Color
+}
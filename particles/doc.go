@@ -0,0 +1,9 @@
+// Package particles implements a simple particle system for the Pixel library.
+//
+// A System consists of Emitters, which spawn particles from a Shape and simulate their
+// movement, color, scale and rotation over their lifetime. All particles of a System are drawn
+// with a single draw call using a pixel.Batch.
+//
+// The simulation is advanced explicitly by calling Update and is fully determined by the seed
+// passed to New, which makes effects reproducible and testable.
+package particles
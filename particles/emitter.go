@@ -0,0 +1,272 @@
+package particles
+
+import (
+	"math"
+	"math/rand"
+
+	"github.com/faiface/pixel"
+)
+
+// Shape specifies an area particles are spawned from.
+type Shape interface {
+	// Sample returns a random position inside the Shape.
+	Sample(rng *rand.Rand) pixel.Vec
+}
+
+type pointShape pixel.Vec
+
+func (ps pointShape) Sample(*rand.Rand) pixel.Vec {
+	return pixel.Vec(ps)
+}
+
+// Point returns a Shape spawning all particles at a single position.
+func Point(pos pixel.Vec) Shape {
+	return pointShape(pos)
+}
+
+type lineShape pixel.Line
+
+func (ls lineShape) Sample(rng *rand.Rand) pixel.Vec {
+	return pixel.Lerp(ls.A, ls.B, rng.Float64())
+}
+
+// Line returns a Shape spawning particles uniformly along a line segment.
+func Line(l pixel.Line) Shape {
+	return lineShape(l)
+}
+
+type rectShape pixel.Rect
+
+func (rs rectShape) Sample(rng *rand.Rand) pixel.Vec {
+	return pixel.V(
+		rs.Min.X+rng.Float64()*(rs.Max.X-rs.Min.X),
+		rs.Min.Y+rng.Float64()*(rs.Max.Y-rs.Min.Y),
+	)
+}
+
+// Rect returns a Shape spawning particles uniformly inside a rectangle.
+func Rect(r pixel.Rect) Shape {
+	return rectShape(r)
+}
+
+type circleShape pixel.Circle
+
+func (cs circleShape) Sample(rng *rand.Rand) pixel.Vec {
+	// square root for uniform distribution over the area
+	r := cs.Radius * math.Sqrt(rng.Float64())
+	return cs.Center.Add(pixel.Unit(rng.Float64() * 2 * math.Pi).Scaled(r))
+}
+
+// Circle returns a Shape spawning particles uniformly inside a circle.
+func Circle(c pixel.Circle) Shape {
+	return circleShape(c)
+}
+
+// Emitter spawns and simulates particles. Set it's exported fields to configure the particles,
+// they can be changed at any time and affect the following spawns (and the simulation of all
+// particles in case of Acceleration, Drag and the curves).
+//
+// Every property ending with Var is a variance. The actual value of the property is chosen
+// uniformly from the range [X-XVar, X+XVar] for each particle.
+//
+//   sparks := &particles.Emitter{
+//       Shape:     particles.Point(pixel.ZV),
+//       Rate:      200,
+//       Lifetime:  0.5,
+//       Direction: math.Pi / 2,
+//       Spread:    math.Pi / 4,
+//       Speed:     300,
+//       Color: particles.ColorCurve{
+//           {T: 0, Color: pixel.RGB(1, 1, 0)},
+//           {T: 1, Color: pixel.Alpha(0)},
+//       },
+//   }
+type Emitter struct {
+	// Pos is the position of the Emitter. Shape is relative to it.
+	Pos pixel.Vec
+
+	// Shape is the area where particles are spawned. If nil, particles spawn at Pos.
+	Shape Shape
+
+	// Rate is the number of particles spawned per second.
+	Rate float64
+
+	// MaxParticles limits the number of alive particles of the Emitter. Zero means no limit.
+	MaxParticles int
+
+	// Lifetime is the time in seconds a particle lives.
+	Lifetime, LifetimeVar float64
+
+	// Direction is the angle of the initial velocity of a particle. Spread is the size of the
+	// angle range around Direction the velocity is randomly chosen from.
+	Direction, Spread float64
+
+	// Speed is the initial speed of a particle in units per second.
+	Speed, SpeedVar float64
+
+	// Acceleration is applied to the velocity of all particles every Update (e.g. gravity).
+	Acceleration pixel.Vec
+
+	// Drag is the fraction of the velocity lost per second.
+	Drag float64
+
+	// Rotation is the initial rotation of a particle. AngularVelocity is the change of the
+	// rotation in radians per second.
+	Rotation, RotationVar               float64
+	AngularVelocity, AngularVelocityVar float64
+
+	// Color is the color of a particle over it's lifetime.
+	Color ColorCurve
+
+	// Scale is the scale of a particle over it's lifetime. Particles without Frames are squares
+	// with the side of Scale units.
+	Scale Curve
+
+	// Frames are rectangles of the System's Picture used to draw particles. If Animate is false,
+	// a random frame is chosen for each particle, otherwise the frames are played over the
+	// lifetime of a particle.
+	Frames  []pixel.Rect
+	Animate bool
+
+	particles []particle
+	acc       float64
+	pending   int
+}
+
+type particle struct {
+	pos, vel pixel.Vec
+	rot, ang float64
+	age      float64
+	life     float64
+	frame    int
+}
+
+// Burst spawns n particles at once during the next Update.
+func (e *Emitter) Burst(n int) {
+	e.pending += n
+}
+
+// Len returns the number of alive particles of the Emitter.
+func (e *Emitter) Len() int {
+	return len(e.particles)
+}
+
+// Clear removes all particles of the Emitter.
+func (e *Emitter) Clear() {
+	e.particles = e.particles[:0]
+	e.acc = 0
+	e.pending = 0
+}
+
+func vary(rng *rand.Rand, base, variance float64) float64 {
+	return base + (rng.Float64()*2-1)*variance
+}
+
+func (e *Emitter) spawn(rng *rand.Rand) {
+	pos := e.Pos
+	if e.Shape != nil {
+		pos = pos.Add(e.Shape.Sample(rng))
+	}
+	angle := e.Direction + (rng.Float64()-0.5)*e.Spread
+	speed := vary(rng, e.Speed, e.SpeedVar)
+
+	p := particle{
+		pos:  pos,
+		vel:  pixel.Unit(angle).Scaled(speed),
+		rot:  vary(rng, e.Rotation, e.RotationVar),
+		ang:  vary(rng, e.AngularVelocity, e.AngularVelocityVar),
+		life: vary(rng, e.Lifetime, e.LifetimeVar),
+	}
+	if len(e.Frames) > 0 && !e.Animate {
+		p.frame = rng.Intn(len(e.Frames))
+	}
+	e.particles = append(e.particles, p)
+}
+
+func (e *Emitter) update(rng *rand.Rand, dt float64) {
+	// simulate existing particles, preserving their order
+	alive := e.particles[:0]
+	drag := math.Max(0, 1-e.Drag*dt)
+	for _, p := range e.particles {
+		p.age += dt
+		if p.age >= p.life {
+			continue
+		}
+		p.vel = p.vel.Add(e.Acceleration.Scaled(dt)).Scaled(drag)
+		p.pos = p.pos.Add(p.vel.Scaled(dt))
+		p.rot += p.ang * dt
+		alive = append(alive, p)
+	}
+	e.particles = alive
+
+	// spawn new particles
+	e.acc += e.Rate * dt
+	n := int(e.acc)
+	e.acc -= float64(n)
+	n += e.pending
+	e.pending = 0
+
+	for i := 0; i < n; i++ {
+		if e.MaxParticles > 0 && len(e.particles) >= e.MaxParticles {
+			break
+		}
+		e.spawn(rng)
+	}
+}
+
+// appendQuads appends two triangles for each particle to td.
+func (e *Emitter) appendQuads(td *pixel.TrianglesData) {
+	for _, p := range e.particles {
+		// particles without a lifetime are at the end of it
+		t := 1.0
+		if p.life > 0 {
+			t = math.Min(p.age/p.life, 1)
+		}
+		col := e.Color.At(t)
+		scale := e.Scale.At(t)
+
+		var frame pixel.Rect
+		intensity := 0.0
+		size := pixel.V(1, 1)
+		if len(e.Frames) > 0 {
+			i := p.frame
+			if e.Animate {
+				i = int(t * float64(len(e.Frames)))
+			}
+			// the Frames may have changed since the particle was spawned
+			if i >= len(e.Frames) {
+				i = len(e.Frames) - 1
+			}
+			if i < 0 {
+				i = 0
+			}
+			frame = e.Frames[i]
+			intensity = 1
+			size = frame.Size()
+		}
+
+		mat := pixel.IM.Scaled(pixel.ZV, scale).Rotated(pixel.ZV, p.rot).Moved(p.pos)
+		half := size.Scaled(0.5)
+		pos := [...]pixel.Vec{
+			pixel.V(-half.X, -half.Y),
+			pixel.V(half.X, -half.Y),
+			pixel.V(half.X, half.Y),
+			pixel.V(-half.X, half.Y),
+		}
+		uv := [...]pixel.Vec{
+			frame.Min,
+			pixel.V(frame.Max.X, frame.Min.Y),
+			frame.Max,
+			pixel.V(frame.Min.X, frame.Max.Y),
+		}
+
+		off := td.Len()
+		td.SetLen(off + 6)
+		for k, l := range [...]int{0, 1, 2, 0, 2, 3} {
+			(*td)[off+k].Position = mat.Project(pos[l])
+			(*td)[off+k].Color = col
+			(*td)[off+k].Picture = uv[l]
+			(*td)[off+k].Intensity = intensity
+		}
+	}
+}
@@ -0,0 +1,140 @@
+package particles_test
+
+import (
+	"math"
+	"testing"
+
+	"github.com/faiface/pixel"
+	"github.com/faiface/pixel/particles"
+)
+
+func newFountain() *particles.Emitter {
+	return &particles.Emitter{
+		Shape:        particles.Circle(pixel.C(pixel.ZV, 10)),
+		Rate:         100,
+		Lifetime:     1,
+		LifetimeVar:  0.5,
+		Direction:    math.Pi / 2,
+		Spread:       math.Pi / 4,
+		Speed:        100,
+		SpeedVar:     20,
+		Acceleration: pixel.V(0, -200),
+		Drag:         0.1,
+		Color: particles.ColorCurve{
+			{T: 0, Color: pixel.RGB(1, 1, 0)},
+			{T: 1, Color: pixel.Alpha(0)},
+		},
+		Scale: particles.Curve{{T: 0, Value: 4}, {T: 1, Value: 1}},
+	}
+}
+
+func render(s *particles.System) *pixel.TrianglesData {
+	out := &pixel.TrianglesData{}
+	s.Draw(pixel.NewBatch(out, nil))
+	return out
+}
+
+func TestSystem_Deterministic(t *testing.T) {
+	a, b := particles.New(nil, 7), particles.New(nil, 7)
+	a.Add(newFountain())
+	b.Add(newFountain())
+
+	for i := 0; i < 30; i++ {
+		a.Update(1.0 / 30)
+		b.Update(1.0 / 30)
+	}
+
+	outA, outB := render(a), render(b)
+	if outA.Len() == 0 {
+		t.Fatalf("no particles were drawn")
+	}
+	if outA.Len() != outB.Len() {
+		t.Fatalf("len %d != %d", outA.Len(), outB.Len())
+	}
+	for i := range *outA {
+		if (*outA)[i] != (*outB)[i] {
+			t.Fatalf("vertex %d differs: %v != %v", i, (*outA)[i], (*outB)[i])
+		}
+	}
+}
+
+func TestEmitter_BurstAndLifetime(t *testing.T) {
+	sys := particles.New(nil, 1)
+	e := &particles.Emitter{Lifetime: 1, MaxParticles: 10}
+	sys.Add(e)
+
+	e.Burst(15)
+	sys.Update(0.1)
+	if got, want := sys.Len(), 10; got != want {
+		t.Fatalf("after burst: Len() = %d, want %d", got, want)
+	}
+	if got, want := render(sys).Len(), 6*10; got != want {
+		t.Fatalf("vertices = %d, want %d", got, want)
+	}
+
+	sys.Update(1)
+	if got, want := sys.Len(), 0; got != want {
+		t.Fatalf("after lifetime: Len() = %d, want %d", got, want)
+	}
+}
+
+func TestEmitter_ZeroLifetime(t *testing.T) {
+	sys := particles.New(nil, 1)
+	e := &particles.Emitter{
+		Frames:  []pixel.Rect{pixel.R(0, 0, 8, 8), pixel.R(8, 0, 16, 8)},
+		Animate: true,
+		Scale:   particles.Curve{{T: 0, Value: 1}},
+	}
+	sys.Add(e)
+
+	e.Burst(3)
+	sys.Update(0.1)
+	out := render(sys)
+	if got, want := out.Len(), 6*3; got != want {
+		t.Fatalf("vertices = %d, want %d", got, want)
+	}
+	for i, v := range *out {
+		if math.IsNaN(v.Position.X) || math.IsNaN(v.Position.Y) || math.IsNaN(v.Color.A) {
+			t.Fatalf("vertex %d is not a number: %v", i, v)
+		}
+		if v.Picture.X < 8 {
+			t.Fatalf("vertex %d is not in the last frame: %v", i, v.Picture)
+		}
+	}
+
+	// frames removed after random frames were chosen
+	e.Animate = false
+	e.Burst(10)
+	sys.Update(0.1)
+	e.Frames = e.Frames[:1]
+	render(sys)
+}
+
+func TestEmitter_Rate(t *testing.T) {
+	sys := particles.New(nil, 1)
+	e := &particles.Emitter{Rate: 10, Lifetime: 100}
+	sys.Add(e)
+
+	for i := 0; i < 20; i++ {
+		sys.Update(0.05)
+	}
+	if got, want := e.Len(), 10; got != want {
+		t.Fatalf("Len() = %d, want %d", got, want)
+	}
+}
+
+func TestCurve_At(t *testing.T) {
+	c := particles.Curve{{T: 0, Value: 0}, {T: 0.5, Value: 10}, {T: 1, Value: 0}}
+	for _, tt := range []struct{ t, want float64 }{
+		{-1, 0}, {0, 0}, {0.25, 5}, {0.5, 10}, {0.75, 5}, {2, 0},
+	} {
+		if got := c.At(tt.t); got != tt.want {
+			t.Errorf("At(%v) = %v, want %v", tt.t, got, tt.want)
+		}
+	}
+
+	cc := particles.ColorCurve{{T: 0, Color: pixel.RGB(1, 0, 0)}, {T: 1, Color: pixel.RGB(0, 0, 1)}}
+	if got, want := cc.At(0.5), pixel.RGB(0.5, 0, 0.5); got != want {
+		t.Errorf("ColorCurve.At(0.5) = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,97 @@
+package particles
+
+import (
+	"math/rand"
+
+	"github.com/faiface/pixel"
+)
+
+// System is a set of Emitters sharing one Picture, drawn together in a single draw call.
+//
+//   sys := particles.New(pic, 42)
+//   sys.Add(sparks)
+//
+//   // each frame
+//   sys.Update(dt)
+//   sys.Draw(win)
+type System struct {
+	emitters []*Emitter
+	rng      *rand.Rand
+
+	tri   *pixel.TrianglesData
+	batch *pixel.Batch
+	dirty bool
+}
+
+// New creates an empty System drawing particles with the given Picture (which may be nil if no
+// Emitter uses Frames). The seed determines all random choices of the System, so two Systems with
+// the same seed, Emitters and Updates produce exactly the same particles.
+func New(pic pixel.Picture, seed int64) *System {
+	tri := &pixel.TrianglesData{}
+	return &System{
+		rng:   rand.New(rand.NewSource(seed)),
+		tri:   tri,
+		batch: pixel.NewBatch(tri, pic),
+	}
+}
+
+// Add adds Emitters to the System.
+func (s *System) Add(emitters ...*Emitter) {
+	s.emitters = append(s.emitters, emitters...)
+}
+
+// Remove removes an Emitter (and all of it's particles) from the System.
+func (s *System) Remove(e *Emitter) {
+	for i := range s.emitters {
+		if s.emitters[i] == e {
+			s.emitters = append(s.emitters[:i], s.emitters[i+1:]...)
+			s.dirty = true
+			return
+		}
+	}
+}
+
+// Emitters returns all Emitters of the System.
+func (s *System) Emitters() []*Emitter {
+	return s.emitters
+}
+
+// Len returns the number of alive particles in the System.
+func (s *System) Len() int {
+	n := 0
+	for _, e := range s.emitters {
+		n += e.Len()
+	}
+	return n
+}
+
+// Clear removes all particles from all Emitters of the System.
+func (s *System) Clear() {
+	for _, e := range s.emitters {
+		e.Clear()
+	}
+	s.dirty = true
+}
+
+// Update advances the simulation by dt seconds: ages, moves and removes existing particles and
+// spawns new ones.
+func (s *System) Update(dt float64) {
+	for _, e := range s.emitters {
+		e.update(s.rng, dt)
+	}
+	s.dirty = true
+}
+
+// Draw draws all particles onto the provided Target. Emitters are drawn in the order they were
+// added, particles of an Emitter in the order they were spawned.
+func (s *System) Draw(t pixel.Target) {
+	if s.dirty {
+		s.tri.SetLen(0)
+		for _, e := range s.emitters {
+			e.appendQuads(s.tri)
+		}
+		s.batch.Dirty()
+		s.dirty = false
+	}
+	s.batch.Draw(t)
+}
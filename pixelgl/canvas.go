@@ -24,6 +24,9 @@ type Canvas struct {
 	col    mgl32.Vec4
 	smooth bool
 
+	stats   pixel.RenderStats
+	lastTex *glhf.Texture
+
 	sprite *pixel.Sprite
 }
 
@@ -61,8 +64,11 @@ func (c *Canvas) SetFragmentShader(src string) {
 //
 // TrianglesPosition, TrianglesColor and TrianglesPicture are supported.
 func (c *Canvas) MakeTriangles(t pixel.Triangles) pixel.TargetTriangles {
+	c.stats.TrianglesMade++
+	gt := NewGLTriangles(c.shader.s, t)
+	gt.stats = &c.stats
 	return &canvasTriangles{
-		GLTriangles: NewGLTriangles(c.shader.s, t),
+		GLTriangles: gt,
 		dst:         c,
 	}
 }
@@ -71,6 +77,7 @@ func (c *Canvas) MakeTriangles(t pixel.Triangles) pixel.TargetTriangles {
 //
 // PictureColor is supported.
 func (c *Canvas) MakePicture(p pixel.Picture) pixel.TargetPicture {
+	c.stats.PicturesMade++
 	if cp, ok := p.(*canvasPicture); ok {
 		return &canvasPicture{
 			GLPicture: cp.GLPicture,
@@ -118,9 +125,22 @@ func (c *Canvas) SetColorMask(col color.Color) {
 // SetComposeMethod sets a Porter-Duff composition method to be used in the following draws onto
 // this Canvas.
 func (c *Canvas) SetComposeMethod(cmp pixel.ComposeMethod) {
+	if cmp != c.cmp {
+		c.stats.ComposeChanges++
+	}
 	c.cmp = cmp
 }
 
+// Stats returns the RenderStats of this Canvas collected since the last call to ResetStats.
+func (c *Canvas) Stats() pixel.RenderStats {
+	return c.stats
+}
+
+// ResetStats sets all RenderStats counters of this Canvas to zero.
+func (c *Canvas) ResetStats() {
+	c.stats = pixel.RenderStats{}
+}
+
 // SetBounds resizes the Canvas to the new bounds. Old content will be preserved.
 func (c *Canvas) SetBounds(bounds pixel.Rect) {
 	c.gf.SetBounds(bounds)
@@ -277,6 +297,13 @@ type canvasTriangles struct {
 func (ct *canvasTriangles) draw(tex *glhf.Texture, bounds pixel.Rect) {
 	ct.dst.gf.Dirty()
 
+	ct.dst.stats.DrawCalls++
+	ct.dst.stats.Vertices += ct.Len()
+	if tex != ct.dst.lastTex {
+		ct.dst.lastTex = tex
+		ct.dst.stats.PictureSwitches++
+	}
+
 	// save the current state vars to avoid race condition
 	cmp := ct.dst.cmp
 	smt := ct.dst.smooth
@@ -352,6 +379,9 @@ func (cp *canvasPicture) Draw(t pixel.TargetTriangles) {
 	if cp.dst != ct.dst {
 		panic(fmt.Errorf("(%T).Draw: TargetTriangles generated by different Canvas", cp))
 	}
+	if gp, ok := cp.GLPicture.(*glPicture); ok {
+		gp.update()
+	}
 	ct.draw(cp.GLPicture.Texture(), cp.GLPicture.Bounds())
 }
 
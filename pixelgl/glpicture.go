@@ -20,17 +20,39 @@ type GLPicture interface {
 
 // NewGLPicture creates a new GLPicture with it's own static OpenGL texture. This function always
 // allocates a new texture that cannot (shouldn't) be further modified.
+//
+// If the Picture is a pixel.DynamicPicture, the texture is updated with the changes of the Picture
+// when drawn onto a Canvas.
 func NewGLPicture(p pixel.Picture) GLPicture {
+	gp := &glPicture{bounds: p.Bounds()}
+	if dp, ok := p.(pixel.DynamicPicture); ok {
+		gp.src = dp
+		gp.version = dp.Version()
+	}
+
+	_, _, bw, bh := intBounds(gp.bounds)
+	gp.pixels = picturePixels(p, gp.bounds)
+
+	mainthread.Call(func() {
+		gp.tex = glhf.NewTexture(bw, bh, false, gp.pixels)
+	})
+
+	return gp
+}
+
+// picturePixels returns the pixels of the Picture inside the rectangle as an RGBA sequence.
+func picturePixels(p pixel.Picture, rect pixel.Rect) []uint8 {
 	bounds := p.Bounds()
-	bx, by, bw, bh := intBounds(bounds)
+	bx, by, bw, bh := intBounds(rect)
 
 	pixels := make([]uint8, 4*bw*bh)
 
 	if pd, ok := p.(*pixel.PictureData); ok {
 		// PictureData short path
+		px, py, _, _ := intBounds(pd.Rect)
 		for y := 0; y < bh; y++ {
 			for x := 0; x < bw; x++ {
-				rgba := pd.Pix[y*pd.Stride+x]
+				rgba := pd.Pix[(by-py+y)*pd.Stride+bx-px+x]
 				off := (y*bw + x) * 4
 				pixels[off+0] = rgba.R
 				pixels[off+1] = rgba.G
@@ -55,23 +77,49 @@ func NewGLPicture(p pixel.Picture) GLPicture {
 		}
 	}
 
-	var tex *glhf.Texture
-	mainthread.Call(func() {
-		tex = glhf.NewTexture(bw, bh, false, pixels)
-	})
-
-	gp := &glPicture{
-		bounds: bounds,
-		tex:    tex,
-		pixels: pixels,
-	}
-	return gp
+	return pixels
 }
 
 type glPicture struct {
 	bounds pixel.Rect
 	tex    *glhf.Texture
 	pixels []uint8
+
+	// the DynamicPicture the texture was made from and it's version copied to the texture
+	src     pixel.DynamicPicture
+	version uint64
+}
+
+// update copies the changes of the DynamicPicture made into the GLPicture to it's texture.
+func (gp *glPicture) update() {
+	if gp.src == nil || gp.src.Version() == gp.version {
+		return
+	}
+	if gp.src.Bounds() != gp.bounds {
+		*gp = *NewGLPicture(gp.src).(*glPicture)
+		return
+	}
+
+	version := gp.src.Version()
+	changed := gp.src.Changed(gp.version).Intersect(gp.bounds)
+	gp.version = version
+	if changed.Area() == 0 {
+		return
+	}
+
+	bx, by, bw, _ := intBounds(gp.bounds)
+	x, y, w, h := intBounds(changed)
+	pixels := picturePixels(gp.src, changed)
+	for row := 0; row < h; row++ {
+		off := ((y-by+row)*bw + x - bx) * 4
+		copy(gp.pixels[off:off+4*w], pixels[row*4*w:(row+1)*4*w])
+	}
+
+	mainthread.Call(func() {
+		gp.tex.Begin()
+		gp.tex.SetPixels(x-bx, y-by, w, h, pixels)
+		gp.tex.End()
+	})
 }
 
 func (gp *glPicture) Bounds() pixel.Rect {
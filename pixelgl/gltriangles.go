@@ -16,6 +16,7 @@ type GLTriangles struct {
 	vs     *glhf.VertexSlice
 	data   []float32
 	shader *glhf.Shader
+	stats  *pixel.RenderStats
 }
 
 var (
@@ -77,6 +78,9 @@ func (gt *GLTriangles) SetLen(length int) {
 	default:
 		return
 	}
+	if gt.stats != nil {
+		gt.stats.BufferResizes++
+	}
 	mainthread.Call(func() {
 		gt.vs.Begin()
 		gt.vs.SetLen(length)
@@ -90,6 +94,7 @@ func (gt *GLTriangles) Slice(i, j int) pixel.Triangles {
 		vs:     gt.vs.Slice(i, j),
 		data:   gt.data[i*gt.vs.Stride() : j*gt.vs.Stride()],
 		shader: gt.shader,
+		stats:  gt.stats,
 	}
 }
 
@@ -159,6 +164,10 @@ func (gt *GLTriangles) Update(t pixel.Triangles) {
 		panic(fmt.Errorf("(%T).Update: invalid triangles len", gt))
 	}
 	gt.updateData(t)
+	if gt.stats != nil {
+		gt.stats.Uploads++
+		gt.stats.UploadedVertices += gt.Len()
+	}
 
 	// this code is supposed to copy the vertex data and CallNonBlock the update if
 	// the data is small enough, otherwise it'll block and not copy the data
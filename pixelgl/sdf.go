@@ -0,0 +1,136 @@
+package pixelgl
+
+import (
+	"image/color"
+
+	"github.com/faiface/pixel"
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+// SDFStyle is the style of text drawn from a signed distance field Atlas (see text.NewSDFAtlas)
+// onto a Canvas using SetSDFStyle.
+//
+// All distances are in pixels of the Atlas and the outline, glow and shadow must fit into the
+// Spread, which must be set to the Spread of the Atlas. Colors default to transparent, which turns
+// the effect off.
+type SDFStyle struct {
+	Spread float64
+
+	// OutlineWidth is the width of the outline around the glyphs.
+	OutlineWidth float64
+	OutlineColor color.Color
+
+	// GlowWidth is the distance, over which the glow around the glyphs and their outline fades out.
+	GlowWidth float64
+	GlowColor color.Color
+
+	// ShadowOffset is the offset of the shadow of the glyphs and their outline, which is blurred
+	// over the ShadowSoftness.
+	ShadowOffset   pixel.Vec
+	ShadowSoftness float64
+	ShadowColor    color.Color
+}
+
+// SetSDFStyle sets the fragment shader of the Canvas to a shader drawing text from signed distance
+// field Atlases in the style and makes the Canvas smooth. The shader keeps glyphs crisp at any
+// scale.
+//
+// Note, that the shader treats all Pictures drawn onto the Canvas as signed distance fields, so
+// it's best to draw the text onto it's own Canvas and then draw that Canvas onto a Window:
+//
+//   textCanvas.Clear(pixel.Alpha(0))
+//   textCanvas.SetSDFStyle(pixelgl.SDFStyle{
+//       Spread:       atlas.Spread(),
+//       OutlineWidth: 2,
+//       OutlineColor: colornames.Black,
+//   })
+//   txt.Draw(textCanvas, pixel.IM.Scaled(txt.Orig, 4))
+//   textCanvas.Draw(win, pixel.IM.Moved(win.Bounds().Center()))
+//
+// Shapes drawn without a Picture are drawn as usual.
+func (c *Canvas) SetSDFStyle(style SDFStyle) {
+	c.SetUniform("uSDFSpread", float32(style.Spread))
+	c.SetUniform("uOutlineWidth", float32(style.OutlineWidth))
+	c.SetUniform("uOutlineColor", colorUniform(style.OutlineColor))
+	c.SetUniform("uGlowWidth", float32(style.GlowWidth))
+	c.SetUniform("uGlowColor", colorUniform(style.GlowColor))
+	c.SetUniform("uShadowOffset", mgl32.Vec2{float32(style.ShadowOffset.X), float32(style.ShadowOffset.Y)})
+	c.SetUniform("uShadowSoftness", float32(style.ShadowSoftness))
+	c.SetUniform("uShadowColor", colorUniform(style.ShadowColor))
+
+	// the uniforms are only known to the shader after it's compiled
+	if c.shader.fs != sdfFragmentShader {
+		c.SetFragmentShader(sdfFragmentShader)
+	}
+	c.SetSmooth(true)
+}
+
+// colorUniform converts a color to a uniform value, nil is transparent.
+func colorUniform(c color.Color) mgl32.Vec4 {
+	if c == nil {
+		return mgl32.Vec4{}
+	}
+	rgba := pixel.ToRGBA(c)
+	return mgl32.Vec4{float32(rgba.R), float32(rgba.G), float32(rgba.B), float32(rgba.A)}
+}
+
+var sdfFragmentShader = `
+#version 330 core
+
+in vec4  vColor;
+in vec2  vTexCoords;
+in float vIntensity;
+
+out vec4 fragColor;
+
+uniform vec4 uColorMask;
+uniform vec4 uTexBounds;
+uniform sampler2D uTexture;
+
+uniform float uSDFSpread;
+uniform float uOutlineWidth;
+uniform vec4  uOutlineColor;
+uniform float uGlowWidth;
+uniform vec4  uGlowColor;
+uniform vec2  uShadowOffset;
+uniform float uShadowSoftness;
+uniform vec4  uShadowColor;
+
+// glyphDistance returns the signed distance to the edge of the glyph in pixels of the atlas,
+// positive inside of the glyph.
+float glyphDistance(vec2 at) {
+	vec2 t = (at - uTexBounds.xy) / uTexBounds.zw;
+	return (texture(uTexture, t).a - 0.5) * 2 * uSDFSpread;
+}
+
+// over composes premultiplied colors.
+vec4 over(vec4 top, vec4 bottom) {
+	return top + (1 - top.a) * bottom;
+}
+
+void main() {
+	if (vIntensity == 0) {
+		fragColor = uColorMask * vColor;
+		return;
+	}
+
+	float d = glyphDistance(vTexCoords);
+	// half of a pixel of the target, for anti-aliasing
+	float aa = max(fwidth(d) * 0.5, 0.0001);
+
+	float fill = smoothstep(-aa, aa, d);
+	float outline = smoothstep(-aa, aa, d + uOutlineWidth);
+	vec4 col = vColor * fill + uOutlineColor * vColor.a * (outline - fill);
+
+	if (uGlowWidth > 0) {
+		float glow = 1 - smoothstep(0.0, uGlowWidth, -(d + uOutlineWidth));
+		col = over(col, uGlowColor * vColor.a * glow);
+	}
+
+	float ds = glyphDistance(vTexCoords - uShadowOffset) + uOutlineWidth;
+	float soft = aa + uShadowSoftness;
+	col = over(col, uShadowColor * vColor.a * smoothstep(-soft, soft, ds));
+
+	fragColor = ((1 - vIntensity) * vColor + vIntensity * col) * uColorMask;
+}
+`
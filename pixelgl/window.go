@@ -145,6 +145,7 @@ type Window struct {
 
 	bounds             pixel.Rect
 	canvas             *Canvas
+	stats              pixel.RenderStats
 	vsync              bool
 	cursorVisible      bool
 	cursorInsideWindow bool
@@ -244,7 +245,12 @@ func (w *Window) Destroy() {
 }
 
 // Update swaps buffers and polls events. Call this method at the end of each frame.
+//
+// Update also finishes collecting the RenderStats of the frame, see Stats.
 func (w *Window) Update() {
+	w.stats = w.canvas.Stats()
+	w.canvas.ResetStats()
+
 	mainthread.Call(func() {
 		_, _, oldW, oldH := intBounds(w.bounds)
 		newW, newH := w.window.GetSize()
@@ -512,6 +518,12 @@ func (w *Window) Color(at pixel.Vec) pixel.RGBA {
 	return w.canvas.Color(at)
 }
 
+// Stats returns the RenderStats of the last frame, i.e. the draws onto the Window between the last
+// two calls to Update. Use the Window's Canvas to get the RenderStats of the current frame so far.
+func (w *Window) Stats() pixel.RenderStats {
+	return w.stats
+}
+
 // Canvas returns the window's underlying Canvas
 func (w *Window) Canvas() *Canvas {
 	return w.canvas
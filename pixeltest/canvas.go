@@ -0,0 +1,287 @@
+package pixeltest
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"math"
+
+	"github.com/faiface/pixel"
+)
+
+// Canvas is a software rasterizer, which draws into a PictureData. It's a pixel.ComposeTarget and
+// a pixel.Picture at the same time.
+//
+// Canvas follows the same rules as pixelgl.Canvas: pixel centers are sampled, vertex properties
+// are interpolated linearly, Pictures are sampled with the nearest pixel (clamped to the edges)
+// and mixed with the vertex color according to the Intensity. Triangle edges follow the top-left
+// rule, so triangles sharing an edge never draw the same pixel twice.
+type Canvas struct {
+	pd  *pixel.PictureData
+	mat pixel.Matrix
+	col pixel.RGBA
+	cmp pixel.ComposeMethod
+}
+
+var (
+	_ pixel.ComposeTarget = (*Canvas)(nil)
+	_ pixel.PictureColor  = (*Canvas)(nil)
+)
+
+// NewCanvas creates a new fully transparent Canvas with the given bounds.
+func NewCanvas(bounds pixel.Rect) *Canvas {
+	return &Canvas{
+		pd:  pixel.MakePictureData(bounds),
+		mat: pixel.IM,
+		col: pixel.Alpha(1),
+	}
+}
+
+// Render draws onto a new fully transparent Canvas with the given bounds using the provided
+// function and returns the result as an image.
+func Render(bounds pixel.Rect, draw func(t pixel.Target)) *image.RGBA {
+	c := NewCanvas(bounds)
+	draw(c)
+	return c.Image()
+}
+
+// Bounds returns the bounds of the Canvas.
+func (c *Canvas) Bounds() pixel.Rect {
+	return c.pd.Rect
+}
+
+// Color returns the color of the pixel at the given position.
+func (c *Canvas) Color(at pixel.Vec) pixel.RGBA {
+	return c.pd.Color(at)
+}
+
+// PictureData returns the PictureData the Canvas draws into.
+func (c *Canvas) PictureData() *pixel.PictureData {
+	return c.pd
+}
+
+// Image returns the content of the Canvas as an image.
+func (c *Canvas) Image() *image.RGBA {
+	return c.pd.Image()
+}
+
+// Clear fills the whole Canvas with a single color.
+func (c *Canvas) Clear(col color.Color) {
+	rgba := color.RGBAModel.Convert(col).(color.RGBA)
+	for i := range c.pd.Pix {
+		c.pd.Pix[i] = rgba
+	}
+}
+
+// SetMatrix sets a Matrix that every point will be projected by.
+func (c *Canvas) SetMatrix(m pixel.Matrix) {
+	c.mat = m
+}
+
+// SetColorMask sets a color that every color in triangles or a picture will be multiplied by.
+func (c *Canvas) SetColorMask(col color.Color) {
+	c.col = pixel.Alpha(1)
+	if col != nil {
+		c.col = pixel.ToRGBA(col)
+	}
+}
+
+// SetComposeMethod sets a Porter-Duff composition method to be used in the following draws onto
+// this Canvas.
+func (c *Canvas) SetComposeMethod(cmp pixel.ComposeMethod) {
+	c.cmp = cmp
+}
+
+// MakeTriangles returns a specialized copy of the provided Triangles that draws onto this Canvas.
+func (c *Canvas) MakeTriangles(t pixel.Triangles) pixel.TargetTriangles {
+	ct := &canvasTriangles{
+		tri: pixel.MakeTrianglesData(t.Len()),
+		dst: c,
+	}
+	ct.tri.Update(t)
+	return ct
+}
+
+// MakePicture returns a specialized copy of the provided Picture that draws onto this Canvas.
+func (c *Canvas) MakePicture(p pixel.Picture) pixel.TargetPicture {
+	if cp, ok := p.(*canvasPicture); ok {
+		p = cp.pic
+	}
+	return &canvasPicture{
+		pic: p,
+		dst: c,
+	}
+}
+
+type vertex struct {
+	pos pixel.Vec
+	col pixel.RGBA
+	uv  pixel.Vec
+	in  float64
+}
+
+// edge returns the edge function of the point p relative to the line a->b, which is positive if p
+// lies to the left of it.
+func edge(a, b, p pixel.Vec) float64 {
+	return (b.X-a.X)*(p.Y-a.Y) - (b.Y-a.Y)*(p.X-a.X)
+}
+
+// topLeft reports whether the edge a->b of a counter-clockwise triangle is a top or a left edge.
+func topLeft(a, b pixel.Vec) bool {
+	d := b.Sub(a)
+	return (d.Y == 0 && d.X < 0) || d.Y < 0
+}
+
+func (c *Canvas) drawTriangle(v [3]vertex, pic pixel.Picture) {
+	area := edge(v[0].pos, v[1].pos, v[2].pos)
+	if area == 0 {
+		return
+	}
+	if area < 0 {
+		v[1], v[2] = v[2], v[1]
+		area = -area
+	}
+
+	// pixels whose centers lie inside the triangle, clipped to the Canvas
+	bounds := c.pd.Rect
+	minX := math.Max(math.Min(v[0].pos.X, math.Min(v[1].pos.X, v[2].pos.X)), bounds.Min.X)
+	minY := math.Max(math.Min(v[0].pos.Y, math.Min(v[1].pos.Y, v[2].pos.Y)), bounds.Min.Y)
+	maxX := math.Min(math.Max(v[0].pos.X, math.Max(v[1].pos.X, v[2].pos.X)), bounds.Max.X)
+	maxY := math.Min(math.Max(v[0].pos.Y, math.Max(v[1].pos.Y, v[2].pos.Y)), bounds.Max.Y)
+
+	tl := [3]bool{
+		topLeft(v[1].pos, v[2].pos),
+		topLeft(v[2].pos, v[0].pos),
+		topLeft(v[0].pos, v[1].pos),
+	}
+
+	for y := math.Floor(minY); y < maxY; y++ {
+		for x := math.Floor(minX); x < maxX; x++ {
+			p := pixel.V(x+0.5, y+0.5)
+			if !bounds.Contains(p) {
+				continue
+			}
+			w := [3]float64{
+				edge(v[1].pos, v[2].pos, p),
+				edge(v[2].pos, v[0].pos, p),
+				edge(v[0].pos, v[1].pos, p),
+			}
+			inside := true
+			for i := range w {
+				if w[i] < 0 || (w[i] == 0 && !tl[i]) {
+					inside = false
+					break
+				}
+			}
+			if !inside {
+				continue
+			}
+
+			b0, b1, b2 := w[0]/area, w[1]/area, w[2]/area
+			col := v[0].col.Scaled(b0).Add(v[1].col.Scaled(b1)).Add(v[2].col.Scaled(b2))
+			if pic != nil {
+				in := v[0].in*b0 + v[1].in*b1 + v[2].in*b2
+				if in != 0 {
+					uv := v[0].uv.Scaled(b0).Add(v[1].uv.Scaled(b1)).Add(v[2].uv.Scaled(b2))
+					tex := sample(pic, uv)
+					col = col.Scaled(1 - in).Add(col.Mul(tex).Scaled(in))
+				}
+			}
+			col = col.Mul(c.col)
+
+			i := c.pd.Index(p)
+			dst := pixel.ToRGBA(c.pd.Pix[i])
+			c.pd.Pix[i] = toColor(c.cmp.Compose(col, dst))
+		}
+	}
+}
+
+// sample returns the color of the Picture's pixel nearest to the point, clamped to the edges of
+// the Picture. Pictures not implementing PictureColor are white.
+func sample(pic pixel.Picture, at pixel.Vec) pixel.RGBA {
+	pc, ok := pic.(pixel.PictureColor)
+	if !ok {
+		return pixel.Alpha(1)
+	}
+	b := pic.Bounds()
+	at.X = pixel.Clamp(math.Floor(at.X), math.Floor(b.Min.X), math.Ceil(b.Max.X)-1) + 0.5
+	at.Y = pixel.Clamp(math.Floor(at.Y), math.Floor(b.Min.Y), math.Ceil(b.Max.Y)-1) + 0.5
+	at.X = pixel.Clamp(at.X, b.Min.X, b.Max.X)
+	at.Y = pixel.Clamp(at.Y, b.Min.Y, b.Max.Y)
+	return pc.Color(at)
+}
+
+func toColor(c pixel.RGBA) color.RGBA {
+	to8 := func(x float64) uint8 {
+		return uint8(math.Round(pixel.Clamp(x, 0, 1) * 255))
+	}
+	return color.RGBA{R: to8(c.R), G: to8(c.G), B: to8(c.B), A: to8(c.A)}
+}
+
+type canvasTriangles struct {
+	tri *pixel.TrianglesData
+	dst *Canvas
+}
+
+func (ct *canvasTriangles) Len() int {
+	return ct.tri.Len()
+}
+
+func (ct *canvasTriangles) SetLen(len int) {
+	ct.tri.SetLen(len)
+}
+
+func (ct *canvasTriangles) Slice(i, j int) pixel.Triangles {
+	return &canvasTriangles{
+		tri: ct.tri.Slice(i, j).(*pixel.TrianglesData),
+		dst: ct.dst,
+	}
+}
+
+func (ct *canvasTriangles) Update(t pixel.Triangles) {
+	ct.tri.Update(t)
+}
+
+func (ct *canvasTriangles) Copy() pixel.Triangles {
+	return &canvasTriangles{
+		tri: ct.tri.Copy().(*pixel.TrianglesData),
+		dst: ct.dst,
+	}
+}
+
+func (ct *canvasTriangles) draw(pic pixel.Picture) {
+	tri := *ct.tri
+	for i := 0; i+2 < len(tri); i += 3 {
+		var v [3]vertex
+		for k := range v {
+			v[k] = vertex{
+				pos: ct.dst.mat.Project(tri[i+k].Position),
+				col: tri[i+k].Color,
+				uv:  tri[i+k].Picture,
+				in:  tri[i+k].Intensity,
+			}
+		}
+		ct.dst.drawTriangle(v, pic)
+	}
+}
+
+func (ct *canvasTriangles) Draw() {
+	ct.draw(nil)
+}
+
+type canvasPicture struct {
+	pic pixel.Picture
+	dst *Canvas
+}
+
+func (cp *canvasPicture) Bounds() pixel.Rect {
+	return cp.pic.Bounds()
+}
+
+func (cp *canvasPicture) Draw(t pixel.TargetTriangles) {
+	ct := t.(*canvasTriangles)
+	if ct.dst != cp.dst {
+		panic(fmt.Errorf("(%T).Draw: TargetTriangles generated by different Canvas", cp))
+	}
+	ct.draw(cp.pic)
+}
@@ -0,0 +1,123 @@
+package pixeltest
+
+import (
+	"image"
+	"image/color"
+	"math"
+
+	"github.com/pkg/errors"
+)
+
+// Options control how images are compared.
+//
+// A pixel is considered different if any of it's channels differs by more than Tolerance, unless a
+// Threshold is set and neither the alpha nor the perceptual difference of the colors exceed it. The
+// zero value of Options requires the images to be exactly the same.
+type Options struct {
+	// Tolerance is the maximal difference of a single channel (0-255) of matching pixels.
+	Tolerance uint8
+
+	// Threshold is the maximal perceptual difference of matching pixels, from 0 (exactly the same
+	// colors) to 1 (black and white). It's measured in the YIQ color space, which is close to how
+	// humans perceive colors, with transparent colors blended over white, after the alpha of the
+	// pixels is compared on it's own. Around 0.1 is a good value for ignoring anti-aliasing
+	// differences. If zero, only the Tolerance is used.
+	Threshold float64
+
+	// MaxDiffPixels is the number of different pixels allowed.
+	MaxDiffPixels int
+}
+
+// Result is the result of comparing two images.
+type Result struct {
+	// DiffPixels is the number of different pixels.
+	DiffPixels int
+
+	// Diff is an image highlighting the different pixels in red over a faded version of the
+	// expected image.
+	Diff *image.RGBA
+}
+
+// Compare compares two images according to the Options. An error is returned if the images
+// differ in size or if more than MaxDiffPixels pixels are different.
+//
+// The Result is returned even if the images differ, unless they differ in size.
+func Compare(got, want image.Image, opts Options) (Result, error) {
+	gb, wb := got.Bounds(), want.Bounds()
+	if gb.Size() != wb.Size() {
+		return Result{}, errors.Errorf("image size %v, want %v", gb.Size(), wb.Size())
+	}
+
+	res := Result{Diff: image.NewRGBA(image.Rect(0, 0, wb.Dx(), wb.Dy()))}
+	maxDelta := opts.Threshold * opts.Threshold * maxYIQDelta
+	maxAlphaDelta := opts.Threshold * 255
+
+	for y := 0; y < wb.Dy(); y++ {
+		for x := 0; x < wb.Dx(); x++ {
+			g := color.RGBAModel.Convert(got.At(gb.Min.X+x, gb.Min.Y+y)).(color.RGBA)
+			w := color.RGBAModel.Convert(want.At(wb.Min.X+x, wb.Min.Y+y)).(color.RGBA)
+
+			different := channelDelta(g, w) > opts.Tolerance
+			if different && opts.Threshold > 0 {
+				// blending over white hides differences in alpha, so they are compared first
+				alphaDelta := math.Abs(float64(g.A) - float64(w.A))
+				different = alphaDelta > maxAlphaDelta || yiqDelta(g, w) > maxDelta
+			}
+			if different {
+				res.DiffPixels++
+				res.Diff.SetRGBA(x, y, color.RGBA{R: 255, A: 255})
+				continue
+			}
+
+			// faded grayscale of the expected image
+			gray := uint8(255 - (255-luma(w))/4)
+			res.Diff.SetRGBA(x, y, color.RGBA{R: gray, G: gray, B: gray, A: 255})
+		}
+	}
+
+	if res.DiffPixels > opts.MaxDiffPixels {
+		return res, errors.Errorf("%d pixels differ, at most %d allowed", res.DiffPixels, opts.MaxDiffPixels)
+	}
+	return res, nil
+}
+
+func channelDelta(a, b color.RGBA) uint8 {
+	abs := func(x, y uint8) uint8 {
+		if x > y {
+			return x - y
+		}
+		return y - x
+	}
+	d := abs(a.R, b.R)
+	for _, e := range [...]uint8{abs(a.G, b.G), abs(a.B, b.B), abs(a.A, b.A)} {
+		if e > d {
+			d = e
+		}
+	}
+	return d
+}
+
+// maxYIQDelta is the YIQ difference of black and white.
+const maxYIQDelta = 35215
+
+// blend blends a premultiplied color over white.
+func blend(c color.RGBA) (r, g, b float64) {
+	white := 255 - float64(c.A)
+	return float64(c.R) + white, float64(c.G) + white, float64(c.B) + white
+}
+
+// yiqDelta returns the squared perceptual difference of the colors in the YIQ color space.
+func yiqDelta(a, b color.RGBA) float64 {
+	r1, g1, b1 := blend(a)
+	r2, g2, b2 := blend(b)
+	dr, dg, db := r1-r2, g1-g2, b1-b2
+	y := dr*0.29889531 + dg*0.58662247 + db*0.11448223
+	i := dr*0.59597799 - dg*0.27417610 - db*0.32180189
+	q := dr*0.21147017 - dg*0.52261711 + db*0.31114694
+	return 0.5053*y*y + 0.299*i*i + 0.1957*q*q
+}
+
+func luma(c color.RGBA) uint8 {
+	r, g, b := blend(c)
+	return uint8(math.Round(r*0.29889531 + g*0.58662247 + b*0.11448223))
+}
@@ -0,0 +1,19 @@
+// Package pixeltest implements utilities for testing rendering code.
+//
+// Canvas is a software rasterizer implementing pixel.ComposeTarget, which draws into a
+// PictureData without any GPU. Compare compares the rendered images against expected ones with a
+// per-channel tolerance and a perceptual threshold and AssertGolden compares them against golden
+// PNG files stored alongside the tests:
+//
+//   func TestCircle(t *testing.T) {
+//       img := pixeltest.Render(pixel.R(0, 0, 64, 64), func(target pixel.Target) {
+//           imd := imdraw.New(nil)
+//           imd.Push(pixel.V(32, 32))
+//           imd.Circle(16, 0)
+//           imd.Draw(target)
+//       })
+//       pixeltest.AssertGolden(t, "testdata/circle.png", img, pixeltest.Options{})
+//   }
+//
+// Run the tests with the -pixeltest.update flag to create or update the golden files.
+package pixeltest
@@ -0,0 +1,84 @@
+package pixeltest
+
+import (
+	"flag"
+	"image"
+	"image/png"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/pkg/errors"
+)
+
+var update = flag.Bool("pixeltest.update", false, "update golden images instead of comparing against them")
+
+// AssertGolden compares the image against the golden PNG file at the path and fails the test if
+// they differ (see Compare).
+//
+// On failure, the rendered image and the diff image are written next to the golden file, with
+// the suffixes .got.png and .diff.png.
+//
+// If the tests are run with the -pixeltest.update flag, the golden file is created or overwritten
+// with the image instead.
+func AssertGolden(t testing.TB, path string, img image.Image, opts Options) {
+	t.Helper()
+
+	if *update {
+		if err := writePNG(path, img); err != nil {
+			t.Fatal(err)
+		}
+		return
+	}
+
+	want, err := readPNG(path)
+	if err != nil {
+		t.Fatalf("%v (run with -pixeltest.update to create it)", err)
+	}
+
+	res, err := Compare(img, want, opts)
+	if err == nil {
+		return
+	}
+
+	base := strings.TrimSuffix(path, filepath.Ext(path))
+	if err := writePNG(base+".got.png", img); err != nil {
+		t.Error(err)
+	}
+	if res.Diff != nil {
+		if err := writePNG(base+".diff.png", res.Diff); err != nil {
+			t.Error(err)
+		}
+	}
+	t.Fatalf("%s: %v", path, err)
+}
+
+func readPNG(path string) (image.Image, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	img, err := png.Decode(f)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to decode %s", path)
+	}
+	return img, nil
+}
+
+func writePNG(path string, img image.Image) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := png.Encode(f, img); err != nil {
+		f.Close()
+		return errors.Wrapf(err, "failed to encode %s", path)
+	}
+	return f.Close()
+}
@@ -0,0 +1,116 @@
+package pixeltest_test
+
+import (
+	"flag"
+	"image"
+	"image/color"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/faiface/pixel"
+	"github.com/faiface/pixel/imdraw"
+	"github.com/faiface/pixel/pixeltest"
+)
+
+func TestCanvas_Sprite(t *testing.T) {
+	pic := pixel.MakePictureData(pixel.R(0, 0, 2, 2))
+	for i := range pic.Pix {
+		pic.Pix[i] = color.RGBA{R: uint8(i * 60), G: 255, A: 255}
+	}
+
+	c := pixeltest.NewCanvas(pixel.R(0, 0, 4, 4))
+	c.Clear(color.RGBA{B: 255, A: 255})
+	pixel.NewSprite(pic, pic.Bounds()).Draw(c, pixel.IM.Moved(pixel.V(2, 2)))
+
+	for y := 0.0; y < 4; y++ {
+		for x := 0.0; x < 4; x++ {
+			at := pixel.V(x+0.5, y+0.5)
+			want := pixel.RGB(0, 0, 1)
+			if pic.Bounds().Moved(pixel.V(1, 1)).Contains(at) {
+				want = pic.Color(at.Sub(pixel.V(1, 1)))
+			}
+			if got := c.Color(at); got != want {
+				t.Errorf("pixel %v = %v, want %v", at, got, want)
+			}
+		}
+	}
+}
+
+func TestCanvas_SharedEdges(t *testing.T) {
+	// overlapping pixels of the two triangles would be more opaque
+	img := pixeltest.Render(pixel.R(0, 0, 16, 16), func(target pixel.Target) {
+		imd := imdraw.New(nil)
+		imd.Color = pixel.Alpha(0.5)
+		imd.Push(pixel.V(0, 0), pixel.V(16, 16))
+		imd.Rectangle(0)
+		imd.Push(pixel.V(0, 0), pixel.V(16, 0), pixel.V(16, 16), pixel.V(0, 16))
+		imd.Draw(target)
+	})
+	for y := 0; y < 16; y++ {
+		for x := 0; x < 16; x++ {
+			if got := img.RGBAAt(x, y).A; got != 128 {
+				t.Fatalf("pixel (%d, %d) has alpha %d, want 128", x, y, got)
+			}
+		}
+	}
+}
+
+func TestCompare(t *testing.T) {
+	a := image.NewRGBA(image.Rect(0, 0, 4, 4))
+	b := image.NewRGBA(image.Rect(0, 0, 4, 4))
+	b.SetRGBA(1, 1, color.RGBA{R: 3, A: 3})
+	b.SetRGBA(2, 2, color.RGBA{R: 255, A: 255})
+	// transparent over white looks like white
+	a.SetRGBA(3, 3, color.RGBA{R: 255, G: 255, B: 255, A: 255})
+
+	tests := []struct {
+		name string
+		opts pixeltest.Options
+		diff int
+		ok   bool
+	}{
+		{"Exact", pixeltest.Options{}, 3, false},
+		{"Tolerance", pixeltest.Options{Tolerance: 3}, 2, false},
+		{"Threshold", pixeltest.Options{Threshold: 0.1}, 2, false},
+		{"MaxDiffPixels", pixeltest.Options{Tolerance: 3, MaxDiffPixels: 2}, 2, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			res, err := pixeltest.Compare(b, a, tt.opts)
+			if res.DiffPixels != tt.diff || (err == nil) != tt.ok {
+				t.Errorf("DiffPixels = %d, err = %v, want %d, ok = %v", res.DiffPixels, err, tt.diff, tt.ok)
+			}
+		})
+	}
+
+	if _, err := pixeltest.Compare(a, image.NewRGBA(image.Rect(0, 0, 4, 5)), pixeltest.Options{}); err == nil {
+		t.Errorf("compared images of different sizes")
+	}
+}
+
+func TestAssertGolden(t *testing.T) {
+	dir, err := ioutil.TempDir("", "pixeltest")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "golden.png")
+
+	img := image.NewRGBA(image.Rect(0, 0, 2, 2))
+	img.SetRGBA(0, 0, color.RGBA{G: 255, A: 255})
+
+	if err := flag.Set("pixeltest.update", "true"); err != nil {
+		t.Fatal(err)
+	}
+	pixeltest.AssertGolden(t, path, img, pixeltest.Options{})
+	if err := flag.Set("pixeltest.update", "false"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(path); err != nil {
+		t.Fatalf("golden file not written: %v", err)
+	}
+
+	pixeltest.AssertGolden(t, path, img, pixeltest.Options{})
+}
@@ -0,0 +1,429 @@
+package pixel
+
+import (
+	"encoding/gob"
+	"fmt"
+	"image/color"
+	"io"
+
+	"github.com/pkg/errors"
+)
+
+// CommandKind is the kind of a Command recorded by a Recorder.
+type CommandKind int
+
+// Here's the list of all kinds of Commands.
+const (
+	// MakeTrianglesCommand creates Triangles with ID Triangles from the Data.
+	MakeTrianglesCommand CommandKind = iota
+
+	// UpdateTrianglesCommand sets the content of the Triangles with ID Triangles to the Data.
+	UpdateTrianglesCommand
+
+	// MakePictureCommand creates a Picture with ID Picture from the Picture.
+	MakePictureCommand
+
+	// DrawCommand draws Len vertices starting at Offset of the Triangles with ID Triangles with
+	// the Picture with ID Picture, or without a Picture if the Picture ID is negative.
+	DrawCommand
+
+	// SetMatrixCommand sets the Matrix of the Target.
+	SetMatrixCommand
+
+	// SetColorMaskCommand sets the color mask of the Target.
+	SetColorMaskCommand
+
+	// SetComposeMethodCommand sets the compose method of the Target.
+	SetComposeMethodCommand
+)
+
+func (ck CommandKind) String() string {
+	switch ck {
+	case MakeTrianglesCommand:
+		return "MakeTriangles"
+	case UpdateTrianglesCommand:
+		return "UpdateTriangles"
+	case MakePictureCommand:
+		return "MakePicture"
+	case DrawCommand:
+		return "Draw"
+	case SetMatrixCommand:
+		return "SetMatrix"
+	case SetColorMaskCommand:
+		return "SetColorMask"
+	case SetComposeMethodCommand:
+		return "SetComposeMethod"
+	default:
+		return fmt.Sprintf("CommandKind(%d)", int(ck))
+	}
+}
+
+// Command is a single call recorded by a Recorder. Only the fields relevant to the Kind are set.
+type Command struct {
+	Kind CommandKind
+
+	// Triangles and Picture are IDs of the Triangles and Picture the Command refers to.
+	Triangles int
+	Picture   int
+
+	// Offset and Len are the range of vertices of the Triangles drawn by a Draw Command.
+	Offset int
+	Len    int
+
+	Data          *TrianglesData
+	Pic           Picture
+	Matrix        Matrix
+	ColorMask     RGBA
+	ComposeMethod ComposeMethod
+}
+
+// String returns a short description of the Command, useful for debugging.
+func (c Command) String() string {
+	switch c.Kind {
+	case MakeTrianglesCommand, UpdateTrianglesCommand:
+		return fmt.Sprintf("%v(#%d, %d vertices)", c.Kind, c.Triangles, c.Data.Len())
+	case MakePictureCommand:
+		return fmt.Sprintf("%v(#%d, %v)", c.Kind, c.Picture, c.Pic.Bounds())
+	case DrawCommand:
+		if c.Picture < 0 {
+			return fmt.Sprintf("%v(#%d[%d:%d])", c.Kind, c.Triangles, c.Offset, c.Offset+c.Len)
+		}
+		return fmt.Sprintf("%v(#%d[%d:%d], #%d)", c.Kind, c.Triangles, c.Offset, c.Offset+c.Len, c.Picture)
+	case SetMatrixCommand:
+		return fmt.Sprintf("%v(%v)", c.Kind, c.Matrix)
+	case SetColorMaskCommand:
+		return fmt.Sprintf("%v(%v)", c.Kind, c.ColorMask)
+	case SetComposeMethodCommand:
+		return fmt.Sprintf("%v(%d)", c.Kind, c.ComposeMethod)
+	default:
+		return c.Kind.String()
+	}
+}
+
+// Recorder is a ComposeTarget, which doesn't draw anything, but records all calls made to it into
+// a list of Commands. The Commands can be inspected, replayed onto another Target and serialized.
+//
+// Recorder is useful for asserting what gets drawn in tests, debugging, or drawing from another
+// goroutine and replaying the result in the main thread:
+//
+//   rec := pixel.NewRecorder()
+//   sprite.Draw(rec, pixel.IM.Moved(pos))
+//   for _, cmd := range rec.Commands() {
+//       fmt.Println(cmd)
+//   }
+//   rec.Replay(win)
+//
+// The content of the Triangles is recorded lazily: a single UpdateTriangles Command is recorded
+// right before a Draw if the Triangles changed since they were last drawn.
+//
+// A Recorder is not safe for concurrent use.
+type Recorder struct {
+	cmds    []Command
+	nextTri int
+	nextPic int
+	targets map[Target]*replayTarget
+}
+
+// NewRecorder creates a new empty Recorder.
+func NewRecorder() *Recorder {
+	return &Recorder{
+		targets: make(map[Target]*replayTarget),
+	}
+}
+
+// Commands returns the recorded Commands. Do not modify the returned slice.
+func (r *Recorder) Commands() []Command {
+	return r.cmds
+}
+
+// Clear removes all recorded Commands.
+//
+// IDs of the Triangles and Pictures made before remain valid, so Triangles and Pictures cached by
+// Drawers keep working. Replaying the following Commands onto a Target which the cleared Commands
+// were replayed onto before works as expected.
+func (r *Recorder) Clear() {
+	r.cmds = r.cmds[:0]
+}
+
+func (r *Recorder) record(cmd Command) {
+	r.cmds = append(r.cmds, cmd)
+}
+
+// SetMatrix records a SetMatrix Command.
+func (r *Recorder) SetMatrix(m Matrix) {
+	r.record(Command{Kind: SetMatrixCommand, Matrix: m})
+}
+
+// SetColorMask records a SetColorMask Command. A nil color is recorded as white.
+func (r *Recorder) SetColorMask(c color.Color) {
+	mask := Alpha(1)
+	if c != nil {
+		mask = ToRGBA(c)
+	}
+	r.record(Command{Kind: SetColorMaskCommand, ColorMask: mask})
+}
+
+// SetComposeMethod records a SetComposeMethod Command.
+func (r *Recorder) SetComposeMethod(cm ComposeMethod) {
+	r.record(Command{Kind: SetComposeMethodCommand, ComposeMethod: cm})
+}
+
+// MakeTriangles records a MakeTriangles Command and returns Triangles that record a Draw Command
+// when drawn.
+func (r *Recorder) MakeTriangles(t Triangles) TargetTriangles {
+	rt := &recorderTriangles{
+		tri: MakeTrianglesData(t.Len()),
+		id:  r.nextTri,
+		dst: r,
+	}
+	rt.root = rt
+	rt.tri.Update(t)
+	r.nextTri++
+	r.record(Command{Kind: MakeTrianglesCommand, Triangles: rt.id, Data: rt.tri.Copy().(*TrianglesData)})
+	return rt
+}
+
+// MakePicture records a MakePicture Command and returns a Picture that records a Draw Command
+// when drawn with.
+func (r *Recorder) MakePicture(p Picture) TargetPicture {
+	if rp, ok := p.(*recorderPicture); ok && rp.dst == r {
+		return rp
+	}
+	rp := &recorderPicture{
+		pic: p,
+		id:  r.nextPic,
+		dst: r,
+	}
+	r.nextPic++
+	r.record(Command{Kind: MakePictureCommand, Picture: rp.id, Pic: p})
+	return rp
+}
+
+// recorderTriangles are Triangles made by a Recorder, or a Slice of them. Slices share the ID of
+// the Triangles they were sliced from and are drawn as a range of it's vertices.
+type recorderTriangles struct {
+	tri    *TrianglesData
+	root   *recorderTriangles
+	offset int
+	id     int
+	dst    *Recorder
+	dirty  bool
+}
+
+func (rt *recorderTriangles) Len() int {
+	return rt.tri.Len()
+}
+
+func (rt *recorderTriangles) SetLen(len int) {
+	rt.tri.SetLen(len)
+	rt.root.dirty = true
+}
+
+func (rt *recorderTriangles) Slice(i, j int) Triangles {
+	return &recorderTriangles{
+		tri:    rt.tri.Slice(i, j).(*TrianglesData),
+		root:   rt.root,
+		offset: rt.offset + i,
+		id:     rt.id,
+		dst:    rt.dst,
+	}
+}
+
+func (rt *recorderTriangles) Update(t Triangles) {
+	rt.tri.Update(t)
+	rt.root.dirty = true
+}
+
+func (rt *recorderTriangles) Copy() Triangles {
+	return rt.dst.MakeTriangles(rt.tri)
+}
+
+func (rt *recorderTriangles) draw(pic int) {
+	if root := rt.root; root.dirty {
+		rt.dst.record(Command{Kind: UpdateTrianglesCommand, Triangles: rt.id, Data: root.tri.Copy().(*TrianglesData)})
+		root.dirty = false
+	}
+	rt.dst.record(Command{
+		Kind:      DrawCommand,
+		Triangles: rt.id,
+		Picture:   pic,
+		Offset:    rt.offset,
+		Len:       rt.tri.Len(),
+	})
+}
+
+func (rt *recorderTriangles) Draw() {
+	rt.draw(-1)
+}
+
+type recorderPicture struct {
+	pic Picture
+	id  int
+	dst *Recorder
+}
+
+func (rp *recorderPicture) Bounds() Rect {
+	return rp.pic.Bounds()
+}
+
+func (rp *recorderPicture) Draw(t TargetTriangles) {
+	rt := t.(*recorderTriangles)
+	if rt.dst != rp.dst {
+		panic(fmt.Errorf("(%T).Draw: TargetTriangles generated by different Recorder", rp))
+	}
+	rt.draw(rp.id)
+}
+
+type replayTarget struct {
+	tris map[int]TargetTriangles
+	pics map[int]TargetPicture
+}
+
+// Replay replays the recorded Commands onto the Target.
+//
+// SetMatrix and SetColorMask Commands are skipped if the Target is not a BasicTarget,
+// SetComposeMethod Commands are skipped if it's not a ComposeTarget.
+//
+// The Triangles and Pictures made on the Target are cached per Target, so replaying the Commands
+// recorded after Clear onto the same Target doesn't have to make them again.
+//
+// Replay panics if a Command refers to Triangles or a Picture that was never made on the Target.
+func (r *Recorder) Replay(t Target) {
+	rt := r.targets[t]
+	if rt == nil {
+		rt = &replayTarget{
+			tris: make(map[int]TargetTriangles),
+			pics: make(map[int]TargetPicture),
+		}
+		r.targets[t] = rt
+	}
+
+	for i, cmd := range r.cmds {
+		switch cmd.Kind {
+		case MakeTrianglesCommand:
+			rt.tris[cmd.Triangles] = t.MakeTriangles(cmd.Data)
+		case UpdateTrianglesCommand:
+			tri := rt.tris[cmd.Triangles]
+			if tri == nil {
+				panic(fmt.Errorf("(%T).Replay: command %d: unknown Triangles #%d", r, i, cmd.Triangles))
+			}
+			tri.SetLen(cmd.Data.Len())
+			tri.Update(cmd.Data)
+		case MakePictureCommand:
+			rt.pics[cmd.Picture] = t.MakePicture(cmd.Pic)
+		case DrawCommand:
+			tri := rt.tris[cmd.Triangles]
+			if tri == nil {
+				panic(fmt.Errorf("(%T).Replay: command %d: unknown Triangles #%d", r, i, cmd.Triangles))
+			}
+			if cmd.Offset != 0 || cmd.Len != tri.Len() {
+				tri = tri.Slice(cmd.Offset, cmd.Offset+cmd.Len).(TargetTriangles)
+			}
+			if cmd.Picture < 0 {
+				tri.Draw()
+				continue
+			}
+			pic := rt.pics[cmd.Picture]
+			if pic == nil {
+				panic(fmt.Errorf("(%T).Replay: command %d: unknown Picture #%d", r, i, cmd.Picture))
+			}
+			pic.Draw(tri)
+		case SetMatrixCommand:
+			if bt, ok := t.(BasicTarget); ok {
+				bt.SetMatrix(cmd.Matrix)
+			}
+		case SetColorMaskCommand:
+			if bt, ok := t.(BasicTarget); ok {
+				bt.SetColorMask(cmd.ColorMask)
+			}
+		case SetComposeMethodCommand:
+			if ct, ok := t.(ComposeTarget); ok {
+				ct.SetComposeMethod(cmd.ComposeMethod)
+			}
+		}
+	}
+}
+
+// gobCommand is the serialized form of a Command. Pictures are converted to PictureData.
+type gobCommand struct {
+	Kind          CommandKind
+	Triangles     int
+	Picture       int
+	Offset        int
+	Len           int
+	Data          *TrianglesData
+	Pic           *PictureData
+	Matrix        Matrix
+	ColorMask     RGBA
+	ComposeMethod ComposeMethod
+}
+
+// gobRecording is the serialized form of a Recorder.
+type gobRecording struct {
+	Commands []gobCommand
+	NextTri  int
+	NextPic  int
+}
+
+// Encode serializes the recorded Commands into the Writer using encoding/gob.
+//
+// All Pictures are converted to PictureData, which may be lossy (see PictureDataFromPicture).
+func (r *Recorder) Encode(w io.Writer) error {
+	rec := gobRecording{
+		Commands: make([]gobCommand, len(r.cmds)),
+		NextTri:  r.nextTri,
+		NextPic:  r.nextPic,
+	}
+	for i, cmd := range r.cmds {
+		gc := gobCommand{
+			Kind:          cmd.Kind,
+			Triangles:     cmd.Triangles,
+			Picture:       cmd.Picture,
+			Offset:        cmd.Offset,
+			Len:           cmd.Len,
+			Data:          cmd.Data,
+			Matrix:        cmd.Matrix,
+			ColorMask:     cmd.ColorMask,
+			ComposeMethod: cmd.ComposeMethod,
+		}
+		if cmd.Pic != nil {
+			gc.Pic = PictureDataFromPicture(cmd.Pic)
+		}
+		rec.Commands[i] = gc
+	}
+	return errors.Wrap(gob.NewEncoder(w).Encode(&rec), "failed to encode recording")
+}
+
+// DecodeRecorder decodes a Recorder previously serialized using Encode.
+func DecodeRecorder(rd io.Reader) (*Recorder, error) {
+	var rec gobRecording
+	if err := gob.NewDecoder(rd).Decode(&rec); err != nil {
+		return nil, errors.Wrap(err, "failed to decode recording")
+	}
+	r := NewRecorder()
+	r.nextTri = rec.NextTri
+	r.nextPic = rec.NextPic
+	r.cmds = make([]Command, len(rec.Commands))
+	for i, gc := range rec.Commands {
+		cmd := Command{
+			Kind:          gc.Kind,
+			Triangles:     gc.Triangles,
+			Picture:       gc.Picture,
+			Offset:        gc.Offset,
+			Len:           gc.Len,
+			Data:          gc.Data,
+			Matrix:        gc.Matrix,
+			ColorMask:     gc.ColorMask,
+			ComposeMethod: gc.ComposeMethod,
+		}
+		if gc.Pic != nil {
+			cmd.Pic = gc.Pic
+		}
+		// gob doesn't transmit empty slices
+		if cmd.Data == nil && (cmd.Kind == MakeTrianglesCommand || cmd.Kind == UpdateTrianglesCommand) {
+			cmd.Data = &TrianglesData{}
+		}
+		r.cmds[i] = cmd
+	}
+	return r, nil
+}
@@ -0,0 +1,147 @@
+package pixel_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/faiface/pixel"
+)
+
+func commandKinds(cmds []pixel.Command) []pixel.CommandKind {
+	kinds := make([]pixel.CommandKind, len(cmds))
+	for i := range cmds {
+		kinds[i] = cmds[i].Kind
+	}
+	return kinds
+}
+
+func TestRecorder(t *testing.T) {
+	pic := pixel.MakePictureData(pixel.R(0, 0, 10, 10))
+	sprite := pixel.NewSprite(pic, pic.Bounds())
+
+	rec := pixel.NewRecorder()
+	rec.SetComposeMethod(pixel.ComposePlus)
+	sprite.Draw(rec, pixel.IM.Moved(pixel.V(5, 5)))
+	sprite.Draw(rec, pixel.IM.Moved(pixel.V(5, 5)))
+	sprite.Draw(rec, pixel.IM.Moved(pixel.V(50, 50)))
+
+	want := []pixel.CommandKind{
+		pixel.SetComposeMethodCommand,
+		pixel.MakeTrianglesCommand,
+		pixel.MakePictureCommand,
+		pixel.DrawCommand,
+		pixel.DrawCommand,
+		pixel.UpdateTrianglesCommand,
+		pixel.DrawCommand,
+	}
+	got := commandKinds(rec.Commands())
+	if len(got) != len(want) {
+		t.Fatalf("got commands %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("got commands %v, want %v", got, want)
+		}
+	}
+
+	if pos := (*rec.Commands()[5].Data)[0].Position; pos != pixel.V(45, 45) {
+		t.Errorf("updated triangles start at %v, want %v", pos, pixel.V(45, 45))
+	}
+	if cmd := rec.Commands()[6]; cmd.Triangles != 0 || cmd.Picture != 0 {
+		t.Errorf("draw command refers to #%d, #%d", cmd.Triangles, cmd.Picture)
+	}
+
+	// replaying onto a Batch draws the same as drawing onto it directly
+	replayed, direct := &pixel.TrianglesData{}, &pixel.TrianglesData{}
+	rec.Replay(pixel.NewBatch(replayed, pic))
+	batch := pixel.NewBatch(direct, pic)
+	sprite.Draw(batch, pixel.IM.Moved(pixel.V(5, 5)))
+	sprite.Draw(batch, pixel.IM.Moved(pixel.V(5, 5)))
+	sprite.Draw(batch, pixel.IM.Moved(pixel.V(50, 50)))
+	if replayed.Len() != direct.Len() {
+		t.Fatalf("replayed %d vertices, want %d", replayed.Len(), direct.Len())
+	}
+	for i := range *direct {
+		if (*replayed)[i] != (*direct)[i] {
+			t.Fatalf("vertex %d: replayed %v, want %v", i, (*replayed)[i], (*direct)[i])
+		}
+	}
+}
+
+func TestRecorder_Slice(t *testing.T) {
+	tri := pixel.MakeTrianglesData(6)
+	for i := range *tri {
+		(*tri)[i].Position = pixel.V(float64(i), 0)
+		(*tri)[i].Color = pixel.Alpha(1)
+	}
+
+	rec := pixel.NewRecorder()
+	rt := rec.MakeTriangles(tri)
+	s := rt.Slice(3, 6)
+	moved := pixel.MakeTrianglesData(3)
+	for i := range *moved {
+		(*moved)[i].Position = pixel.V(float64(i), 10)
+		(*moved)[i].Color = pixel.Alpha(1)
+	}
+	s.Update(moved)
+	s.(pixel.TargetTriangles).Draw()
+
+	want := []pixel.CommandKind{
+		pixel.MakeTrianglesCommand,
+		pixel.UpdateTrianglesCommand,
+		pixel.DrawCommand,
+	}
+	got := commandKinds(rec.Commands())
+	if len(got) != len(want) {
+		t.Fatalf("got commands %v, want %v", got, want)
+	}
+	if pos := (*rec.Commands()[1].Data)[3].Position; pos != pixel.V(0, 10) {
+		t.Errorf("updated vertex 3 at %v, want %v", pos, pixel.V(0, 10))
+	}
+
+	replayed := &pixel.TrianglesData{}
+	rec.Replay(pixel.NewBatch(replayed, nil))
+	if replayed.Len() != moved.Len() {
+		t.Fatalf("replayed %d vertices, want %d", replayed.Len(), moved.Len())
+	}
+	for i := range *moved {
+		if (*replayed)[i].Position != (*moved)[i].Position {
+			t.Fatalf("vertex %d: replayed at %v, want %v", i, (*replayed)[i].Position, (*moved)[i].Position)
+		}
+	}
+}
+
+func TestRecorder_Encode(t *testing.T) {
+	pic := pixel.MakePictureData(pixel.R(0, 0, 2, 2))
+	pic.Pix[3].R = 255
+
+	rec := pixel.NewRecorder()
+	rec.SetMatrix(pixel.IM.Scaled(pixel.ZV, 2))
+	rec.SetColorMask(pixel.RGB(1, 0, 0))
+	pixel.NewSprite(pic, pic.Bounds()).Draw(rec, pixel.IM)
+
+	var buf bytes.Buffer
+	if err := rec.Encode(&buf); err != nil {
+		t.Fatal(err)
+	}
+	dec, err := pixel.DecodeRecorder(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(dec.Commands()) != len(rec.Commands()) {
+		t.Fatalf("decoded %d commands, want %d", len(dec.Commands()), len(rec.Commands()))
+	}
+	for i, cmd := range rec.Commands() {
+		if got := dec.Commands()[i].String(); got != cmd.String() {
+			t.Errorf("command %d: decoded %s, want %s", i, got, cmd)
+		}
+	}
+	if got := dec.Commands()[3].Pic.(*pixel.PictureData).Pix[3].R; got != 255 {
+		t.Errorf("decoded picture lost it's pixels")
+	}
+
+	if _, err := pixel.DecodeRecorder(bytes.NewReader([]byte("garbage"))); err == nil {
+		t.Errorf("decoded garbage without an error")
+	}
+}
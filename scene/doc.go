@@ -0,0 +1,6 @@
+// Package scene implements a scene graph with hierarchical transforms for the Pixel library.
+//
+// A scene is a tree of Nodes. Each Node has a local Matrix, color mask and visibility, and a list
+// of Drawables attached to it. The world Matrix of a Node is it's local Matrix chained with the
+// world Matrix of it's parent, so moving, rotating or scaling a Node affects the whole subtree.
+package scene
@@ -0,0 +1,241 @@
+package scene
+
+import (
+	"image/color"
+
+	"github.com/faiface/pixel"
+)
+
+// Drawable is anything that can be drawn transformed by a Matrix and masked by a color, such as
+// *pixel.Sprite, *text.Text or *pixelgl.Canvas.
+//
+// Use Transformed to attach drawables that only draw onto a Target as is, such as *pixel.Batch
+// or *imdraw.IMDraw.
+type Drawable interface {
+	DrawColorMask(t pixel.Target, matrix pixel.Matrix, mask color.Color)
+}
+
+// DrawableFunc is a function implementing Drawable.
+type DrawableFunc func(t pixel.Target, matrix pixel.Matrix, mask color.Color)
+
+// DrawColorMask calls f.
+func (f DrawableFunc) DrawColorMask(t pixel.Target, matrix pixel.Matrix, mask color.Color) {
+	f(t, matrix, mask)
+}
+
+// Node is a node of a scene graph.
+//
+// Create a Node with NewNode, attach Drawables and child Nodes to it and draw the whole tree with
+// the Draw method of the root Node:
+//
+//   root := scene.NewNode()
+//   player := scene.NewNode()
+//   player.Attach(playerSprite)
+//   root.Add(player)
+//
+//   player.SetMatrix(pixel.IM.Moved(pos))
+//   root.Draw(win)
+//
+// World matrices are cached and only recomputed when a Node or one of it's ancestors changes.
+type Node struct {
+	local   pixel.Matrix
+	mask    pixel.RGBA
+	visible bool
+	hit     pixel.Rect
+
+	parent    *Node
+	children  []*Node
+	drawables []Drawable
+
+	world pixel.Matrix
+	dirty bool
+}
+
+// NewNode creates a new visible Node with the identity Matrix and no color mask.
+func NewNode() *Node {
+	return &Node{
+		local:   pixel.IM,
+		mask:    pixel.Alpha(1),
+		visible: true,
+		world:   pixel.IM,
+	}
+}
+
+// SetMatrix sets the local Matrix of the Node, which transforms the Node relative to it's parent.
+func (n *Node) SetMatrix(m pixel.Matrix) {
+	if m != n.local {
+		n.local = m
+		n.markDirty()
+	}
+}
+
+// Matrix returns the local Matrix of the Node.
+func (n *Node) Matrix() pixel.Matrix {
+	return n.local
+}
+
+// WorldMatrix returns the Matrix transforming the Node's local coordinates to the coordinates of
+// the root of the tree. It's the local Matrix chained with the parent's world Matrix.
+func (n *Node) WorldMatrix() pixel.Matrix {
+	if n.dirty {
+		n.world = n.local
+		if n.parent != nil {
+			n.world = n.local.Chained(n.parent.WorldMatrix())
+		}
+		n.dirty = false
+	}
+	return n.world
+}
+
+// SetColorMask sets a color that the Node and all of it's descendants will be multiplied by. A
+// nil color means no mask.
+func (n *Node) SetColorMask(c color.Color) {
+	n.mask = pixel.Alpha(1)
+	if c != nil {
+		n.mask = pixel.ToRGBA(c)
+	}
+}
+
+// ColorMask returns the color mask of the Node.
+func (n *Node) ColorMask() pixel.RGBA {
+	return n.mask
+}
+
+// WorldColorMask returns the color mask of the Node multiplied by the color masks of all of it's
+// ancestors.
+func (n *Node) WorldColorMask() pixel.RGBA {
+	mask := n.mask
+	for p := n.parent; p != nil; p = p.parent {
+		mask = mask.Mul(p.mask)
+	}
+	return mask
+}
+
+// SetVisible sets whether the Node and all of it's descendants are drawn and hit-tested.
+func (n *Node) SetVisible(visible bool) {
+	n.visible = visible
+}
+
+// Visible returns whether the Node is visible.
+func (n *Node) Visible() bool {
+	return n.visible
+}
+
+// SetHitBounds sets the rectangle (in the Node's local coordinates) used for hit-testing the Node
+// with Hit. A rectangle of zero area makes the Node itself not hittable, which is the default.
+func (n *Node) SetHitBounds(r pixel.Rect) {
+	n.hit = r.Norm()
+}
+
+// HitBounds returns the rectangle used for hit-testing the Node.
+func (n *Node) HitBounds() pixel.Rect {
+	return n.hit
+}
+
+// Add adds child Nodes to the Node. If a child already has a parent, it's removed from it first.
+func (n *Node) Add(children ...*Node) {
+	for _, c := range children {
+		if c.parent != nil {
+			c.parent.Remove(c)
+		}
+		c.parent = n
+		c.markDirty()
+		n.children = append(n.children, c)
+	}
+}
+
+// Remove removes a child Node from the Node. Nothing happens if it's not a child of the Node.
+func (n *Node) Remove(child *Node) {
+	for i, c := range n.children {
+		if c == child {
+			n.children = append(n.children[:i], n.children[i+1:]...)
+			child.parent = nil
+			child.markDirty()
+			return
+		}
+	}
+}
+
+// Parent returns the parent of the Node, or nil if it's a root.
+func (n *Node) Parent() *Node {
+	return n.parent
+}
+
+// Children returns the child Nodes of the Node. Do not modify the returned slice.
+func (n *Node) Children() []*Node {
+	return n.children
+}
+
+// Attach attaches Drawables to the Node. They will be drawn transformed by the world Matrix of the
+// Node, in the order they were attached and before the Node's children.
+func (n *Node) Attach(drawables ...Drawable) {
+	n.drawables = append(n.drawables, drawables...)
+}
+
+// Detach detaches a Drawable from the Node.
+func (n *Node) Detach(d Drawable) {
+	for i := range n.drawables {
+		if n.drawables[i] == d {
+			n.drawables = append(n.drawables[:i], n.drawables[i+1:]...)
+			return
+		}
+	}
+}
+
+// Drawables returns the Drawables attached to the Node. Do not modify the returned slice.
+func (n *Node) Drawables() []Drawable {
+	return n.drawables
+}
+
+// Draw draws the Node's Drawables and then all of it's children (depth-first, in the order they
+// were added) onto the Target. Invisible Nodes and their descendants are skipped.
+//
+// The color mask of the Node's ancestors is applied as well, but only when drawing from the
+// root. Drawing a subtree applies only the masks within the subtree.
+func (n *Node) Draw(t pixel.Target) {
+	n.draw(t, pixel.Alpha(1))
+}
+
+func (n *Node) draw(t pixel.Target, parentMask pixel.RGBA) {
+	if !n.visible {
+		return
+	}
+	mask := n.mask.Mul(parentMask)
+	world := n.WorldMatrix()
+	for _, d := range n.drawables {
+		d.DrawColorMask(t, world, mask)
+	}
+	for _, c := range n.children {
+		c.draw(t, mask)
+	}
+}
+
+// Hit returns the visible Node (the Node itself or one of it's descendants) whose hit bounds
+// contain the point given in the root's coordinates. If multiple Nodes are hit, the one drawn last
+// (the topmost one) is returned. If no Node is hit, nil is returned.
+func (n *Node) Hit(p pixel.Vec) *Node {
+	if !n.visible {
+		return nil
+	}
+	for i := len(n.children) - 1; i >= 0; i-- {
+		if hit := n.children[i].Hit(p); hit != nil {
+			return hit
+		}
+	}
+	if n.hit.W()*n.hit.H() != 0 && n.hit.Contains(n.WorldMatrix().Unproject(p)) {
+		return n
+	}
+	return nil
+}
+
+// markDirty marks the world Matrix of the Node and all of it's descendants as outdated.
+func (n *Node) markDirty() {
+	// if a Node is dirty, all of it's descendants are dirty too
+	if n.dirty {
+		return
+	}
+	n.dirty = true
+	for _, c := range n.children {
+		c.markDirty()
+	}
+}
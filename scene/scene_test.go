@@ -0,0 +1,115 @@
+package scene_test
+
+import (
+	"math"
+	"testing"
+
+	"github.com/faiface/pixel"
+	"github.com/faiface/pixel/imdraw"
+	"github.com/faiface/pixel/scene"
+)
+
+func vecEq(a, b pixel.Vec) bool {
+	return math.Abs(a.X-b.X) < 1e-9 && math.Abs(a.Y-b.Y) < 1e-9
+}
+
+func TestNode_WorldMatrix(t *testing.T) {
+	root := scene.NewNode()
+	arm := scene.NewNode()
+	hand := scene.NewNode()
+	root.Add(arm)
+	arm.Add(hand)
+
+	arm.SetMatrix(pixel.IM.Moved(pixel.V(10, 0)))
+	hand.SetMatrix(pixel.IM.Moved(pixel.V(5, 0)))
+	if got, want := hand.WorldMatrix().Project(pixel.ZV), pixel.V(15, 0); !vecEq(got, want) {
+		t.Errorf("hand at %v, want %v", got, want)
+	}
+
+	// changing an ancestor invalidates the cached world matrices of the subtree
+	root.SetMatrix(pixel.IM.Rotated(pixel.ZV, math.Pi/2))
+	if got, want := hand.WorldMatrix().Project(pixel.ZV), pixel.V(0, 15); !vecEq(got, want) {
+		t.Errorf("rotated hand at %v, want %v", got, want)
+	}
+
+	// reparenting too
+	root.Add(hand)
+	if len(arm.Children()) != 0 || hand.Parent() != root {
+		t.Fatalf("hand was not reparented")
+	}
+	if got, want := hand.WorldMatrix().Project(pixel.ZV), pixel.V(0, 5); !vecEq(got, want) {
+		t.Errorf("reparented hand at %v, want %v", got, want)
+	}
+}
+
+func TestNode_Hit(t *testing.T) {
+	root := scene.NewNode()
+	back := scene.NewNode()
+	front := scene.NewNode()
+	root.Add(back, front)
+
+	back.SetHitBounds(pixel.R(0, 0, 100, 100))
+	front.SetHitBounds(pixel.R(-10, -10, 10, 10))
+	front.SetMatrix(pixel.IM.Scaled(pixel.ZV, 2).Moved(pixel.V(50, 50)))
+
+	tests := []struct {
+		p    pixel.Vec
+		want *scene.Node
+	}{
+		{pixel.V(50, 50), front},
+		{pixel.V(69, 31), front},
+		{pixel.V(75, 50), back},
+		{pixel.V(150, 50), nil},
+	}
+	for _, tt := range tests {
+		if got := root.Hit(tt.p); got != tt.want {
+			t.Errorf("Hit(%v) = %p, want %p", tt.p, got, tt.want)
+		}
+	}
+
+	front.SetVisible(false)
+	if got := root.Hit(pixel.V(50, 50)); got != back {
+		t.Errorf("hit an invisible node")
+	}
+}
+
+func TestNode_Draw(t *testing.T) {
+	pic := pixel.MakePictureData(pixel.R(0, 0, 10, 10))
+	sprite := pixel.NewSprite(pic, pic.Bounds())
+
+	im := imdraw.New(nil)
+	im.Push(pixel.ZV)
+	im.Circle(1, 0)
+
+	root := scene.NewNode()
+	child := scene.NewNode()
+	root.Add(child)
+	root.SetColorMask(pixel.RGB(1, 0, 0))
+	child.SetMatrix(pixel.IM.Moved(pixel.V(100, 0)))
+	child.Attach(sprite, scene.Transformed(im))
+
+	out := &pixel.TrianglesData{}
+	batch := pixel.NewBatch(out, pic)
+	root.Draw(batch)
+
+	if out.Len() <= 6 {
+		t.Fatalf("out.Len() = %d, want more than 6", out.Len())
+	}
+	for i := range *out {
+		v := (*out)[i]
+		if v.Position.X < 94 || v.Position.X > 106 {
+			t.Fatalf("vertex %d at %v was not transformed", i, v.Position)
+		}
+		if v.Color.G != 0 || v.Color.B != 0 {
+			t.Fatalf("vertex %d has color %v, want red", i, v.Color)
+		}
+	}
+
+	// invisible subtrees are not drawn
+	child.SetVisible(false)
+	batch.Clear()
+	root.Draw(batch)
+	if out.Len() != 0 {
+		t.Errorf("drew an invisible node")
+	}
+}
@@ -0,0 +1,153 @@
+package scene
+
+import (
+	"image/color"
+
+	"github.com/faiface/pixel"
+)
+
+// TargetDrawer is anything that draws itself onto a Target as is, such as *pixel.Batch or
+// *imdraw.IMDraw.
+type TargetDrawer interface {
+	Draw(t pixel.Target)
+}
+
+// Transformed turns a TargetDrawer into a Drawable, which can be attached to a Node.
+//
+// The TargetDrawer is drawn onto an intermediate Target, which transforms the vertices by the
+// Matrix and multiplies their colors by the color mask. The transformed vertices are cached and
+// only recomputed when the Matrix, the color mask or the drawn Triangles change, so a static
+// TargetDrawer costs nothing extra when it's Node doesn't move.
+//
+// Unlike with SetMatrix, the original Target's Matrix and color mask remain untouched.
+func Transformed(d TargetDrawer) Drawable {
+	return &transformed{
+		d:       d,
+		targets: make(map[pixel.Target]*transformTarget),
+	}
+}
+
+type transformed struct {
+	d       TargetDrawer
+	targets map[pixel.Target]*transformTarget
+}
+
+func (tr *transformed) DrawColorMask(t pixel.Target, matrix pixel.Matrix, mask color.Color) {
+	tt := tr.targets[t]
+	if tt == nil {
+		tt = &transformTarget{dst: t}
+		tr.targets[t] = tt
+	}
+	tt.mat = matrix
+	tt.col = pixel.Alpha(1)
+	if mask != nil {
+		tt.col = pixel.ToRGBA(mask)
+	}
+	tr.d.Draw(tt)
+}
+
+// transformTarget is a Target, which transforms everything drawn onto it and draws it onto the
+// destination Target.
+type transformTarget struct {
+	dst pixel.Target
+	mat pixel.Matrix
+	col pixel.RGBA
+}
+
+func (tt *transformTarget) MakeTriangles(t pixel.Triangles) pixel.TargetTriangles {
+	return &transformTriangles{
+		tri:   t.Copy(),
+		tmp:   pixel.MakeTrianglesData(t.Len()),
+		dst:   tt.dst.MakeTriangles(t),
+		tt:    tt,
+		dirty: true,
+	}
+}
+
+func (tt *transformTarget) MakePicture(p pixel.Picture) pixel.TargetPicture {
+	return &transformPicture{
+		Picture: p,
+		dst:     tt.dst.MakePicture(p),
+	}
+}
+
+type transformTriangles struct {
+	tri pixel.Triangles
+	tmp *pixel.TrianglesData
+	dst pixel.TargetTriangles
+	tt  *transformTarget
+
+	mat   pixel.Matrix
+	col   pixel.RGBA
+	dirty bool
+}
+
+func (tr *transformTriangles) Len() int {
+	return tr.tri.Len()
+}
+
+func (tr *transformTriangles) SetLen(len int) {
+	tr.tri.SetLen(len)
+	tr.tmp.SetLen(len)
+	tr.dirty = true
+}
+
+func (tr *transformTriangles) Slice(i, j int) pixel.Triangles {
+	tri := tr.tri.Slice(i, j)
+	return &transformTriangles{
+		tri:   tri,
+		tmp:   tr.tmp.Slice(i, j).(*pixel.TrianglesData),
+		dst:   tr.tt.dst.MakeTriangles(tri),
+		tt:    tr.tt,
+		dirty: true,
+	}
+}
+
+func (tr *transformTriangles) Update(t pixel.Triangles) {
+	tr.tri.Update(t)
+	tr.dirty = true
+}
+
+func (tr *transformTriangles) Copy() pixel.Triangles {
+	return &transformTriangles{
+		tri:   tr.tri.Copy(),
+		tmp:   tr.tmp.Copy().(*pixel.TrianglesData),
+		dst:   tr.tt.dst.MakeTriangles(tr.tri),
+		tt:    tr.tt,
+		dirty: true,
+	}
+}
+
+// refresh updates the destination Triangles if the Triangles or the transformation changed since
+// the last time.
+func (tr *transformTriangles) refresh() {
+	if !tr.dirty && tr.mat == tr.tt.mat && tr.col == tr.tt.col {
+		return
+	}
+	tr.mat, tr.col, tr.dirty = tr.tt.mat, tr.tt.col, false
+
+	tr.tmp.Update(tr.tri)
+	for i := range *tr.tmp {
+		(*tr.tmp)[i].Position = tr.mat.Project((*tr.tmp)[i].Position)
+		(*tr.tmp)[i].Color = tr.col.Mul((*tr.tmp)[i].Color)
+	}
+
+	tr.dst.SetLen(tr.tmp.Len())
+	tr.dst.Update(tr.tmp)
+}
+
+func (tr *transformTriangles) Draw() {
+	tr.refresh()
+	tr.dst.Draw()
+}
+
+type transformPicture struct {
+	pixel.Picture
+	dst pixel.TargetPicture
+}
+
+func (tp *transformPicture) Draw(t pixel.TargetTriangles) {
+	tr := t.(*transformTriangles)
+	tr.refresh()
+	tp.dst.Draw(tr.dst)
+}
@@ -2,13 +2,19 @@ package pixel
 
 import "image/color"
 
-// Sprite is a drawable frame of a Picture. It's anchored by the center of it's Picture's frame.
+// Sprite is a drawable frame of a Picture. By default, it's anchored by the center of it's
+// Picture's frame, use SetAnchor or SetPivot to change that.
 //
 // Frame specifies a rectangular portion of the Picture that will be drawn. For example, this
 // creates a Sprite that draws the whole Picture:
 //
 //   sprite := pixel.NewSprite(pic, pic.Bounds())
 //
+// Apart from the Matrix and the color mask supplied when drawing, a Sprite can be flipped
+// horizontally and vertically (SetFlip), tinted with a different color in each corner
+// (SetCornerColors) and limited to draw only a portion of it's frame (SetSubFrame). All of these
+// are cached, so they don't cost anything unless they change.
+//
 // Note, that Sprite caches the results of MakePicture from Targets it's drawn to for each Picture
 // it's set to. What it means is that using a Sprite with an unbounded number of Pictures leads to a
 // memory leak, since Sprite caches them and never forgets. In such a situation, create a new Sprite
@@ -20,8 +26,29 @@ type Sprite struct {
 
 	matrix Matrix
 	mask   RGBA
+
+	anchor   Vec
+	absolute bool
+	flipH    bool
+	flipV    bool
+	corners  [4]RGBA
+	sub      Rect
+	subMode  SubFrameMode
 }
 
+// SubFrameMode specifies how a sub-frame set by Sprite.SetSubFrame is drawn.
+type SubFrameMode int
+
+const (
+	// SubFrameClip draws the sub-frame at the same place it would be drawn if the whole frame was
+	// drawn, effectively clipping the rest of the frame. This is useful for health bars and
+	// similar.
+	SubFrameClip SubFrameMode = iota
+
+	// SubFrameStretch stretches the sub-frame over the area of the whole frame.
+	SubFrameStretch
+)
+
 // NewSprite creates a Sprite from the supplied frame of a Picture.
 func NewSprite(pic Picture, frame Rect) *Sprite {
 	tri := MakeTrianglesData(6)
@@ -31,6 +58,8 @@ func NewSprite(pic Picture, frame Rect) *Sprite {
 	}
 	s.matrix = IM
 	s.mask = Alpha(1)
+	s.anchor = V(0.5, 0.5)
+	s.corners = [4]RGBA{Alpha(1), Alpha(1), Alpha(1), Alpha(1)}
 	s.Set(pic, frame)
 	return s
 }
@@ -54,6 +83,113 @@ func (s *Sprite) Frame() Rect {
 	return s.frame
 }
 
+// SetAnchor sets the anchor of the Sprite, the point of the frame which is placed at the origin
+// (and thus transformed by the Matrix to it's final position). The anchor is normalized, (0, 0)
+// being the bottom-left corner of the frame and (1, 1) the top-right corner.
+//
+//   sprite.SetAnchor(pixel.V(0.5, 0.5)) // center of the frame (default)
+//   sprite.SetAnchor(pixel.V(0.5, 0))   // bottom-center, e.g. feet of a character
+//
+// The anchor is kept relative to the frame, when the frame changes.
+func (s *Sprite) SetAnchor(anchor Vec) {
+	if anchor != s.anchor || s.absolute {
+		s.anchor = anchor
+		s.absolute = false
+		s.calcData()
+	}
+}
+
+// SetPivot sets the anchor of the Sprite in absolute units, relative to the Min corner of the
+// frame. See SetAnchor for details.
+//
+// The pivot keeps it's absolute offset from the Min corner, when the frame changes.
+func (s *Sprite) SetPivot(pivot Vec) {
+	if pivot != s.anchor || !s.absolute {
+		s.anchor = pivot
+		s.absolute = true
+		s.calcData()
+	}
+}
+
+// Anchor returns the current anchor of the Sprite, normalized as in SetAnchor.
+func (s *Sprite) Anchor() Vec {
+	if !s.absolute {
+		return s.anchor
+	}
+	if s.frame.W()*s.frame.H() == 0 {
+		return ZV
+	}
+	return V(s.anchor.X/s.frame.W(), s.anchor.Y/s.frame.H())
+}
+
+// Pivot returns the current anchor of the Sprite in absolute units, as in SetPivot.
+func (s *Sprite) Pivot() Vec {
+	if s.absolute {
+		return s.anchor
+	}
+	return s.anchor.ScaledXY(s.frame.Size())
+}
+
+// SetFlip sets whether the Sprite is mirrored horizontally and vertically. The Sprite is mirrored
+// around it's anchor, so flipping is equivalent to scaling by -1 around the origin, but without
+// changing the Matrix.
+func (s *Sprite) SetFlip(horizontal, vertical bool) {
+	if horizontal != s.flipH || vertical != s.flipV {
+		s.flipH, s.flipV = horizontal, vertical
+		s.calcData()
+	}
+}
+
+// Flip returns whether the Sprite is mirrored horizontally and vertically.
+func (s *Sprite) Flip() (horizontal, vertical bool) {
+	return s.flipH, s.flipV
+}
+
+// SetCornerColors sets a color tint of each corner of the Sprite. The colors are interpolated
+// across the Sprite, which allows for cheap gradients. The corners are as they appear before
+// applying the Matrix (that is, after flipping). A nil color means no tint.
+//
+// The tints are multiplied by the color mask supplied in DrawColorMask.
+func (s *Sprite) SetCornerColors(bottomLeft, bottomRight, topRight, topLeft color.Color) {
+	var corners [4]RGBA
+	for i, c := range [...]color.Color{bottomLeft, bottomRight, topRight, topLeft} {
+		corners[i] = Alpha(1)
+		if c != nil {
+			corners[i] = ToRGBA(c)
+		}
+	}
+	if corners != s.corners {
+		s.corners = corners
+		s.calcData()
+	}
+}
+
+// CornerColors returns the color tints of the corners of the Sprite.
+func (s *Sprite) CornerColors() (bottomLeft, bottomRight, topRight, topLeft RGBA) {
+	return s.corners[0], s.corners[1], s.corners[2], s.corners[3]
+}
+
+// SetSubFrame limits the Sprite to only draw the part of it's frame covered by the sub rectangle
+// (in the Picture's coordinates). The mode specifies whether the sub-frame is clipped or
+// stretched, see SubFrameMode.
+//
+//   // draw a health bar partially
+//   bar.SetSubFrame(frame.ResizedMin(pixel.V(frame.W()*health, frame.H())), pixel.SubFrameClip)
+//
+// Setting a sub rectangle of zero area (such as pixel.ZR) draws the whole frame again.
+func (s *Sprite) SetSubFrame(sub Rect, mode SubFrameMode) {
+	if sub != s.sub || mode != s.subMode {
+		s.sub, s.subMode = sub, mode
+		s.calcData()
+	}
+}
+
+// SubFrame returns the current sub-frame of the Sprite and it's mode. A sub-frame of zero area
+// means that the whole frame is drawn.
+func (s *Sprite) SubFrame() (Rect, SubFrameMode) {
+	return s.sub, s.subMode
+}
+
 // Draw draws the Sprite onto the provided Target. The Sprite will be transformed by the given Matrix.
 //
 // This method is equivalent to calling DrawColorMask with nil color mask.
@@ -88,24 +224,47 @@ func (s *Sprite) DrawColorMask(t Target, matrix Matrix, mask color.Color) {
 }
 
 func (s *Sprite) calcData() {
-	var (
-		center     = s.frame.Center()
-		horizontal = V(s.frame.W()/2, 0)
-		vertical   = V(0, s.frame.H()/2)
-	)
-
-	(*s.tri)[0].Position = Vec{}.Sub(horizontal).Sub(vertical)
-	(*s.tri)[1].Position = Vec{}.Add(horizontal).Sub(vertical)
-	(*s.tri)[2].Position = Vec{}.Add(horizontal).Add(vertical)
-	(*s.tri)[3].Position = Vec{}.Sub(horizontal).Sub(vertical)
-	(*s.tri)[4].Position = Vec{}.Add(horizontal).Add(vertical)
-	(*s.tri)[5].Position = Vec{}.Sub(horizontal).Add(vertical)
-
-	for i := range *s.tri {
-		(*s.tri)[i].Color = s.mask
-		(*s.tri)[i].Picture = center.Add((*s.tri)[i].Position)
+	// src is the area of the Picture that gets drawn, dst is where it's drawn (in the Picture's
+	// coordinates, before moving the anchor to the origin)
+	src, dst := s.frame, s.frame
+	if s.sub.W()*s.sub.H() != 0 {
+		src = s.sub.Norm().Intersect(s.frame.Norm())
+		if s.subMode == SubFrameClip {
+			dst = src
+		}
+	}
+
+	pivot := s.Pivot()
+	local := dst.Moved(s.frame.Min.Add(pivot).Scaled(-1))
+
+	if s.flipH {
+		local.Min.X, local.Max.X = -local.Max.X, -local.Min.X
+		src.Min.X, src.Max.X = src.Max.X, src.Min.X
+	}
+	if s.flipV {
+		local.Min.Y, local.Max.Y = -local.Max.Y, -local.Min.Y
+		src.Min.Y, src.Max.Y = src.Max.Y, src.Min.Y
+	}
+
+	// bottom-left, bottom-right, top-right, top-left
+	pos := [...]Vec{
+		local.Min,
+		V(local.Max.X, local.Min.Y),
+		local.Max,
+		V(local.Min.X, local.Max.Y),
+	}
+	pic := [...]Vec{
+		src.Min,
+		V(src.Max.X, src.Min.Y),
+		src.Max,
+		V(src.Min.X, src.Max.Y),
+	}
+
+	for i, j := range [...]int{0, 1, 2, 0, 2, 3} {
+		(*s.tri)[i].Position = s.matrix.Project(pos[j])
+		(*s.tri)[i].Color = s.mask.Mul(s.corners[j])
+		(*s.tri)[i].Picture = pic[j]
 		(*s.tri)[i].Intensity = 1
-		(*s.tri)[i].Position = s.matrix.Project((*s.tri)[i].Position)
 	}
 
 	s.d.Dirty()
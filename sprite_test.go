@@ -0,0 +1,113 @@
+package pixel_test
+
+import (
+	"testing"
+
+	"github.com/faiface/pixel"
+)
+
+// drawSprite draws the Sprite onto a fresh Batch and returns the resulting vertices.
+func drawSprite(s *pixel.Sprite) *pixel.TrianglesData {
+	out := &pixel.TrianglesData{}
+	s.Draw(pixel.NewBatch(out, s.Picture()), pixel.IM)
+	return out
+}
+
+func spriteBounds(td *pixel.TrianglesData) pixel.Rect {
+	bounds := pixel.Rect{Min: (*td)[0].Position, Max: (*td)[0].Position}
+	for _, v := range *td {
+		bounds = bounds.Union(pixel.Rect{Min: v.Position, Max: v.Position})
+	}
+	return bounds
+}
+
+func TestSprite_Anchor(t *testing.T) {
+	pic := pixel.MakePictureData(pixel.R(0, 0, 40, 20))
+	sprite := pixel.NewSprite(pic, pic.Bounds())
+
+	tests := []struct {
+		name   string
+		setup  func()
+		bounds pixel.Rect
+	}{
+		{"Default", func() {}, pixel.R(-20, -10, 20, 10)},
+		{"Bottom-left", func() { sprite.SetAnchor(pixel.ZV) }, pixel.R(0, 0, 40, 20)},
+		{"Bottom-center", func() { sprite.SetAnchor(pixel.V(0.5, 0)) }, pixel.R(-20, 0, 20, 20)},
+		{"Pivot", func() { sprite.SetPivot(pixel.V(10, 5)) }, pixel.R(-10, -5, 30, 15)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.setup()
+			if got := spriteBounds(drawSprite(sprite)); got != tt.bounds {
+				t.Errorf("bounds = %v, want %v", got, tt.bounds)
+			}
+		})
+	}
+}
+
+func TestSprite_Flip(t *testing.T) {
+	pic := pixel.MakePictureData(pixel.R(0, 0, 40, 20))
+	sprite := pixel.NewSprite(pic, pic.Bounds())
+	sprite.SetAnchor(pixel.ZV)
+	sprite.SetFlip(true, false)
+
+	out := drawSprite(sprite)
+	if got, want := spriteBounds(out), pixel.R(-40, 0, 0, 20); got != want {
+		t.Fatalf("bounds = %v, want %v", got, want)
+	}
+	// the bottom-left vertex shows the bottom-right corner of the frame
+	if got, want := (*out)[0].Position, pixel.V(-40, 0); got != want {
+		t.Errorf("Position = %v, want %v", got, want)
+	}
+	if got, want := (*out)[0].Picture, pixel.V(40, 0); got != want {
+		t.Errorf("Picture = %v, want %v", got, want)
+	}
+}
+
+func TestSprite_CornerColors(t *testing.T) {
+	pic := pixel.MakePictureData(pixel.R(0, 0, 40, 20))
+	sprite := pixel.NewSprite(pic, pic.Bounds())
+	sprite.SetCornerColors(pixel.RGB(1, 0, 0), nil, pixel.RGB(0, 0, 1), nil)
+
+	out := &pixel.TrianglesData{}
+	sprite.DrawColorMask(pixel.NewBatch(out, pic), pixel.IM, pixel.Alpha(0.5))
+
+	if got, want := (*out)[0].Color, pixel.RGB(1, 0, 0).Mul(pixel.Alpha(0.5)); got != want {
+		t.Errorf("bottom-left Color = %v, want %v", got, want)
+	}
+	if got, want := (*out)[1].Color, pixel.Alpha(0.5); got != want {
+		t.Errorf("bottom-right Color = %v, want %v", got, want)
+	}
+	if got, want := (*out)[2].Color, pixel.RGB(0, 0, 1).Mul(pixel.Alpha(0.5)); got != want {
+		t.Errorf("top-right Color = %v, want %v", got, want)
+	}
+}
+
+func TestSprite_SubFrame(t *testing.T) {
+	pic := pixel.MakePictureData(pixel.R(0, 0, 40, 20))
+	sprite := pixel.NewSprite(pic, pic.Bounds())
+	sprite.SetAnchor(pixel.ZV)
+
+	sprite.SetSubFrame(pixel.R(0, 0, 10, 20), pixel.SubFrameClip)
+	out := drawSprite(sprite)
+	if got, want := spriteBounds(out), pixel.R(0, 0, 10, 20); got != want {
+		t.Errorf("clip: bounds = %v, want %v", got, want)
+	}
+	if got, want := (*out)[2].Picture, pixel.V(10, 20); got != want {
+		t.Errorf("clip: Picture = %v, want %v", got, want)
+	}
+
+	sprite.SetSubFrame(pixel.R(0, 0, 10, 20), pixel.SubFrameStretch)
+	out = drawSprite(sprite)
+	if got, want := spriteBounds(out), pixel.R(0, 0, 40, 20); got != want {
+		t.Errorf("stretch: bounds = %v, want %v", got, want)
+	}
+	if got, want := (*out)[2].Picture, pixel.V(10, 20); got != want {
+		t.Errorf("stretch: Picture = %v, want %v", got, want)
+	}
+
+	sprite.SetSubFrame(pixel.ZR, pixel.SubFrameClip)
+	if got, want := spriteBounds(drawSprite(sprite)), pixel.R(0, 0, 40, 20); got != want {
+		t.Errorf("reset: bounds = %v, want %v", got, want)
+	}
+}
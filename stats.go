@@ -0,0 +1,183 @@
+package pixel
+
+import (
+	"fmt"
+	"image/color"
+)
+
+// RenderStats are counters of the work done by a Target, usually collected over a single frame.
+//
+// They are provided by StatsTarget, which wraps any Target, and natively by pixelgl.Canvas and
+// pixelgl.Window.
+type RenderStats struct {
+	// DrawCalls is the number of times any Triangles were drawn.
+	DrawCalls int
+
+	// Vertices is the total number of vertices drawn.
+	Vertices int
+
+	// Uploads is the number of times the content of Triangles was updated and UploadedVertices is
+	// the total number of vertices updated.
+	Uploads          int
+	UploadedVertices int
+
+	// BufferResizes is the number of times Triangles changed their length. In pixelgl, each of
+	// these re-allocates and re-uploads the vertex buffer.
+	BufferResizes int
+
+	// PictureSwitches is the number of draws using a different Picture (or no Picture) than the
+	// previous draw.
+	PictureSwitches int
+
+	// TrianglesMade and PicturesMade is the number of calls to MakeTriangles and MakePicture.
+	TrianglesMade int
+	PicturesMade  int
+
+	// ComposeChanges is the number of times the compose method was changed.
+	ComposeChanges int
+}
+
+// String returns a compact, single-line representation of the RenderStats.
+func (rs RenderStats) String() string {
+	return fmt.Sprintf(
+		"draws: %d, vertices: %d, uploads: %d (%d vertices), resizes: %d, picture switches: %d, made: %d triangles, %d pictures, compose changes: %d",
+		rs.DrawCalls,
+		rs.Vertices,
+		rs.Uploads,
+		rs.UploadedVertices,
+		rs.BufferResizes,
+		rs.PictureSwitches,
+		rs.TrianglesMade,
+		rs.PicturesMade,
+		rs.ComposeChanges,
+	)
+}
+
+// StatsTarget is a ComposeTarget, which wraps another Target, forwards everything to it and
+// collects RenderStats along the way.
+//
+//   stats := pixel.NewStatsTarget(canvas)
+//   scene.Draw(stats)
+//   fmt.Println(stats.Stats())
+//   stats.ResetStats()
+//
+// SetMatrix and SetColorMask are only forwarded if the wrapped Target is a BasicTarget,
+// SetComposeMethod only if it's a ComposeTarget.
+type StatsTarget struct {
+	t       Target
+	stats   RenderStats
+	lastPic *statsPicture
+	cmp     ComposeMethod
+}
+
+// NewStatsTarget creates a new StatsTarget wrapping the provided Target.
+func NewStatsTarget(t Target) *StatsTarget {
+	return &StatsTarget{t: t}
+}
+
+// Target returns the wrapped Target.
+func (st *StatsTarget) Target() Target {
+	return st.t
+}
+
+// Stats returns the RenderStats collected since the last call to ResetStats.
+func (st *StatsTarget) Stats() RenderStats {
+	return st.stats
+}
+
+// ResetStats sets all counters to zero. Usually called once per frame.
+func (st *StatsTarget) ResetStats() {
+	st.stats = RenderStats{}
+}
+
+// SetMatrix sets the Matrix of the wrapped Target.
+func (st *StatsTarget) SetMatrix(m Matrix) {
+	if bt, ok := st.t.(BasicTarget); ok {
+		bt.SetMatrix(m)
+	}
+}
+
+// SetColorMask sets the color mask of the wrapped Target.
+func (st *StatsTarget) SetColorMask(c color.Color) {
+	if bt, ok := st.t.(BasicTarget); ok {
+		bt.SetColorMask(c)
+	}
+}
+
+// SetComposeMethod sets the compose method of the wrapped Target.
+func (st *StatsTarget) SetComposeMethod(cm ComposeMethod) {
+	if cm != st.cmp {
+		st.cmp = cm
+		st.stats.ComposeChanges++
+	}
+	if ct, ok := st.t.(ComposeTarget); ok {
+		ct.SetComposeMethod(cm)
+	}
+}
+
+// MakeTriangles makes Triangles on the wrapped Target and returns Triangles counting their usage.
+func (st *StatsTarget) MakeTriangles(t Triangles) TargetTriangles {
+	st.stats.TrianglesMade++
+	return &statsTriangles{
+		TargetTriangles: st.t.MakeTriangles(t),
+		dst:             st,
+	}
+}
+
+// MakePicture makes a Picture on the wrapped Target and returns a Picture counting it's usage.
+func (st *StatsTarget) MakePicture(p Picture) TargetPicture {
+	st.stats.PicturesMade++
+	if sp, ok := p.(*statsPicture); ok {
+		p = sp.TargetPicture
+	}
+	return &statsPicture{
+		TargetPicture: st.t.MakePicture(p),
+		dst:           st,
+	}
+}
+
+func (st *StatsTarget) countDraw(t TargetTriangles, pic *statsPicture) {
+	st.stats.DrawCalls++
+	st.stats.Vertices += t.Len()
+	if pic != st.lastPic {
+		st.lastPic = pic
+		st.stats.PictureSwitches++
+	}
+}
+
+type statsTriangles struct {
+	TargetTriangles
+	dst *StatsTarget
+}
+
+func (st *statsTriangles) SetLen(len int) {
+	if len != st.Len() {
+		st.dst.stats.BufferResizes++
+	}
+	st.TargetTriangles.SetLen(len)
+}
+
+func (st *statsTriangles) Update(t Triangles) {
+	st.dst.stats.Uploads++
+	st.dst.stats.UploadedVertices += t.Len()
+	st.TargetTriangles.Update(t)
+}
+
+func (st *statsTriangles) Draw() {
+	st.dst.countDraw(st.TargetTriangles, nil)
+	st.TargetTriangles.Draw()
+}
+
+type statsPicture struct {
+	TargetPicture
+	dst *StatsTarget
+}
+
+func (sp *statsPicture) Draw(t TargetTriangles) {
+	st := t.(*statsTriangles)
+	if st.dst != sp.dst {
+		panic(fmt.Errorf("(%T).Draw: TargetTriangles generated by different StatsTarget", sp))
+	}
+	sp.dst.countDraw(st.TargetTriangles, sp)
+	sp.TargetPicture.Draw(st.TargetTriangles)
+}
@@ -0,0 +1,46 @@
+package pixel_test
+
+import (
+	"testing"
+
+	"github.com/faiface/pixel"
+)
+
+func TestStatsTarget(t *testing.T) {
+	pic1 := pixel.MakePictureData(pixel.R(0, 0, 10, 10))
+	pic2 := pixel.MakePictureData(pixel.R(0, 0, 20, 20))
+	s1 := pixel.NewSprite(pic1, pic1.Bounds())
+	s2 := pixel.NewSprite(pic2, pic2.Bounds())
+
+	st := pixel.NewStatsTarget(pixel.NewRecorder())
+	st.SetComposeMethod(pixel.ComposeOver)
+	st.SetComposeMethod(pixel.ComposePlus)
+	s1.Draw(st, pixel.IM)
+	s1.Draw(st, pixel.IM)
+	s2.Draw(st, pixel.IM)
+	s1.Draw(st, pixel.IM.Moved(pixel.V(1, 1)))
+
+	want := pixel.RenderStats{
+		DrawCalls:        4,
+		Vertices:         24,
+		Uploads:          1,
+		UploadedVertices: 6,
+		PictureSwitches:  3,
+		TrianglesMade:    2,
+		PicturesMade:     2,
+		ComposeChanges:   1,
+	}
+	if got := st.Stats(); got != want {
+		t.Errorf("Stats() = %v\nwant %v", got, want)
+	}
+
+	// everything is forwarded to the wrapped Target
+	if got := len(st.Target().(*pixel.Recorder).Commands()); got != 11 {
+		t.Errorf("wrapped Target recorded %d commands, want 11", got)
+	}
+
+	st.ResetStats()
+	if got := st.Stats(); got != (pixel.RenderStats{}) {
+		t.Errorf("Stats() after ResetStats = %v", got)
+	}
+}
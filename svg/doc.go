@@ -0,0 +1,10 @@
+// Package svg implements conversion between Pixel's drawing primitives and SVG documents.
+//
+// Target is a pixel.Target, which turns everything drawn onto it into an SVG document. It can be
+// used to export vector graphics, such as charts drawn with IMDraw, or as a GPU-free visual
+// debugging tool.
+//
+// Decode goes the other way around and loads the basic shapes of an SVG document, such as icons
+// made in a vector editor, into an Image, which can be drawn with IMDraw at any resolution.
+// ParsePath converts just the path data of a single path element into an imdraw.Path.
+package svg
@@ -0,0 +1,344 @@
+package svg
+
+import (
+	"encoding/xml"
+	"io"
+	"math"
+
+	"github.com/faiface/pixel"
+	"github.com/faiface/pixel/imdraw"
+	"github.com/pkg/errors"
+)
+
+// Image is a vector image decoded from an SVG document. It's a list of Shapes, which are drawn in
+// order.
+//
+// The coordinates of the Image are Pixel's ones, with the y axis pointing up and the viewBox of
+// the document mapped to the Bounds.
+type Image struct {
+	Shapes []Shape
+	bounds pixel.Rect
+}
+
+// Shape is a single shape of an Image, which is filled and/or outlined.
+type Shape struct {
+	// Path is the geometry of the Shape in the coordinates of the Image, with all transforms
+	// applied.
+	Path *imdraw.Path
+
+	// Fill is the color the Path is filled with, transparent if it's not filled.
+	Fill     pixel.RGBA
+	FillRule imdraw.FillRule
+
+	// Stroke is the color of the outline of the Path, transparent if it's not outlined. The other
+	// properties of the outline correspond to the properties of IMDraw.
+	Stroke      pixel.RGBA
+	StrokeWidth float64
+	EndShape    imdraw.EndShape
+	LineJoin    imdraw.LineJoin
+	MiterLimit  float64
+	Dash        []float64
+	DashOffset  float64
+}
+
+// Decode decodes an SVG document into an Image.
+//
+// Supported are the path, rect, circle, ellipse, line, polyline and polygon elements, grouped by
+// g elements, with transforms and the presentation attributes controlling fills and strokes (both
+// as attributes and in the style attribute). Colors can only be solid, paint servers such as
+// gradients are replaced by their fallback color. Opacity of groups is applied to each shape
+// separately. All other elements, such as text and images, are skipped.
+func Decode(r io.Reader) (*Image, error) {
+	dec := xml.NewDecoder(r)
+	img := &Image{}
+	var stack []style
+
+	for {
+		tok, err := dec.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to decode document")
+		}
+
+		switch tok := tok.(type) {
+		case xml.StartElement:
+			attrs := make(map[string]string)
+			for _, a := range tok.Attr {
+				attrs[a.Name.Local] = a.Value
+			}
+
+			var st style
+			if len(stack) == 0 {
+				if tok.Name.Local != "svg" {
+					return nil, errors.Errorf("root element is %s, not svg", tok.Name.Local)
+				}
+				st = defaultStyle()
+				if st.matrix, err = img.setViewport(attrs); err != nil {
+					return nil, err
+				}
+			} else {
+				st = stack[len(stack)-1]
+				// the slice of the parent must not be modified
+				st.dash = append([]float64(nil), st.dash...)
+			}
+			if err := st.inherit(attrs); err != nil {
+				return nil, err
+			}
+
+			switch tok.Name.Local {
+			case "svg", "g", "a":
+			case "path", "rect", "circle", "ellipse", "line", "polyline", "polygon":
+				if err := img.addShape(tok.Name.Local, attrs, st); err != nil {
+					return nil, err
+				}
+			default:
+				if err := dec.Skip(); err != nil {
+					return nil, errors.Wrap(err, "failed to decode document")
+				}
+				continue
+			}
+			stack = append(stack, st)
+
+		case xml.EndElement:
+			stack = stack[:len(stack)-1]
+		}
+	}
+
+	if img.bounds == pixel.ZR {
+		return nil, errors.New("no svg element found")
+	}
+	return img, nil
+}
+
+// Bounds returns the bounds of the Image.
+func (img *Image) Bounds() pixel.Rect {
+	return img.bounds
+}
+
+// Draw draws all Shapes of the Image using the IMDraw. The properties of the IMDraw are restored
+// afterwards, except for the Tolerance, which controls the precision of the curves.
+func (img *Image) Draw(imd *imdraw.IMDraw) {
+	color, fillRule := imd.Color, imd.FillRule
+	endShape, lineJoin, miterLimit := imd.EndShape, imd.LineJoin, imd.MiterLimit
+	dash, dashOffset := imd.Dash, imd.DashOffset
+	fill := imd.Fill
+	imd.Fill = nil
+
+	for _, s := range img.Shapes {
+		if s.Fill.A > 0 {
+			imd.Color = s.Fill
+			imd.FillRule = s.FillRule
+			imd.Path(s.Path, 0)
+		}
+		if s.Stroke.A > 0 && s.StrokeWidth > 0 {
+			imd.Color = s.Stroke
+			imd.EndShape = s.EndShape
+			imd.LineJoin = s.LineJoin
+			imd.MiterLimit = s.MiterLimit
+			imd.Dash = s.Dash
+			imd.DashOffset = s.DashOffset
+			imd.Path(s.Path, s.StrokeWidth)
+		}
+	}
+
+	imd.Color, imd.FillRule = color, fillRule
+	imd.EndShape, imd.LineJoin, imd.MiterLimit = endShape, lineJoin, miterLimit
+	imd.Dash, imd.DashOffset = dash, dashOffset
+	imd.Fill = fill
+}
+
+// Triangles returns the triangles of all Shapes of the Image, as drawn by IMDraw with the default
+// properties.
+func (img *Image) Triangles() *pixel.TrianglesData {
+	imd := imdraw.New(nil)
+	img.Draw(imd)
+	tri := &pixel.TrianglesData{}
+	imd.Draw(pixel.NewBatch(tri, nil))
+	return tri
+}
+
+// setViewport sets the bounds of the Image from the size of the root svg element and returns the
+// Matrix mapping it's viewBox to the bounds.
+func (img *Image) setViewport(attrs map[string]string) (pixel.Matrix, error) {
+	var viewBox pixel.Rect
+	if vb, ok := attrs["viewBox"]; ok {
+		nums, err := (&scanner{s: vb}).numbers()
+		if err != nil || len(nums) != 4 || nums[2] <= 0 || nums[3] <= 0 {
+			return pixel.IM, errors.Errorf("invalid viewBox %q", vb)
+		}
+		viewBox = pixel.R(nums[0], nums[1], nums[0]+nums[2], nums[1]+nums[3])
+	}
+
+	// relative sizes, such as 100%, are resolved to the viewBox
+	size := viewBox.Size()
+	if w, err := parseLength(attrs["width"]); err == nil && w > 0 {
+		size.X = w
+	}
+	if h, err := parseLength(attrs["height"]); err == nil && h > 0 {
+		size.Y = h
+	}
+	if size.X <= 0 || size.Y <= 0 {
+		return pixel.IM, errors.New("document has no size")
+	}
+	if viewBox == pixel.ZR {
+		viewBox = pixel.R(0, 0, size.X, size.Y)
+	}
+
+	img.bounds = pixel.R(0, 0, size.X, size.Y)
+	return pixel.IM.
+		Moved(viewBox.Min.Scaled(-1)).
+		ScaledXY(pixel.ZV, pixel.V(size.X/viewBox.W(), -size.Y/viewBox.H())).
+		Moved(pixel.V(0, size.Y)), nil
+}
+
+// inherit applies the presentation attributes, the style attribute and the transform of an
+// element to the style inherited from it's parent.
+func (st *style) inherit(attrs map[string]string) error {
+	for name, value := range attrs {
+		if err := st.set(name, value); err != nil {
+			return err
+		}
+	}
+	if css, ok := attrs["style"]; ok {
+		if err := st.setStyle(css); err != nil {
+			return err
+		}
+	}
+	if transform, ok := attrs["transform"]; ok {
+		m, err := parseTransform(transform)
+		if err != nil {
+			return err
+		}
+		st.matrix = m.Chained(st.matrix)
+	}
+	return nil
+}
+
+func (img *Image) addShape(name string, attrs map[string]string, st style) error {
+	num := func(attr string) (float64, error) {
+		s, ok := attrs[attr]
+		if !ok {
+			return 0, nil
+		}
+		x, err := parseLength(s)
+		return x, errors.Wrapf(err, "invalid %s of %s", attr, name)
+	}
+	nums := func(attrs ...string) ([]float64, error) {
+		xs := make([]float64, len(attrs))
+		for i, attr := range attrs {
+			var err error
+			if xs[i], err = num(attr); err != nil {
+				return nil, err
+			}
+		}
+		return xs, nil
+	}
+
+	pb := newPathBuilder(st.matrix)
+	switch name {
+	case "path":
+		if err := pb.parse(attrs["d"]); err != nil {
+			return err
+		}
+
+	case "rect":
+		xs, err := nums("x", "y", "width", "height", "rx", "ry")
+		if err != nil {
+			return err
+		}
+		x, y, w, h, rx, ry := xs[0], xs[1], xs[2], xs[3], xs[4], xs[5]
+		if w <= 0 || h <= 0 {
+			return nil
+		}
+		if _, ok := attrs["rx"]; !ok {
+			rx = ry
+		}
+		if _, ok := attrs["ry"]; !ok {
+			ry = rx
+		}
+		rx, ry = pixel.Clamp(rx, 0, w/2), pixel.Clamp(ry, 0, h/2)
+
+		pb.moveTo(pixel.V(x+rx, y))
+		if rx > 0 && ry > 0 {
+			r := pixel.V(rx, ry)
+			pb.lineTo(pixel.V(x+w-rx, y))
+			pb.ellipseArc(pixel.V(x+w-rx, y+ry), r, 0, -math.Pi/2, 0)
+			pb.lineTo(pixel.V(x+w, y+h-ry))
+			pb.ellipseArc(pixel.V(x+w-rx, y+h-ry), r, 0, 0, math.Pi/2)
+			pb.lineTo(pixel.V(x+rx, y+h))
+			pb.ellipseArc(pixel.V(x+rx, y+h-ry), r, 0, math.Pi/2, math.Pi)
+			pb.lineTo(pixel.V(x, y+ry))
+			pb.ellipseArc(pixel.V(x+rx, y+ry), r, 0, math.Pi, 3*math.Pi/2)
+		} else {
+			pb.lineTo(pixel.V(x+w, y))
+			pb.lineTo(pixel.V(x+w, y+h))
+			pb.lineTo(pixel.V(x, y+h))
+		}
+		pb.close()
+
+	case "circle", "ellipse":
+		xs, err := nums("cx", "cy", "r", "rx", "ry")
+		if err != nil {
+			return err
+		}
+		center, radius := pixel.V(xs[0], xs[1]), pixel.V(xs[2], xs[2])
+		if name == "ellipse" {
+			radius = pixel.V(xs[3], xs[4])
+		}
+		if radius.X <= 0 || radius.Y <= 0 {
+			return nil
+		}
+		pb.moveTo(center.Add(pixel.V(radius.X, 0)))
+		pb.ellipseArc(center, radius, 0, 0, 2*math.Pi)
+		pb.close()
+
+	case "line":
+		xs, err := nums("x1", "y1", "x2", "y2")
+		if err != nil {
+			return err
+		}
+		pb.moveTo(pixel.V(xs[0], xs[1]))
+		pb.lineTo(pixel.V(xs[2], xs[3]))
+
+	case "polyline", "polygon":
+		xs, err := (&scanner{s: attrs["points"]}).numbers()
+		if err != nil {
+			return errors.Wrapf(err, "invalid points of %s", name)
+		}
+		if len(xs) < 2 {
+			return nil
+		}
+		pb.moveTo(pixel.V(xs[0], xs[1]))
+		for i := 2; i+1 < len(xs); i += 2 {
+			pb.lineTo(pixel.V(xs[i], xs[i+1]))
+		}
+		if name == "polygon" {
+			pb.close()
+		}
+	}
+
+	// stroke widths scale with the average scale of the transform
+	m := st.matrix
+	scale := math.Sqrt(math.Abs(m[0]*m[3] - m[1]*m[2]))
+
+	s := Shape{
+		Path:        pb.path,
+		Fill:        st.fill.Scaled(st.fillOpacity * st.opacity),
+		FillRule:    st.fillRule,
+		Stroke:      st.stroke.Scaled(st.strokeOpacity * st.opacity),
+		StrokeWidth: st.strokeWidth * scale,
+		EndShape:    st.lineCap,
+		LineJoin:    st.lineJoin,
+		MiterLimit:  st.miterLimit,
+		DashOffset:  st.dashOffset * scale,
+	}
+	for _, d := range st.dash {
+		s.Dash = append(s.Dash, d*scale)
+	}
+	if s.Fill.A > 0 || s.Stroke.A > 0 {
+		img.Shapes = append(img.Shapes, s)
+	}
+	return nil
+}
@@ -0,0 +1,392 @@
+package svg
+
+import (
+	"math"
+	"strconv"
+
+	"github.com/faiface/pixel"
+	"github.com/faiface/pixel/imdraw"
+	"github.com/pkg/errors"
+)
+
+// ParsePath parses SVG path data (the d attribute of a path element) into an imdraw.Path. All
+// commands are supported. The Path is in the coordinates of the SVG document, where the y axis
+// points down.
+//
+// Elliptical arcs are converted to cubic Bézier curves.
+func ParsePath(d string) (*imdraw.Path, error) {
+	pb := newPathBuilder(pixel.IM)
+	if err := pb.parse(d); err != nil {
+		return nil, err
+	}
+	return pb.path, nil
+}
+
+// pathBuilder builds an imdraw.Path from SVG path data, with all points projected by a Matrix.
+// The current point and the control points are tracked in the untransformed coordinates.
+type pathBuilder struct {
+	path *imdraw.Path
+	m    pixel.Matrix
+
+	start, current, ctrl pixel.Vec
+	lastCmd              byte
+}
+
+func newPathBuilder(m pixel.Matrix) *pathBuilder {
+	return &pathBuilder{path: &imdraw.Path{}, m: m}
+}
+
+func (pb *pathBuilder) moveTo(pt pixel.Vec) {
+	pb.path.MoveTo(pb.m.Project(pt))
+	pb.start, pb.current = pt, pt
+}
+
+func (pb *pathBuilder) lineTo(pt pixel.Vec) {
+	pb.path.LineTo(pb.m.Project(pt))
+	pb.current = pt
+}
+
+func (pb *pathBuilder) quadTo(ctrl, pt pixel.Vec) {
+	pb.path.QuadTo(pb.m.Project(ctrl), pb.m.Project(pt))
+	pb.current, pb.ctrl = pt, ctrl
+}
+
+func (pb *pathBuilder) cubicTo(ctrl1, ctrl2, pt pixel.Vec) {
+	pb.path.CubicTo(pb.m.Project(ctrl1), pb.m.Project(ctrl2), pb.m.Project(pt))
+	pb.current, pb.ctrl = pt, ctrl2
+}
+
+func (pb *pathBuilder) close() {
+	pb.path.Close()
+	pb.current = pb.start
+}
+
+// ellipseArc adds an arc of the ellipse around the center with the axes rotated by the rotation
+// angle, from the low to the high angle. The arc is split into cubic Bézier curves of at most a
+// quarter turn each, which approximate it with a relative error below 0.03%. Unlike an ellipse,
+// the curves stay exact under any Matrix.
+func (pb *pathBuilder) ellipseArc(center, radius pixel.Vec, rotation, low, high float64) {
+	ellipse := pixel.IM.ScaledXY(pixel.ZV, radius).Rotated(pixel.ZV, rotation).Moved(center)
+	n := math.Ceil(math.Abs(high-low) / (math.Pi / 2))
+	delta := (high - low) / n
+	k := 4.0 / 3 * math.Tan(delta/4)
+
+	for i := 0.0; i < n; i++ {
+		a, b := low+i*delta, low+(i+1)*delta
+		sina, cosa := math.Sincos(a)
+		sinb, cosb := math.Sincos(b)
+		pb.cubicTo(
+			ellipse.Project(pixel.V(cosa-k*sina, sina+k*cosa)),
+			ellipse.Project(pixel.V(cosb+k*sinb, sinb-k*cosb)),
+			ellipse.Project(pixel.V(cosb, sinb)),
+		)
+	}
+}
+
+// arcTo adds an SVG elliptical arc from the current point to the point, converting it to the
+// center parameterization first (see the SVG specification, section F.6.5).
+func (pb *pathBuilder) arcTo(radius pixel.Vec, rotation float64, large, sweep bool, pt pixel.Vec) {
+	if pt == pb.current {
+		return
+	}
+	rx, ry := math.Abs(radius.X), math.Abs(radius.Y)
+	if rx == 0 || ry == 0 {
+		pb.lineTo(pt)
+		return
+	}
+
+	half := pb.current.Sub(pt).Scaled(0.5).Rotated(-rotation)
+	lambda := half.X*half.X/(rx*rx) + half.Y*half.Y/(ry*ry)
+	if lambda > 1 {
+		rx, ry = rx*math.Sqrt(lambda), ry*math.Sqrt(lambda)
+	}
+
+	num := rx*rx*ry*ry - rx*rx*half.Y*half.Y - ry*ry*half.X*half.X
+	den := rx*rx*half.Y*half.Y + ry*ry*half.X*half.X
+	coef := math.Sqrt(math.Max(0, num/den))
+	if large == sweep {
+		coef = -coef
+	}
+	c := pixel.V(coef*rx*half.Y/ry, -coef*ry*half.X/rx)
+	center := c.Rotated(rotation).Add(pb.current.Add(pt).Scaled(0.5))
+
+	u := pixel.V((half.X-c.X)/rx, (half.Y-c.Y)/ry)
+	v := pixel.V((-half.X-c.X)/rx, (-half.Y-c.Y)/ry)
+	low := u.Angle()
+	delta := math.Atan2(u.Cross(v), u.Dot(v))
+	if !sweep && delta > 0 {
+		delta -= 2 * math.Pi
+	}
+	if sweep && delta < 0 {
+		delta += 2 * math.Pi
+	}
+
+	pb.ellipseArc(center, pixel.V(rx, ry), rotation, low, low+delta)
+	pb.current = pt
+}
+
+func (pb *pathBuilder) parse(d string) error {
+	s := &scanner{s: d}
+	for {
+		s.skipSeparator()
+		if s.done() {
+			return nil
+		}
+
+		cmd := s.s[s.i]
+		switch {
+		case isCommand(cmd):
+			s.i++
+		case pb.lastCmd != 0 && lower(pb.lastCmd) != 'z' && s.hasNumber():
+			// implicit repetition of the last command, moves are followed by lines
+			cmd = pb.lastCmd
+			switch cmd {
+			case 'M':
+				cmd = 'L'
+			case 'm':
+				cmd = 'l'
+			}
+		default:
+			return errors.Errorf("invalid path data %q at offset %d", d, s.i)
+		}
+		if pb.lastCmd == 0 && cmd != 'M' && cmd != 'm' {
+			return errors.Errorf("path data %q doesn't start with a move", d)
+		}
+
+		if err := pb.command(cmd, s); err != nil {
+			return errors.Wrapf(err, "invalid path data %q", d)
+		}
+		pb.lastCmd = cmd
+	}
+}
+
+func (pb *pathBuilder) command(cmd byte, s *scanner) error {
+	// relative commands are lowercase
+	var origin pixel.Vec
+	if cmd >= 'a' {
+		origin = pb.current
+	}
+
+	// control point reflected for smooth curves, if the previous command was a curve of the
+	// same kind
+	reflected := pb.current
+	switch lower(cmd) {
+	case 's':
+		if l := lower(pb.lastCmd); l == 'c' || l == 's' {
+			reflected = pb.current.Scaled(2).Sub(pb.ctrl)
+		}
+	case 't':
+		if l := lower(pb.lastCmd); l == 'q' || l == 't' {
+			reflected = pb.current.Scaled(2).Sub(pb.ctrl)
+		}
+	}
+
+	switch lower(cmd) {
+	case 'z':
+		pb.close()
+		return nil
+	case 'h':
+		x, err := s.number()
+		if err != nil {
+			return err
+		}
+		pb.lineTo(pixel.V(origin.X+x, pb.current.Y))
+		return nil
+	case 'v':
+		y, err := s.number()
+		if err != nil {
+			return err
+		}
+		pb.lineTo(pixel.V(pb.current.X, origin.Y+y))
+		return nil
+	case 'a':
+		var (
+			rx, ry, rotation float64
+			large, sweep     bool
+			pt               pixel.Vec
+			err              error
+		)
+		if rx, err = s.number(); err != nil {
+			return err
+		}
+		if ry, err = s.number(); err != nil {
+			return err
+		}
+		if rotation, err = s.number(); err != nil {
+			return err
+		}
+		if large, err = s.flag(); err != nil {
+			return err
+		}
+		if sweep, err = s.flag(); err != nil {
+			return err
+		}
+		if pt, err = s.point(); err != nil {
+			return err
+		}
+		pb.arcTo(pixel.V(rx, ry), rotation*math.Pi/180, large, sweep, origin.Add(pt))
+		return nil
+	}
+
+	var pts [3]pixel.Vec
+	for i := 0; i < pointArgs[lower(cmd)]; i++ {
+		pt, err := s.point()
+		if err != nil {
+			return err
+		}
+		pts[i] = origin.Add(pt)
+	}
+
+	switch lower(cmd) {
+	case 'm':
+		pb.moveTo(pts[0])
+	case 'l':
+		pb.lineTo(pts[0])
+	case 'q':
+		pb.quadTo(pts[0], pts[1])
+	case 't':
+		pb.quadTo(reflected, pts[0])
+	case 'c':
+		pb.cubicTo(pts[0], pts[1], pts[2])
+	case 's':
+		pb.cubicTo(reflected, pts[0], pts[1])
+	}
+	return nil
+}
+
+// pointArgs is the number of points taken by the commands with only point arguments.
+var pointArgs = map[byte]int{'m': 1, 'l': 1, 't': 1, 'q': 2, 's': 2, 'c': 3}
+
+func isCommand(c byte) bool {
+	switch lower(c) {
+	case 'm', 'l', 'h', 'v', 'c', 's', 'q', 't', 'a', 'z':
+		return true
+	}
+	return false
+}
+
+func lower(c byte) byte {
+	if 'A' <= c && c <= 'Z' {
+		return c + 'a' - 'A'
+	}
+	return c
+}
+
+// scanner reads numbers from SVG attributes, such as path data, point lists and transforms.
+// Numbers are separated by whitespace and commas, or not at all if it's unambiguous, such as in
+// "1-2.5.5".
+type scanner struct {
+	s string
+	i int
+}
+
+func (s *scanner) done() bool {
+	return s.i >= len(s.s)
+}
+
+func (s *scanner) skipSpace() {
+	for !s.done() && isSpace(s.s[s.i]) {
+		s.i++
+	}
+}
+
+func (s *scanner) skipSeparator() {
+	s.skipSpace()
+	if !s.done() && s.s[s.i] == ',' {
+		s.i++
+		s.skipSpace()
+	}
+}
+
+func (s *scanner) hasNumber() bool {
+	if s.done() {
+		return false
+	}
+	c := s.s[s.i]
+	return isDigit(c) || c == '.' || c == '-' || c == '+'
+}
+
+func (s *scanner) number() (float64, error) {
+	s.skipSeparator()
+	start := s.i
+	if !s.done() && (s.s[s.i] == '-' || s.s[s.i] == '+') {
+		s.i++
+	}
+	digits, dot := 0, false
+mantissa:
+	for !s.done() {
+		c := s.s[s.i]
+		switch {
+		case isDigit(c):
+			digits++
+		case c == '.' && !dot:
+			dot = true
+		default:
+			break mantissa
+		}
+		s.i++
+	}
+	if digits > 0 && s.i+1 < len(s.s) && (s.s[s.i] == 'e' || s.s[s.i] == 'E') {
+		j := s.i + 1
+		if s.s[j] == '-' || s.s[j] == '+' {
+			j++
+		}
+		if j < len(s.s) && isDigit(s.s[j]) {
+			s.i = j
+			for !s.done() && isDigit(s.s[s.i]) {
+				s.i++
+			}
+		}
+	}
+	if digits == 0 {
+		s.i = start
+		return 0, errors.Errorf("expected a number at offset %d", start)
+	}
+	return strconv.ParseFloat(s.s[start:s.i], 64)
+}
+
+// flag reads an arc flag, which is a single 0 or 1 and doesn't need to be separated.
+func (s *scanner) flag() (bool, error) {
+	s.skipSeparator()
+	if s.done() || (s.s[s.i] != '0' && s.s[s.i] != '1') {
+		return false, errors.Errorf("expected a flag at offset %d", s.i)
+	}
+	s.i++
+	return s.s[s.i-1] == '1', nil
+}
+
+func (s *scanner) point() (pixel.Vec, error) {
+	x, err := s.number()
+	if err != nil {
+		return pixel.ZV, err
+	}
+	y, err := s.number()
+	if err != nil {
+		return pixel.ZV, err
+	}
+	return pixel.V(x, y), nil
+}
+
+// numbers reads all remaining numbers.
+func (s *scanner) numbers() ([]float64, error) {
+	var nums []float64
+	for {
+		s.skipSeparator()
+		if s.done() {
+			return nums, nil
+		}
+		x, err := s.number()
+		if err != nil {
+			return nil, err
+		}
+		nums = append(nums, x)
+	}
+}
+
+func isSpace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\n' || c == '\r' || c == '\f'
+}
+
+func isDigit(c byte) bool {
+	return '0' <= c && c <= '9'
+}
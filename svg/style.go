@@ -0,0 +1,289 @@
+package svg
+
+import (
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/faiface/pixel"
+	"github.com/faiface/pixel/imdraw"
+	"github.com/pkg/errors"
+)
+
+// style holds the presentation attributes of an element, inherited from it's ancestors.
+type style struct {
+	fill          pixel.RGBA
+	fillOpacity   float64
+	fillRule      imdraw.FillRule
+	stroke        pixel.RGBA
+	strokeOpacity float64
+	strokeWidth   float64
+	lineCap       imdraw.EndShape
+	lineJoin      imdraw.LineJoin
+	miterLimit    float64
+	dash          []float64
+	dashOffset    float64
+	opacity       float64
+	matrix        pixel.Matrix
+}
+
+// defaultStyle returns the initial values of the presentation attributes.
+func defaultStyle() style {
+	return style{
+		fill:          pixel.RGB(0, 0, 0),
+		fillOpacity:   1,
+		fillRule:      imdraw.NonZeroFillRule,
+		strokeOpacity: 1,
+		strokeWidth:   1,
+		lineCap:       imdraw.NoEndShape,
+		lineJoin:      imdraw.MiterLineJoin,
+		miterLimit:    4,
+		opacity:       1,
+		matrix:        pixel.IM,
+	}
+}
+
+// set sets a presentation attribute. Unknown attributes are ignored.
+func (st *style) set(name, value string) error {
+	value = strings.TrimSpace(value)
+	var err error
+	switch name {
+	case "fill":
+		st.fill, err = parsePaint(value)
+	case "fill-opacity":
+		st.fillOpacity, err = parseOpacity(value)
+	case "fill-rule":
+		switch value {
+		case "nonzero":
+			st.fillRule = imdraw.NonZeroFillRule
+		case "evenodd":
+			st.fillRule = imdraw.EvenOddFillRule
+		default:
+			err = errors.Errorf("unknown fill rule %q", value)
+		}
+	case "stroke":
+		st.stroke, err = parsePaint(value)
+	case "stroke-opacity":
+		st.strokeOpacity, err = parseOpacity(value)
+	case "stroke-width":
+		st.strokeWidth, err = parseLength(value)
+	case "stroke-linecap":
+		switch value {
+		case "butt":
+			st.lineCap = imdraw.NoEndShape
+		case "round":
+			st.lineCap = imdraw.RoundEndShape
+		case "square":
+			st.lineCap = imdraw.SquareEndShape
+		default:
+			err = errors.Errorf("unknown line cap %q", value)
+		}
+	case "stroke-linejoin":
+		switch value {
+		case "miter", "miter-clip", "arcs":
+			st.lineJoin = imdraw.MiterLineJoin
+		case "round":
+			st.lineJoin = imdraw.RoundLineJoin
+		case "bevel":
+			st.lineJoin = imdraw.BevelLineJoin
+		default:
+			err = errors.Errorf("unknown line join %q", value)
+		}
+	case "stroke-miterlimit":
+		st.miterLimit, err = strconv.ParseFloat(value, 64)
+	case "stroke-dasharray":
+		st.dash = nil
+		if value != "none" {
+			s := &scanner{s: strings.Replace(value, "px", "", -1)}
+			st.dash, err = s.numbers()
+		}
+	case "stroke-dashoffset":
+		st.dashOffset, err = parseLength(value)
+	case "opacity":
+		var opacity float64
+		opacity, err = parseOpacity(value)
+		// group opacity is approximated by applying it to each shape separately
+		st.opacity *= opacity
+	}
+	return errors.Wrapf(err, "invalid %s", name)
+}
+
+// setStyle sets the presentation attributes from the content of a style attribute.
+func (st *style) setStyle(css string) error {
+	for _, decl := range strings.Split(css, ";") {
+		i := strings.IndexByte(decl, ':')
+		if i < 0 {
+			continue
+		}
+		if err := st.set(strings.TrimSpace(decl[:i]), decl[i+1:]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// parsePaint parses a color of a fill or a stroke. References to paint servers, such as gradients,
+// are not supported and are replaced by their fallback color, or none.
+func parsePaint(s string) (pixel.RGBA, error) {
+	if strings.HasPrefix(s, "url(") {
+		i := strings.IndexByte(s, ')')
+		if i < 0 {
+			return pixel.RGBA{}, errors.Errorf("invalid paint %q", s)
+		}
+		fallback := strings.TrimSpace(s[i+1:])
+		if fallback == "" {
+			return pixel.RGBA{}, nil
+		}
+		s = fallback
+	}
+	return parseColor(s)
+}
+
+// parseColor parses a color in the hexadecimal or the rgb() notation, or a color keyword. The
+// returned color is premultiplied, as always in Pixel.
+func parseColor(s string) (pixel.RGBA, error) {
+	s = strings.ToLower(strings.TrimSpace(s))
+
+	switch {
+	case s == "none" || s == "transparent":
+		return pixel.RGBA{}, nil
+
+	case strings.HasPrefix(s, "#"):
+		hex := s[1:]
+		if len(hex) == 3 {
+			hex = string([]byte{hex[0], hex[0], hex[1], hex[1], hex[2], hex[2]})
+		}
+		if len(hex) != 6 {
+			break
+		}
+		x, err := strconv.ParseUint(hex, 16, 32)
+		if err != nil {
+			break
+		}
+		return pixel.RGB(float64(x>>16)/255, float64(x>>8&0xff)/255, float64(x&0xff)/255), nil
+
+	case strings.HasPrefix(s, "rgb(") && strings.HasSuffix(s, ")"):
+		parts := strings.Split(s[4:len(s)-1], ",")
+		if len(parts) != 3 {
+			break
+		}
+		var rgb [3]float64
+		for i, p := range parts {
+			p = strings.TrimSpace(p)
+			scale := 255.0
+			if strings.HasSuffix(p, "%") {
+				p, scale = p[:len(p)-1], 100
+			}
+			x, err := strconv.ParseFloat(p, 64)
+			if err != nil {
+				return pixel.RGBA{}, errors.Errorf("invalid color %q", s)
+			}
+			rgb[i] = pixel.Clamp(x/scale, 0, 1)
+		}
+		return pixel.RGB(rgb[0], rgb[1], rgb[2]), nil
+
+	default:
+		if c, ok := colorKeywords[s]; ok {
+			return pixel.RGB(float64(c[0])/255, float64(c[1])/255, float64(c[2])/255), nil
+		}
+	}
+	return pixel.RGBA{}, errors.Errorf("invalid color %q", s)
+}
+
+// colorKeywords are the basic color keywords of CSS, plus a few commonly used extended ones.
+var colorKeywords = map[string][3]uint8{
+	"black":   {0, 0, 0},
+	"silver":  {192, 192, 192},
+	"gray":    {128, 128, 128},
+	"grey":    {128, 128, 128},
+	"white":   {255, 255, 255},
+	"maroon":  {128, 0, 0},
+	"red":     {255, 0, 0},
+	"purple":  {128, 0, 128},
+	"fuchsia": {255, 0, 255},
+	"magenta": {255, 0, 255},
+	"green":   {0, 128, 0},
+	"lime":    {0, 255, 0},
+	"olive":   {128, 128, 0},
+	"yellow":  {255, 255, 0},
+	"navy":    {0, 0, 128},
+	"blue":    {0, 0, 255},
+	"teal":    {0, 128, 128},
+	"aqua":    {0, 255, 255},
+	"cyan":    {0, 255, 255},
+	"orange":  {255, 165, 0},
+	"brown":   {165, 42, 42},
+	"pink":    {255, 192, 203},
+	"gold":    {255, 215, 0},
+}
+
+func parseOpacity(s string) (float64, error) {
+	scale := 1.0
+	if strings.HasSuffix(s, "%") {
+		s, scale = s[:len(s)-1], 100
+	}
+	x, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return 0, err
+	}
+	return pixel.Clamp(x/scale, 0, 1), nil
+}
+
+// parseLength parses a length in user units. Only the px unit is supported, since other units
+// depend on the resolution of the output.
+func parseLength(s string) (float64, error) {
+	s = strings.TrimSuffix(strings.TrimSpace(s), "px")
+	x, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return 0, errors.Errorf("invalid length %q", s)
+	}
+	return x, nil
+}
+
+// parseTransform parses the content of a transform attribute. The returned Matrix applies the
+// transforms from right to left, as in SVG.
+func parseTransform(s string) (pixel.Matrix, error) {
+	m := pixel.IM
+	rest := strings.TrimSpace(s)
+	for rest != "" {
+		open := strings.IndexByte(rest, '(')
+		end := strings.IndexByte(rest, ')')
+		if open < 0 || end < open {
+			return pixel.IM, errors.Errorf("invalid transform %q", s)
+		}
+		name := strings.TrimSpace(rest[:open])
+		args, err := (&scanner{s: rest[open+1 : end]}).numbers()
+		if err != nil {
+			return pixel.IM, errors.Wrapf(err, "invalid transform %q", s)
+		}
+		rest = strings.TrimLeft(rest[end+1:], " \t\n\r,")
+
+		arg := func(i int, def float64) float64 {
+			if i < len(args) {
+				return args[i]
+			}
+			return def
+		}
+
+		var t pixel.Matrix
+		switch {
+		case name == "matrix" && len(args) == 6:
+			t = pixel.Matrix{args[0], args[1], args[2], args[3], args[4], args[5]}
+		case name == "translate" && (len(args) == 1 || len(args) == 2):
+			t = pixel.IM.Moved(pixel.V(args[0], arg(1, 0)))
+		case name == "scale" && (len(args) == 1 || len(args) == 2):
+			t = pixel.IM.ScaledXY(pixel.ZV, pixel.V(args[0], arg(1, args[0])))
+		case name == "rotate" && (len(args) == 1 || len(args) == 3):
+			t = pixel.IM.Rotated(pixel.V(arg(1, 0), arg(2, 0)), args[0]*math.Pi/180)
+		case name == "skewX" && len(args) == 1:
+			t = pixel.Matrix{1, 0, math.Tan(args[0] * math.Pi / 180), 1, 0, 0}
+		case name == "skewY" && len(args) == 1:
+			t = pixel.Matrix{1, math.Tan(args[0] * math.Pi / 180), 0, 1, 0, 0}
+		default:
+			return pixel.IM, errors.Errorf("invalid transform %q", s)
+		}
+		// the leftmost transform is applied last
+		m = t.Chained(m)
+	}
+	return m, nil
+}
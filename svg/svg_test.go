@@ -0,0 +1,267 @@
+package svg_test
+
+import (
+	"bytes"
+	"encoding/xml"
+	"io"
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/faiface/pixel"
+	"github.com/faiface/pixel/imdraw"
+	"github.com/faiface/pixel/pixeltest"
+	"github.com/faiface/pixel/svg"
+)
+
+func render(t *testing.T, target *svg.Target) string {
+	t.Helper()
+	var buf bytes.Buffer
+	if _, err := target.WriteTo(&buf); err != nil {
+		t.Fatal(err)
+	}
+	// the document must be well-formed XML
+	dec := xml.NewDecoder(bytes.NewReader(buf.Bytes()))
+	for {
+		_, err := dec.Token()
+		if err != nil {
+			if err != io.EOF {
+				t.Fatalf("invalid XML: %v\n%s", err, buf.String())
+			}
+			break
+		}
+	}
+	return buf.String()
+}
+
+func TestTarget_Solid(t *testing.T) {
+	target := svg.NewTarget(pixel.R(0, 0, 100, 50))
+	target.SetMatrix(pixel.IM.Moved(pixel.V(10, 0)))
+	target.SetColorMask(pixel.Alpha(0.5))
+
+	imd := imdraw.New(nil)
+	imd.Color = pixel.RGB(1, 0, 0)
+	imd.Push(pixel.V(0, 0), pixel.V(20, 20))
+	imd.Rectangle(0)
+	imd.Draw(target)
+
+	out := render(t, target)
+	if got := strings.Count(out, "<polygon"); got != 2 {
+		t.Errorf("got %d polygons, want 2", got)
+	}
+	for _, want := range []string{
+		`points="10,0 30,20 30,0"`,
+		`fill="#ff0000" fill-opacity="0.5"`,
+		`<g transform="matrix(1 0 0 -1 0 50)">`,
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output doesn't contain %s:\n%s", want, out)
+		}
+	}
+}
+
+func TestTarget_Gradient(t *testing.T) {
+	target := svg.NewTarget(pixel.R(0, 0, 100, 100))
+	imd := imdraw.New(nil)
+	imd.Color = pixel.RGB(1, 0, 0)
+	imd.Push(pixel.V(0, 0))
+	imd.Color = pixel.RGB(0, 0, 1)
+	imd.Push(pixel.V(100, 0), pixel.V(100, 100))
+	imd.Polygon(0)
+	target.SetComposeMethod(pixel.ComposePlus)
+	imd.Draw(target)
+
+	out := render(t, target)
+	for _, want := range []string{
+		`<linearGradient id="gradient1" gradientUnits="userSpaceOnUse" x1="0" y1="0" x2="100" y2="0">`,
+		`<stop offset="0" stop-color="#ff0000"/><stop offset="1" stop-color="#0000ff"/>`,
+		`fill="url(#gradient1)" style="mix-blend-mode:plus-lighter"`,
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output doesn't contain %s:\n%s", want, out)
+		}
+	}
+}
+
+func TestTarget_Picture(t *testing.T) {
+	pic := pixel.MakePictureData(pixel.R(0, 0, 4, 4))
+	sprite := pixel.NewSprite(pic, pic.Bounds())
+
+	target := svg.NewTarget(pixel.R(0, 0, 100, 100))
+	sprite.Draw(target, pixel.IM.Scaled(pixel.ZV, 2).Moved(pixel.V(50, 50)))
+	sprite.DrawColorMask(target, pixel.IM, pixel.RGB(0, 1, 0))
+
+	out := render(t, target)
+	if got := strings.Count(out, "data:image/png;base64,"); got != 1 {
+		t.Errorf("picture embedded %d times, want 1", got)
+	}
+	// both triangles of a sprite share a single pattern
+	if got := strings.Count(out, "<pattern"); got != 2 {
+		t.Errorf("got %d patterns, want 2", got)
+	}
+	for _, want := range []string{
+		`patternTransform="matrix(2 0 0 2 46 46)"`,
+		`<feColorMatrix type="matrix" values="0 0 0 0 0 0 1 0 0 0 0 0 0 0 0 0 0 0 1 0"/>`,
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output doesn't contain %s:\n%s", want, out)
+		}
+	}
+
+	target.Clear()
+	if out := render(t, target); strings.Contains(out, "<polygon") {
+		t.Errorf("Clear didn't remove the polygons")
+	}
+}
+
+func TestParsePath(t *testing.T) {
+	tests := []struct {
+		name    string
+		d       string
+		in, out []pixel.Vec
+	}{
+		{"Lines", "M4 4 h24 v8 H4 z", []pixel.Vec{pixel.V(6.5, 6.5), pixel.V(25.5, 10.5)}, []pixel.Vec{pixel.V(6.5, 14.5)}},
+		{"Compact", "M4,4L28-0 28 12.5.5,12.5z", []pixel.Vec{pixel.V(27.5, 10.5)}, []pixel.Vec{pixel.V(29.5, 6.5)}},
+		{"Relative", "m4 4 l24 0 0 8 -24 0 z m0 12 h24 v8 h-24 z", []pixel.Vec{pixel.V(6.5, 6.5), pixel.V(6.5, 18.5)}, []pixel.Vec{pixel.V(6.5, 14.5)}},
+		// a half of a circle around (16, 16) with the radius of 12, bulging up (y points down)
+		{"Arc", "M4 16 A12 12 0 0 1 28 16 z", []pixel.Vec{pixel.V(16.5, 4.5)}, []pixel.Vec{pixel.V(16.5, 17.5), pixel.V(5.5, 5.5)}},
+		{"ArcSweep", "M4 16 A12 12 0 0 0 28 16 z", []pixel.Vec{pixel.V(16.5, 27.5)}, []pixel.Vec{pixel.V(16.5, 14.5)}},
+		{"Smooth", "M4 16 Q10 4 16 16 T28 16 z", []pixel.Vec{pixel.V(10.5, 11.5), pixel.V(22.5, 20.5)}, []pixel.Vec{pixel.V(10.5, 20.5), pixel.V(22.5, 11.5)}},
+		{"SmoothCubic", "M4 16 C4 4 16 4 16 16 S28 28 28 16 z", []pixel.Vec{pixel.V(10.5, 8.5), pixel.V(22.5, 23.5)}, []pixel.Vec{pixel.V(10.5, 20.5), pixel.V(22.5, 8.5)}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, err := svg.ParsePath(tt.d)
+			if err != nil {
+				t.Fatal(err)
+			}
+			c := pixeltest.NewCanvas(pixel.R(0, 0, 32, 32))
+			imd := imdraw.New(nil)
+			imd.Path(p, 0)
+			imd.Draw(c)
+			for _, pt := range tt.in {
+				if c.Color(pt).A == 0 {
+					t.Errorf("pixel %v not filled", pt)
+				}
+			}
+			for _, pt := range tt.out {
+				if c.Color(pt).A != 0 {
+					t.Errorf("pixel %v filled", pt)
+				}
+			}
+		})
+	}
+
+	for _, d := range []string{"L1 2", "M1", "M1 2 X", "M1 2 A1 1 0 2 0 3 3", "M1 2 z 3 4"} {
+		if _, err := svg.ParsePath(d); err == nil {
+			t.Errorf("parsed invalid path data %q", d)
+		}
+	}
+}
+
+const testDocument = `<?xml version="1.0"?>
+<svg xmlns="http://www.w3.org/2000/svg" width="64" height="64" viewBox="0 0 32 32">
+  <defs>
+    <rect id="hidden" width="32" height="32" fill="black"/>
+  </defs>
+  <title>test</title>
+  <g fill="red" stroke-width="2" transform="translate(2 2)">
+    <rect width="12" height="12" rx="2"/>
+    <circle cx="22" cy="6" r="6" fill="#00f" style="stroke: rgb(0, 255, 0); fill-opacity: 50%"/>
+  </g>
+  <path d="M2 18 H30 V30 H2 z M8 20 v8 h16 v-8 z" fill="#0f0" fill-rule="evenodd"/>
+  <polyline points="2,30 30,18" fill="none" stroke="blue"/>
+</svg>`
+
+func TestDecode(t *testing.T) {
+	img, err := svg.Decode(strings.NewReader(testDocument))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if img.Bounds() != pixel.R(0, 0, 64, 64) {
+		t.Errorf("Bounds() = %v, want %v", img.Bounds(), pixel.R(0, 0, 64, 64))
+	}
+	if len(img.Shapes) != 4 {
+		t.Fatalf("got %d shapes, want 4", len(img.Shapes))
+	}
+
+	circle := img.Shapes[1]
+	if circle.Fill != pixel.RGB(0, 0, 1).Scaled(0.5) || circle.Stroke != pixel.RGB(0, 1, 0) {
+		t.Errorf("circle colors are %v and %v", circle.Fill, circle.Stroke)
+	}
+	// the viewBox is scaled twice
+	if circle.StrokeWidth != 4 {
+		t.Errorf("circle stroke width is %v, want 4", circle.StrokeWidth)
+	}
+
+	c := pixeltest.NewCanvas(img.Bounds())
+	imd := imdraw.New(nil)
+	img.Draw(imd)
+	imd.Draw(c)
+
+	for _, tt := range []struct {
+		at   pixel.Vec
+		want pixel.RGBA
+	}{
+		// the y axis is flipped, so the group is at the top
+		{pixel.V(16.5, 48.5), pixel.RGB(1, 0, 0)},
+		{pixel.V(4.5, 59.5), pixel.RGBA{}},
+		{pixel.V(48.5, 48.5), pixel.RGB(0, 0, 1).Scaled(0.5)},
+		{pixel.V(48.5, 36.5), pixel.RGB(0, 1, 0)},
+		{pixel.V(8.5, 8.5), pixel.RGB(0, 1, 0)},
+		{pixel.V(32.5, 12.5), pixel.RGBA{}},
+	} {
+		// colors are stored with 8 bits per channel
+		got, diff := c.Color(tt.at), c.Color(tt.at).Sub(tt.want)
+		if math.Abs(diff.R)+math.Abs(diff.G)+math.Abs(diff.B)+math.Abs(diff.A) > 0.02 {
+			t.Errorf("pixel %v = %v, want %v", tt.at, got, tt.want)
+		}
+	}
+
+	if tri := img.Triangles(); tri.Len() == 0 || tri.Len()%3 != 0 {
+		t.Errorf("Triangles() has %d vertices", tri.Len())
+	}
+
+	pixeltest.AssertGolden(t, "testdata/image.png", c.Image(), pixeltest.Options{Threshold: 0.1})
+
+	t.Run("RoundedRect", func(t *testing.T) {
+		doc := `<svg width="100" height="50"><rect width="100" height="50" rx="10" fill="red"/></svg>`
+		img, err := svg.Decode(strings.NewReader(doc))
+		if err != nil {
+			t.Fatal(err)
+		}
+		c := pixeltest.NewCanvas(img.Bounds())
+		imd := imdraw.New(nil)
+		img.Draw(imd)
+		imd.Draw(c)
+
+		for _, tt := range []struct {
+			at   pixel.Vec
+			want pixel.RGBA
+		}{
+			// just inside the straight top edge, near the top-right corner
+			{pixel.V(60.5, 49.5), pixel.RGB(1, 0, 0)},
+			{pixel.V(88.5, 49.5), pixel.RGB(1, 0, 0)},
+			// inside and outside of the arc of the corner
+			{pixel.V(95.5, 47.5), pixel.RGB(1, 0, 0)},
+			{pixel.V(98.5, 49.5), pixel.RGBA{}},
+		} {
+			got, diff := c.Color(tt.at), c.Color(tt.at).Sub(tt.want)
+			if math.Abs(diff.R)+math.Abs(diff.G)+math.Abs(diff.B)+math.Abs(diff.A) > 0.02 {
+				t.Errorf("pixel %v = %v, want %v", tt.at, got, tt.want)
+			}
+		}
+	})
+
+	for _, doc := range []string{
+		`<html></html>`,
+		`<svg></svg>`,
+		`<svg width="8" height="8"><rect fill="#12"/></svg>`,
+		`<svg width="8" height="8"><path d="M1"/></svg>`,
+		`<svg width="8" height="8"><g transform="spin(3)"/></svg>`,
+	} {
+		if _, err := svg.Decode(strings.NewReader(doc)); err == nil {
+			t.Errorf("decoded invalid document %s", doc)
+		}
+	}
+}
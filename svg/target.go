@@ -0,0 +1,389 @@
+package svg
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"image/color"
+	"image/png"
+	"io"
+	"math"
+	"strconv"
+
+	"github.com/faiface/pixel"
+)
+
+// Target is a pixel.ComposeTarget, which converts everything drawn onto it into an SVG document.
+//
+//   t := svg.NewTarget(pixel.R(0, 0, 800, 600))
+//   imd.Draw(t)
+//   f, _ := os.Create("chart.svg")
+//   t.WriteTo(f)
+//
+// Each triangle becomes a polygon. Since SVG doesn't support interpolating colors across a
+// triangle, triangles with different vertex colors are filled with a linear gradient between the
+// two most different vertices, which is exact for most shapes generated by IMDraw, but only
+// approximate in general.
+//
+// Pictures are embedded as base64-encoded PNG images and triangles drawn with a Picture are filled
+// with a pattern of the image, transformed so that the Picture coordinates of the vertices match.
+// Vertex colors tint the Picture using a color matrix filter (averaged over the triangle).
+// Triangles with Intensity below 0.5 are drawn without the Picture.
+//
+// SVG supports only some of the compose methods: ComposeOver is the default and ComposePlus uses
+// the plus-lighter blend mode. All other compose methods are drawn as ComposeOver.
+type Target struct {
+	bounds pixel.Rect
+	mat    pixel.Matrix
+	col    pixel.RGBA
+	cmp    pixel.ComposeMethod
+
+	defs bytes.Buffer
+	body bytes.Buffer
+
+	pics     map[pixel.Picture]string
+	patterns map[string]string
+	filters  map[string]string
+	nextID   int
+}
+
+var _ pixel.ComposeTarget = (*Target)(nil)
+
+// NewTarget creates a new empty Target. The bounds are the area of the Pixel's coordinates that
+// will be visible in the resulting SVG document.
+func NewTarget(bounds pixel.Rect) *Target {
+	t := &Target{bounds: bounds.Norm()}
+	t.SetMatrix(pixel.IM)
+	t.SetColorMask(pixel.Alpha(1))
+	t.Clear()
+	return t
+}
+
+// Bounds returns the area of the Pixel's coordinates visible in the SVG document.
+func (t *Target) Bounds() pixel.Rect {
+	return t.bounds
+}
+
+// Clear removes everything drawn onto the Target.
+func (t *Target) Clear() {
+	t.defs.Reset()
+	t.body.Reset()
+	t.pics = make(map[pixel.Picture]string)
+	t.patterns = make(map[string]string)
+	t.filters = make(map[string]string)
+	t.nextID = 0
+}
+
+// SetMatrix sets a Matrix that every point will be projected by.
+func (t *Target) SetMatrix(m pixel.Matrix) {
+	t.mat = m
+}
+
+// SetColorMask sets a color that every color in triangles or a picture will be multiplied by.
+func (t *Target) SetColorMask(c color.Color) {
+	t.col = pixel.Alpha(1)
+	if c != nil {
+		t.col = pixel.ToRGBA(c)
+	}
+}
+
+// SetComposeMethod sets a Porter-Duff composition method to be used in the following draws onto
+// this Target. Only ComposeOver and ComposePlus are supported.
+func (t *Target) SetComposeMethod(cmp pixel.ComposeMethod) {
+	t.cmp = cmp
+}
+
+// MakeTriangles returns a specialized copy of the provided Triangles that draws onto this Target.
+func (t *Target) MakeTriangles(tri pixel.Triangles) pixel.TargetTriangles {
+	st := &svgTriangles{
+		tri: pixel.MakeTrianglesData(tri.Len()),
+		dst: t,
+	}
+	st.tri.Update(tri)
+	return st
+}
+
+// MakePicture returns a specialized copy of the provided Picture that draws onto this Target.
+func (t *Target) MakePicture(p pixel.Picture) pixel.TargetPicture {
+	if sp, ok := p.(*svgPicture); ok {
+		p = sp.pic
+	}
+	return &svgPicture{
+		pic: p,
+		dst: t,
+	}
+}
+
+// WriteTo writes the SVG document into the Writer.
+func (t *Target) WriteTo(w io.Writer) (n int64, err error) {
+	var buf bytes.Buffer
+	width, height := t.bounds.W(), t.bounds.H()
+	fmt.Fprintf(&buf, `<?xml version="1.0" encoding="UTF-8"?>`+"\n")
+	fmt.Fprintf(&buf,
+		`<svg xmlns="http://www.w3.org/2000/svg" xmlns:xlink="http://www.w3.org/1999/xlink" width="%s" height="%s" viewBox="0 0 %s %s">`+"\n",
+		num(width), num(height), num(width), num(height),
+	)
+	if t.defs.Len() > 0 {
+		fmt.Fprintf(&buf, "<defs>\n%s</defs>\n", t.defs.Bytes())
+	}
+	// flip the Y axis, Pixel's coordinates point up
+	fmt.Fprintf(&buf, `<g transform="matrix(1 0 0 -1 %s %s)">`+"\n", num(-t.bounds.Min.X), num(t.bounds.Max.Y))
+	buf.Write(t.body.Bytes())
+	fmt.Fprintf(&buf, "</g>\n</svg>\n")
+	return buf.WriteTo(w)
+}
+
+func (t *Target) newID(prefix string) string {
+	t.nextID++
+	return prefix + strconv.Itoa(t.nextID)
+}
+
+// pictureID returns the ID of the image element of the Picture, embedding it if necessary.
+func (t *Target) pictureID(pic pixel.Picture) string {
+	if id, ok := t.pics[pic]; ok {
+		return id
+	}
+	pd := pixel.PictureDataFromPicture(pic)
+	var img bytes.Buffer
+	if err := png.Encode(&img, pd.Image()); err != nil {
+		// encoding an in-memory image never fails
+		panic(err)
+	}
+	id := t.newID("pic")
+	r := pd.Rect
+	// the image is flipped back, because Pixel's coordinates point up
+	fmt.Fprintf(&t.defs,
+		`<image id="%s" x="%s" y="%s" width="%s" height="%s" transform="matrix(1 0 0 -1 0 %s)" preserveAspectRatio="none" xlink:href="data:image/png;base64,%s"/>`+"\n",
+		id, num(r.Min.X), num(r.Min.Y), num(r.W()), num(r.H()), num(r.Min.Y+r.Max.Y),
+		base64.StdEncoding.EncodeToString(img.Bytes()),
+	)
+	t.pics[pic] = id
+	return id
+}
+
+// patternID returns the ID of a pattern filled with the Picture transformed by the Matrix.
+func (t *Target) patternID(pic pixel.Picture, m pixel.Matrix) string {
+	picID := t.pictureID(pic)
+	key := picID + " " + matrix(m)
+	if id, ok := t.patterns[key]; ok {
+		return id
+	}
+	id := t.newID("pattern")
+	r := pic.Bounds()
+	fmt.Fprintf(&t.defs,
+		`<pattern id="%s" patternUnits="userSpaceOnUse" x="%s" y="%s" width="%s" height="%s" patternTransform="%s"><use xlink:href="#%s"/></pattern>`+"\n",
+		id, num(r.Min.X), num(r.Min.Y), num(r.W()), num(r.H()), matrix(m), picID,
+	)
+	t.patterns[key] = id
+	return id
+}
+
+// filterID returns the ID of a filter multiplying colors by the color.
+func (t *Target) filterID(c pixel.RGBA) string {
+	key := fmt.Sprint(c)
+	if id, ok := t.filters[key]; ok {
+		return id
+	}
+	id := t.newID("tint")
+	// the filter works with non-premultiplied colors
+	r, g, b := c.R, c.G, c.B
+	if c.A > 0 {
+		r, g, b = r/c.A, g/c.A, b/c.A
+	}
+	fmt.Fprintf(&t.defs,
+		`<filter id="%s" color-interpolation-filters="sRGB"><feColorMatrix type="matrix" values="%s 0 0 0 0 0 %s 0 0 0 0 0 %s 0 0 0 0 0 %s 0"/></filter>`+"\n",
+		id, num(r), num(g), num(b), num(c.A),
+	)
+	t.filters[key] = id
+	return id
+}
+
+// drawTriangle writes a single triangle, whose vertices are already projected and masked.
+func (t *Target) drawTriangle(v [3]vertex, pic pixel.Picture) {
+	var style string
+	if t.cmp == pixel.ComposePlus {
+		style = ` style="mix-blend-mode:plus-lighter"`
+	}
+	points := fmt.Sprintf("%s,%s %s,%s %s,%s",
+		num(v[0].pos.X), num(v[0].pos.Y),
+		num(v[1].pos.X), num(v[1].pos.Y),
+		num(v[2].pos.X), num(v[2].pos.Y),
+	)
+
+	if pic != nil && v[0].in+v[1].in+v[2].in >= 1.5 {
+		if m, ok := pictureMatrix(v); ok {
+			avg := v[0].col.Add(v[1].col).Add(v[2].col).Scaled(1.0 / 3)
+			var filter string
+			if avg != pixel.Alpha(1) {
+				filter = fmt.Sprintf(` filter="url(#%s)"`, t.filterID(avg))
+			}
+			fmt.Fprintf(&t.body, `<polygon points="%s" fill="url(#%s)"%s%s/>`+"\n",
+				points, t.patternID(pic, m), filter, style)
+			return
+		}
+	}
+
+	if v[0].col == v[1].col && v[1].col == v[2].col {
+		if v[0].col.A == 0 {
+			return
+		}
+		fmt.Fprintf(&t.body, `<polygon points="%s" %s%s/>`+"\n", points, fill(v[0].col), style)
+		return
+	}
+
+	// gradient between the two most different vertices
+	a, b := 0, 1
+	for _, pair := range [...][2]int{{0, 2}, {1, 2}} {
+		if colorDistance(v[pair[0]].col, v[pair[1]].col) > colorDistance(v[a].col, v[b].col) {
+			a, b = pair[0], pair[1]
+		}
+	}
+	id := t.newID("gradient")
+	fmt.Fprintf(&t.defs,
+		`<linearGradient id="%s" gradientUnits="userSpaceOnUse" x1="%s" y1="%s" x2="%s" y2="%s"><stop offset="0" %s/><stop offset="1" %s/></linearGradient>`+"\n",
+		id, num(v[a].pos.X), num(v[a].pos.Y), num(v[b].pos.X), num(v[b].pos.Y),
+		stopColor(v[a].col), stopColor(v[b].col),
+	)
+	fmt.Fprintf(&t.body, `<polygon points="%s" fill="url(#%s)"%s/>`+"\n", points, id, style)
+}
+
+type vertex struct {
+	pos pixel.Vec
+	col pixel.RGBA
+	pic pixel.Vec
+	in  float64
+}
+
+type svgTriangles struct {
+	tri *pixel.TrianglesData
+	dst *Target
+}
+
+func (st *svgTriangles) Len() int {
+	return st.tri.Len()
+}
+
+func (st *svgTriangles) SetLen(len int) {
+	st.tri.SetLen(len)
+}
+
+func (st *svgTriangles) Slice(i, j int) pixel.Triangles {
+	return &svgTriangles{
+		tri: st.tri.Slice(i, j).(*pixel.TrianglesData),
+		dst: st.dst,
+	}
+}
+
+func (st *svgTriangles) Update(t pixel.Triangles) {
+	st.tri.Update(t)
+}
+
+func (st *svgTriangles) Copy() pixel.Triangles {
+	return &svgTriangles{
+		tri: st.tri.Copy().(*pixel.TrianglesData),
+		dst: st.dst,
+	}
+}
+
+func (st *svgTriangles) draw(pic pixel.Picture) {
+	tri := *st.tri
+	for i := 0; i+2 < len(tri); i += 3 {
+		var v [3]vertex
+		for k := range v {
+			v[k] = vertex{
+				pos: st.dst.mat.Project(tri[i+k].Position),
+				col: st.dst.col.Mul(tri[i+k].Color),
+				pic: tri[i+k].Picture,
+				in:  tri[i+k].Intensity,
+			}
+		}
+		st.dst.drawTriangle(v, pic)
+	}
+}
+
+func (st *svgTriangles) Draw() {
+	st.draw(nil)
+}
+
+type svgPicture struct {
+	pic pixel.Picture
+	dst *Target
+}
+
+func (sp *svgPicture) Bounds() pixel.Rect {
+	return sp.pic.Bounds()
+}
+
+func (sp *svgPicture) Draw(t pixel.TargetTriangles) {
+	st := t.(*svgTriangles)
+	if st.dst != sp.dst {
+		panic(fmt.Errorf("(%T).Draw: TargetTriangles generated by different Target", sp))
+	}
+	st.draw(sp.pic)
+}
+
+// pictureMatrix returns the Matrix mapping the Picture coordinates of the vertices to their
+// positions. If the Picture coordinates are degenerate, false is returned.
+func pictureMatrix(v [3]vertex) (pixel.Matrix, bool) {
+	u1, u2 := v[1].pic.Sub(v[0].pic), v[2].pic.Sub(v[0].pic)
+	p1, p2 := v[1].pos.Sub(v[0].pos), v[2].pos.Sub(v[0].pos)
+	det := u1.X*u2.Y - u2.X*u1.Y
+	if math.Abs(det) < 1e-9 {
+		return pixel.Matrix{}, false
+	}
+	// linear part L satisfies L*u1 = p1 and L*u2 = p2
+	a := (p1.X*u2.Y - p2.X*u1.Y) / det
+	c := (p2.X*u1.X - p1.X*u2.X) / det
+	b := (p1.Y*u2.Y - p2.Y*u1.Y) / det
+	d := (p2.Y*u1.X - p1.Y*u2.X) / det
+	m := pixel.Matrix{a, b, c, d, 0, 0}
+	off := v[0].pos.Sub(m.Project(v[0].pic))
+	m[4], m[5] = off.X, off.Y
+	return m, true
+}
+
+func colorDistance(a, b pixel.RGBA) float64 {
+	d := a.Sub(b)
+	return d.R*d.R + d.G*d.G + d.B*d.B + d.A*d.A
+}
+
+// unpremultiply converts a Pixel's color to a CSS color and an opacity.
+func unpremultiply(c pixel.RGBA) (string, float64) {
+	if c.A <= 0 {
+		return "#000000", 0
+	}
+	to8 := func(x float64) uint8 {
+		return uint8(math.Round(pixel.Clamp(x/c.A, 0, 1) * 255))
+	}
+	return fmt.Sprintf("#%02x%02x%02x", to8(c.R), to8(c.G), to8(c.B)), pixel.Clamp(c.A, 0, 1)
+}
+
+func fill(c pixel.RGBA) string {
+	col, opacity := unpremultiply(c)
+	if opacity == 1 {
+		return fmt.Sprintf(`fill="%s"`, col)
+	}
+	return fmt.Sprintf(`fill="%s" fill-opacity="%s"`, col, num(opacity))
+}
+
+func stopColor(c pixel.RGBA) string {
+	col, opacity := unpremultiply(c)
+	if opacity == 1 {
+		return fmt.Sprintf(`stop-color="%s"`, col)
+	}
+	return fmt.Sprintf(`stop-color="%s" stop-opacity="%s"`, col, num(opacity))
+}
+
+func matrix(m pixel.Matrix) string {
+	return fmt.Sprintf("matrix(%s %s %s %s %s %s)", num(m[0]), num(m[1]), num(m[2]), num(m[3]), num(m[4]), num(m[5]))
+}
+
+// num formats a number with at most 3 decimal places.
+func num(x float64) string {
+	x = math.Round(x*1000) / 1000
+	if x == 0 {
+		// avoid negative zero
+		x = 0
+	}
+	return strconv.FormatFloat(x, 'f', -1, 64)
+}
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"image"
 	"image/draw"
+	"math"
 	"sort"
 	"unicode"
 
@@ -23,10 +24,15 @@ type Glyph struct {
 }
 
 // Atlas is a set of pre-drawn glyphs of a fixed set of runes. This allows for efficient text drawing.
+//
+// A dynamic Atlas, created using NewDynamicAtlas, draws glyphs on demand instead.
 type Atlas struct {
 	face       font.Face
 	pic        pixel.Picture
 	mapping    map[rune]Glyph
+	cache      *glyphCache
+	spread     float64
+	pad        float64
 	ascent     float64
 	descent    float64
 	lineHeight float64
@@ -39,6 +45,11 @@ type Atlas struct {
 //
 // Do not destroy or close the font.Face after creating the Atlas. Atlas still uses it.
 func NewAtlas(face font.Face, runeSets ...[]rune) *Atlas {
+	return newAtlas(face, atlasRunes(runeSets), 0)
+}
+
+// atlasRunes returns the union of the sets of runes, plus unicode.ReplacementChar.
+func atlasRunes(runeSets [][]rune) []rune {
 	seen := make(map[rune]bool)
 	runes := []rune{unicode.ReplacementChar}
 	for _, set := range runeSets {
@@ -49,14 +60,21 @@ func NewAtlas(face font.Face, runeSets ...[]rune) *Atlas {
 			}
 		}
 	}
+	return runes
+}
 
-	fixedMapping, fixedBounds := makeSquareMapping(face, runes, fixed.I(2))
+// newAtlas creates an Atlas of the runes. If the spread is not zero, the glyphs are converted to a
+// signed distance field reaching the spread around them.
+func newAtlas(face font.Face, runes []rune, spread float64) *Atlas {
+	pad := int(math.Ceil(spread))
+
+	fixedMapping, fixedBounds := makeSquareMapping(face, runes, fixed.I(2+2*pad))
 
 	atlasImg := image.NewRGBA(image.Rect(
-		fixedBounds.Min.X.Floor(),
-		fixedBounds.Min.Y.Floor(),
-		fixedBounds.Max.X.Ceil(),
-		fixedBounds.Max.Y.Ceil(),
+		fixedBounds.Min.X.Floor()-pad,
+		fixedBounds.Min.Y.Floor()-pad,
+		fixedBounds.Max.X.Ceil()+pad,
+		fixedBounds.Max.Y.Ceil()+pad,
 	))
 
 	for r, fg := range fixedMapping {
@@ -64,26 +82,36 @@ func NewAtlas(face font.Face, runeSets ...[]rune) *Atlas {
 		draw.Draw(atlasImg, dr, mask, maskp, draw.Src)
 	}
 
+	if spread > 0 {
+		signedDistanceField(atlasImg, spread)
+	}
+
 	bounds := pixel.R(
-		i2f(fixedBounds.Min.X),
-		i2f(fixedBounds.Min.Y),
-		i2f(fixedBounds.Max.X),
-		i2f(fixedBounds.Max.Y),
+		i2f(fixedBounds.Min.X)-float64(pad),
+		i2f(fixedBounds.Min.Y)-float64(pad),
+		i2f(fixedBounds.Max.X)+float64(pad),
+		i2f(fixedBounds.Max.Y)+float64(pad),
 	)
 
 	mapping := make(map[rune]Glyph)
 	for r, fg := range fixedMapping {
+		frame := pixel.R(
+			i2f(fg.frame.Min.X),
+			bounds.Max.Y-(i2f(fg.frame.Min.Y)-bounds.Min.Y),
+			i2f(fg.frame.Max.X),
+			bounds.Max.Y-(i2f(fg.frame.Max.Y)-bounds.Min.Y),
+		).Norm()
 		mapping[r] = Glyph{
 			Dot: pixel.V(
 				i2f(fg.dot.X),
 				bounds.Max.Y-(i2f(fg.dot.Y)-bounds.Min.Y),
 			),
 			Frame: pixel.R(
-				i2f(fg.frame.Min.X),
-				bounds.Max.Y-(i2f(fg.frame.Min.Y)-bounds.Min.Y),
-				i2f(fg.frame.Max.X),
-				bounds.Max.Y-(i2f(fg.frame.Max.Y)-bounds.Min.Y),
-			).Norm(),
+				frame.Min.X-float64(pad),
+				frame.Min.Y-float64(pad),
+				frame.Max.X+float64(pad),
+				frame.Max.Y+float64(pad),
+			),
 			Advance: i2f(fg.advance),
 		}
 	}
@@ -92,6 +120,8 @@ func NewAtlas(face font.Face, runeSets ...[]rune) *Atlas {
 		face:       face,
 		pic:        pixel.PictureDataFromImage(atlasImg),
 		mapping:    mapping,
+		spread:     spread,
+		pad:        float64(pad),
 		ascent:     i2f(face.Metrics().Ascent),
 		descent:    i2f(face.Metrics().Descent),
 		lineHeight: i2f(face.Metrics().Height),
@@ -105,13 +135,23 @@ func (a *Atlas) Picture() pixel.Picture {
 }
 
 // Contains reports wheter r in contained within the Atlas.
+//
+// A dynamic Atlas contains all runes the font face has glyph bounds of.
 func (a *Atlas) Contains(r rune) bool {
+	if a.cache != nil {
+		return a.cache.contains(r)
+	}
 	_, ok := a.mapping[r]
 	return ok
 }
 
 // Glyph returns the description of r within the Atlas.
+//
+// A dynamic Atlas draws the glyph of r, if it's not drawn yet.
 func (a *Atlas) Glyph(r rune) Glyph {
+	if a.cache != nil {
+		return a.cache.glyph(r)
+	}
 	return a.mapping[r]
 }
 
@@ -141,9 +181,7 @@ func (a *Atlas) LineHeight() float64 {
 // Rect is a rectangle where the glyph should be positioned. Frame is the glyph frame inside the
 // Atlas's Picture. NewDot is the new position of the dot.
 func (a *Atlas) DrawRune(prevR, r rune, dot pixel.Vec) (rect, frame, bounds pixel.Rect, newDot pixel.Vec) {
-	if !a.Contains(r) {
-		r = unicode.ReplacementChar
-	}
+	r = a.resolve(r)
 	if !a.Contains(unicode.ReplacementChar) {
 		return pixel.Rect{}, pixel.Rect{}, pixel.Rect{}, dot
 	}
@@ -159,6 +197,10 @@ func (a *Atlas) DrawRune(prevR, r rune, dot pixel.Vec) (rect, frame, bounds pixe
 
 	rect = glyph.Frame.Moved(dot.Sub(glyph.Dot))
 	bounds = rect
+	if a.pad > 0 {
+		// the frames of a signed distance field Atlas include the spread around the glyphs
+		bounds = pixel.R(rect.Min.X+a.pad, rect.Min.Y+a.pad, rect.Max.X-a.pad, rect.Max.Y-a.pad)
+	}
 
 	if bounds.W()*bounds.H() != 0 {
 		bounds = pixel.R(
@@ -1,8 +1,15 @@
 package text_test
 
 import (
+	"bytes"
+	"fmt"
+	"image"
 	"testing"
 
+	"golang.org/x/image/font/basicfont"
+
+	"github.com/faiface/pixel"
+	"github.com/faiface/pixel/pixeltest"
 	"github.com/faiface/pixel/text"
 )
 
@@ -22,3 +29,102 @@ func TestAtlas7x13(t *testing.T) {
 		}
 	}
 }
+
+func TestDynamicAtlas(t *testing.T) {
+	atlas := text.NewDynamicAtlas(basicfont.Face7x13, 64, 0)
+
+	if !atlas.Contains('a') || !atlas.Contains('日') {
+		t.Fatalf("Contains doesn't match the font face")
+	}
+
+	// glyphs are placed exactly like in a static Atlas
+	for _, r := range "aZ|_ " {
+		rect, _, bounds, dot := atlas.DrawRune(-1, r, pixel.V(10, 20))
+		wantRect, _, wantBounds, wantDot := text.Atlas7x13.DrawRune(-1, r, pixel.V(10, 20))
+		if rect != wantRect || bounds != wantBounds || dot != wantDot {
+			t.Errorf("DrawRune(%q) = %v, %v, %v, want %v, %v, %v", r, rect, bounds, dot, wantRect, wantBounds, wantDot)
+		}
+	}
+
+	render := func(atlas *text.Atlas) *image.RGBA {
+		return pixeltest.Render(pixel.R(0, 0, 64, 16), func(target pixel.Target) {
+			txt := text.New(pixel.V(2, 4), atlas)
+			fmt.Fprint(txt, "Dynamic")
+			txt.Draw(target, pixel.IM)
+		})
+	}
+	if !bytes.Equal(render(atlas).Pix, render(text.Atlas7x13).Pix) {
+		t.Errorf("text drawn with a dynamic Atlas differs from a static Atlas")
+	}
+}
+
+func TestDynamicAtlas_Eviction(t *testing.T) {
+	// a page holds 8 glyphs of 7x13 pixels with padding
+	atlas := text.NewDynamicAtlas(basicfont.Face7x13, 32, 1)
+	pic := atlas.Picture().(pixel.DynamicPicture)
+
+	canvas := pixeltest.NewCanvas(pixel.R(0, 0, 32, 16))
+	txt := text.New(pixel.V(2, 4), atlas)
+	fmt.Fprint(txt, "ab")
+	txt.Draw(canvas, pixel.IM)
+	want := canvas.Image().Pix
+
+	version := pic.Version()
+	frame := atlas.Glyph('c').Frame
+	if got := pic.Changed(version); !got.Contains(frame.Min) || !got.Contains(frame.Max) {
+		t.Errorf("Changed(%v) = %v, doesn't contain the new glyph %v", version, got, frame)
+	}
+
+	// fill the page and evict it, the glyphs of the Text are redrawn
+	for _, r := range "defghijkl" {
+		atlas.Glyph(r)
+	}
+	if atlas.Picture().Bounds() != pixel.R(0, 0, 32, 32) {
+		t.Errorf("Picture().Bounds() = %v, want a single page", atlas.Picture().Bounds())
+	}
+	canvas.Clear(pixel.Alpha(0))
+	txt.Draw(canvas, pixel.IM)
+	if !bytes.Equal(canvas.Image().Pix, want) {
+		t.Errorf("text drawn after eviction differs")
+	}
+}
+
+func TestSDFAtlas(t *testing.T) {
+	const spread = 4
+	atlas := text.NewSDFAtlas(basicfont.Face7x13, spread, text.ASCII)
+
+	if got := atlas.Spread(); got != spread {
+		t.Errorf("Spread() = %v, want %v", got, spread)
+	}
+	if got := text.Atlas7x13.Spread(); got != 0 {
+		t.Errorf("Atlas7x13.Spread() = %v, want 0", got)
+	}
+
+	sdf := atlas.Picture().(pixel.PictureColor)
+	bitmap := text.Atlas7x13.Picture().(pixel.PictureColor)
+
+	for _, r := range "aZ@|" {
+		// the glyphs are placed like in a plain Atlas, with the spread around them
+		rect, _, bounds, dot := atlas.DrawRune(-1, r, pixel.V(10, 20))
+		wantRect, _, wantBounds, wantDot := text.Atlas7x13.DrawRune(-1, r, pixel.V(10, 20))
+		wantRect = pixel.R(wantRect.Min.X-spread, wantRect.Min.Y-spread, wantRect.Max.X+spread, wantRect.Max.Y+spread)
+		if rect != wantRect || bounds != wantBounds || dot != wantDot {
+			t.Errorf("DrawRune(%q) = %v, %v, %v, want %v, %v, %v", r, rect, bounds, dot, wantRect, wantBounds, wantDot)
+		}
+
+		// the edge of the field matches the bitmap glyph
+		frame, bitmapFrame := atlas.Glyph(r).Frame, text.Atlas7x13.Glyph(r).Frame
+		for y := 0.5; y < bitmapFrame.H(); y++ {
+			for x := 0.5; x < bitmapFrame.W(); x++ {
+				in := bitmap.Color(bitmapFrame.Min.Add(pixel.V(x, y))).A > 0.5
+				d := sdf.Color(frame.Min.Add(pixel.V(x+spread, y+spread))).A
+				if in != (d > 0.5) {
+					t.Errorf("glyph %q at %v, %v: field is %v, bitmap inside is %v", r, x, y, d, in)
+				}
+			}
+		}
+		if d := sdf.Color(frame.Min.Add(pixel.V(0.5, 0.5))).A; d >= 0.5 {
+			t.Errorf("glyph %q: field in the corner is %v, want outside", r, d)
+		}
+	}
+}
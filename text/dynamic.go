@@ -0,0 +1,297 @@
+package text
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+	"unicode"
+
+	"github.com/faiface/pixel"
+	"golang.org/x/image/font"
+	"golang.org/x/image/math/fixed"
+)
+
+// NewDynamicAtlas creates a new Atlas, which draws glyphs from the given font face when they are
+// first used, instead of pre-drawing a fixed set of runes. This is useful for large sets of runes,
+// such as CJK characters, where only a few are actually used.
+//
+// The glyphs are drawn onto square pages of pageSize pixels, which are added to the Picture of the
+// Atlas as needed. Once there are maxPages pages, the least recently used page is cleared to make
+// space for new glyphs, unless maxPages is zero. Texts drawn with the Atlas redraw their glyphs
+// after that, but the pages should be large enough to hold all glyphs drawn in a single frame,
+// otherwise they keep evicting each other.
+//
+// The Picture of the Atlas is a pixel.DynamicPicture, so Targets update their copies of it
+// incrementally.
+//
+// Do not destroy or close the font.Face after creating the Atlas. Atlas still uses it.
+func NewDynamicAtlas(face font.Face, pageSize, maxPages int) *Atlas {
+	cache := &glyphCache{
+		face:     face,
+		pageSize: pageSize,
+		maxPages: maxPages,
+		pic:      &dynamicPicture{pd: pixel.MakePictureData(pixel.R(0, 0, float64(pageSize), 0))},
+		glyphs:   make(map[rune]cachedGlyph),
+		known:    make(map[rune]bool),
+	}
+	cache.grow()
+	return &Atlas{
+		face:       face,
+		pic:        cache.pic,
+		cache:      cache,
+		ascent:     i2f(face.Metrics().Ascent),
+		descent:    i2f(face.Metrics().Descent),
+		lineHeight: i2f(face.Metrics().Height),
+	}
+}
+
+// glyphPadding is the number of empty pixels around each glyph on a page, which prevents glyphs
+// from bleeding into each other.
+const glyphPadding = 1
+
+// glyphCache draws glyphs of a dynamic Atlas on demand.
+type glyphCache struct {
+	face     font.Face
+	pageSize int
+	maxPages int
+
+	pic    *dynamicPicture
+	pages  []*atlasPage
+	glyphs map[rune]cachedGlyph
+	known  map[rune]bool
+
+	// tick is incremented on each use of a glyph
+	tick      uint64
+	evictions uint64
+}
+
+type cachedGlyph struct {
+	glyph Glyph
+	// index of the page of the glyph, -1 for empty glyphs
+	page int
+}
+
+// atlasPage is a square area of the Picture, where glyphs are arranged into shelves.
+type atlasPage struct {
+	shelves []atlasShelf
+	top     int
+	used    uint64
+	runes   []rune
+}
+
+// atlasShelf is a row of glyphs on a page. Glyphs are added to the shelf from left to right.
+type atlasShelf struct {
+	y, h, x int
+}
+
+func (gc *glyphCache) contains(r rune) bool {
+	if known, ok := gc.known[r]; ok {
+		return known
+	}
+	_, _, ok := gc.face.GlyphBounds(r)
+	gc.known[r] = ok
+	return ok
+}
+
+func (gc *glyphCache) glyph(r rune) Glyph {
+	gc.tick++
+	if cg, ok := gc.glyphs[r]; ok {
+		if cg.page >= 0 {
+			gc.pages[cg.page].used = gc.tick
+		}
+		return cg.glyph
+	}
+	if !gc.contains(r) {
+		return Glyph{}
+	}
+
+	b, advance, _ := gc.face.GlyphBounds(r)
+	frame := image.Rect(b.Min.X.Floor(), b.Min.Y.Floor(), b.Max.X.Ceil(), b.Max.Y.Ceil())
+	cg := cachedGlyph{glyph: Glyph{Advance: i2f(advance)}, page: -1}
+	if frame.Empty() {
+		gc.glyphs[r] = cg
+		return cg.glyph
+	}
+
+	page, x, y, ok := gc.alloc(frame.Dx()+2*glyphPadding, frame.Dy()+2*glyphPadding)
+	if !ok {
+		// the glyph is larger than a page
+		gc.glyphs[r] = cg
+		return cg.glyph
+	}
+	x, y = x+glyphPadding, y+glyphPadding
+
+	// draw the glyph with it's frame at the origin and copy it onto the page upside down
+	img := image.NewRGBA(image.Rect(0, 0, frame.Dx(), frame.Dy()))
+	dot := fixed.P(-frame.Min.X, -frame.Min.Y)
+	dr, mask, maskp, _, _ := gc.face.Glyph(dot, r)
+	draw.Draw(img, dr, mask, maskp, draw.Src)
+
+	pd := gc.pic.pd
+	for iy := 0; iy < frame.Dy(); iy++ {
+		for ix := 0; ix < frame.Dx(); ix++ {
+			c := img.RGBAAt(ix, iy)
+			pd.Pix[(y+frame.Dy()-1-iy)*pd.Stride+x+ix] = c
+		}
+	}
+
+	cg.glyph.Frame = pixel.R(float64(x), float64(y), float64(x+frame.Dx()), float64(y+frame.Dy()))
+	cg.glyph.Dot = pixel.V(float64(x-frame.Min.X), float64(y+frame.Dy()+frame.Min.Y))
+	cg.page = page
+	gc.glyphs[r] = cg
+	gc.pages[page].runes = append(gc.pages[page].runes, r)
+	gc.pages[page].used = gc.tick
+	gc.pic.change(cg.glyph.Frame)
+
+	return cg.glyph
+}
+
+// alloc finds space of the size on a page, adding a new page or evicting the least recently used
+// one if needed. It returns the page and the position of the space in the Picture.
+func (gc *glyphCache) alloc(w, h int) (page, x, y int, ok bool) {
+	if w > gc.pageSize || h > gc.pageSize {
+		return 0, 0, 0, false
+	}
+
+	for i, p := range gc.pages {
+		if x, y, ok := p.alloc(w, h, gc.pageSize); ok {
+			return i, x, y + i*gc.pageSize, true
+		}
+	}
+
+	if gc.maxPages <= 0 || len(gc.pages) < gc.maxPages {
+		gc.grow()
+		page = len(gc.pages) - 1
+	} else {
+		page = 0
+		for i, p := range gc.pages {
+			if p.used < gc.pages[page].used {
+				page = i
+			}
+		}
+		gc.evict(page)
+	}
+
+	x, y, _ = gc.pages[page].alloc(w, h, gc.pageSize)
+	return page, x, y + page*gc.pageSize, true
+}
+
+// grow adds a new empty page on top of the Picture.
+func (gc *glyphCache) grow() {
+	old := gc.pic.pd
+	pd := pixel.MakePictureData(pixel.R(0, 0, float64(gc.pageSize), float64((len(gc.pages)+1)*gc.pageSize)))
+	copy(pd.Pix, old.Pix)
+	gc.pic.pd = pd
+	gc.pic.change(pd.Rect)
+	gc.pages = append(gc.pages, &atlasPage{})
+}
+
+// evict removes all glyphs from the page and clears it.
+func (gc *glyphCache) evict(page int) {
+	p := gc.pages[page]
+	for _, r := range p.runes {
+		delete(gc.glyphs, r)
+	}
+	*p = atlasPage{}
+
+	pd := gc.pic.pd
+	start := page * gc.pageSize * pd.Stride
+	for i := start; i < start+gc.pageSize*pd.Stride; i++ {
+		pd.Pix[i] = color.RGBA{}
+	}
+	gc.pic.change(pixel.R(0, float64(page*gc.pageSize), float64(gc.pageSize), float64((page+1)*gc.pageSize)))
+	gc.evictions++
+}
+
+// alloc finds space of the size on the page, using the lowest shelf that is high enough and
+// starting a new shelf if there is none.
+func (p *atlasPage) alloc(w, h, size int) (x, y int, ok bool) {
+	best := -1
+	for i, s := range p.shelves {
+		if s.h >= h && s.x+w <= size && (best < 0 || s.h < p.shelves[best].h) {
+			best = i
+		}
+	}
+	if best < 0 {
+		if p.top+h > size {
+			return 0, 0, false
+		}
+		p.shelves = append(p.shelves, atlasShelf{y: p.top, h: h})
+		p.top += h
+		best = len(p.shelves) - 1
+	}
+	s := &p.shelves[best]
+	x, y = s.x, s.y
+	s.x += w
+	return x, y, true
+}
+
+// dynamicPicture is a pixel.DynamicPicture of a PictureData, which keeps track of it's recent
+// changes.
+type dynamicPicture struct {
+	pd      *pixel.PictureData
+	version uint64
+	changes []pictureChange
+}
+
+type pictureChange struct {
+	version uint64
+	rect    pixel.Rect
+}
+
+// maxPictureChanges is the number of recent changes remembered by a dynamicPicture.
+const maxPictureChanges = 64
+
+func (dp *dynamicPicture) Bounds() pixel.Rect {
+	return dp.pd.Bounds()
+}
+
+func (dp *dynamicPicture) Color(at pixel.Vec) pixel.RGBA {
+	return dp.pd.Color(at)
+}
+
+func (dp *dynamicPicture) Version() uint64 {
+	return dp.version
+}
+
+func (dp *dynamicPicture) Changed(since uint64) pixel.Rect {
+	if since >= dp.version {
+		return pixel.Rect{}
+	}
+	if len(dp.changes) == 0 || dp.changes[0].version > since+1 {
+		return dp.Bounds()
+	}
+	changed := pixel.Rect{}
+	for _, c := range dp.changes {
+		if c.version > since {
+			changed = unionBounds(changed, c.rect)
+		}
+	}
+	return changed
+}
+
+func (dp *dynamicPicture) change(rect pixel.Rect) {
+	dp.version++
+	dp.changes = append(dp.changes, pictureChange{version: dp.version, rect: rect})
+	if len(dp.changes) > maxPictureChanges {
+		dp.changes = dp.changes[1:]
+	}
+}
+
+// resolve returns the rune drawn by the Atlas for r, which is unicode.ReplacementChar if the Atlas
+// doesn't contain r.
+func (a *Atlas) resolve(r rune) rune {
+	if !a.Contains(r) {
+		return unicode.ReplacementChar
+	}
+	return r
+}
+
+// evictions returns the number of times the Atlas evicted glyphs, changing the frames of glyphs
+// drawn before.
+func (a *Atlas) evictions() uint64 {
+	if a.cache == nil {
+		return 0
+	}
+	return a.cache.evictions
+}
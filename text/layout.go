@@ -0,0 +1,384 @@
+package text
+
+import (
+	"math"
+	"strings"
+	"unicode"
+
+	"github.com/faiface/pixel"
+)
+
+// Alignment is the horizontal alignment of lines of a Text relative to it's Orig.
+type Alignment int
+
+const (
+	// AlignLeft starts lines at the Orig.
+	AlignLeft Alignment = iota
+
+	// AlignCenter centers lines around the Orig.
+	AlignCenter
+
+	// AlignRight ends lines at the Orig.
+	AlignRight
+
+	// AlignJustify starts lines at the Orig and stretches wrapped lines to the WrapWidth by
+	// widening their spaces. The last line of each paragraph is aligned to the left.
+	AlignJustify
+)
+
+// VerticalAlignment is the vertical alignment of the whole block of text of a Text relative to
+// it's Orig.
+type VerticalAlignment int
+
+const (
+	// AlignTop places the baseline of the first line at the Orig.
+	AlignTop VerticalAlignment = iota
+
+	// AlignMiddle centers the block of text around the Orig. The block spans from the ascent of the
+	// first line to the descent of the last line.
+	AlignMiddle
+
+	// AlignBottom places the baseline of the last line at the Orig.
+	AlignBottom
+)
+
+const (
+	softHyphen     = '\u00ad'
+	zeroWidthSpace = '\u200b'
+)
+
+// layoutItem is a rune written to a Text with it's color and font.
+type layoutItem struct {
+	r     rune
+	col   pixel.RGBA
+	atlas *Atlas
+	scale float64
+
+	// image, if not nil, is drawn instead of the rune, sitting on the baseline
+	image  *pixel.Sprite
+	effect Effect
+}
+
+type layoutLine struct {
+	items []layoutItem
+	// wrapped lines are followed by a line of the same paragraph
+	wrapped bool
+	// index of the first item of the line in all written items
+	src int
+}
+
+// textLayout holds the parameters of laying out items into lines.
+type textLayout struct {
+	orig          pixel.Vec
+	wrapWidth     float64
+	align         Alignment
+	verticalAlign VerticalAlignment
+	tabWidth      float64
+
+	// lineHeight is the distance between baselines. If zero, it's computed for each line from the
+	// fonts of it's items.
+	lineHeight float64
+
+	// atlas and scale are the font of empty lines
+	atlas *Atlas
+	scale float64
+}
+
+// laidOut reports whether the text is laid out, instead of being written from the Dot. Once some
+// text was laid out, the Text stays laid out until it's cleared.
+func (txt *Text) laidOut() bool {
+	return txt.WrapWidth > 0 || txt.Align != AlignLeft || txt.VerticalAlign != AlignTop || len(txt.items) > 0
+}
+
+func (txt *Text) textLayout() textLayout {
+	return textLayout{
+		orig:          txt.Orig,
+		wrapWidth:     txt.WrapWidth,
+		align:         txt.Align,
+		verticalAlign: txt.VerticalAlign,
+		tabWidth:      txt.TabWidth,
+		lineHeight:    txt.LineHeight,
+		atlas:         txt.atlas,
+		scale:         1,
+	}
+}
+
+// layout wraps and aligns the items and calls glyph for each glyph, with the index of it's item.
+// The returned Vec is the position of the dot after the last item.
+func (l textLayout) layout(items []layoutItem, glyph func(src int, item layoutItem, rect, frame, bounds pixel.Rect)) pixel.Vec {
+	var lines []layoutLine
+	start := 0
+	for i := 0; i <= len(items); i++ {
+		if i < len(items) && items[i].r != '\n' {
+			continue
+		}
+		lines = append(lines, l.wrap(items[start:i], start)...)
+		start = i + 1
+	}
+
+	// baselines of the lines, relative to the first one
+	baselines := make([]float64, len(lines))
+	var top, bottom, y float64
+	for i, line := range lines {
+		ascent, descent, height := l.metrics(line.items)
+		if i == 0 {
+			top = ascent
+		} else {
+			y -= height
+		}
+		baselines[i] = y
+		bottom = y - descent
+	}
+
+	shift := l.orig.Y
+	switch l.verticalAlign {
+	case AlignMiddle:
+		shift -= (top + bottom) / 2
+	case AlignBottom:
+		shift -= baselines[len(baselines)-1]
+	}
+
+	var dot pixel.Vec
+	for i, line := range lines {
+		y := baselines[i] + shift
+		width, _ := l.placeLine(line.items, pixel.V(0, y), 0, nil)
+
+		x, gap := l.orig.X, 0.0
+		switch l.align {
+		case AlignCenter:
+			x -= width / 2
+		case AlignRight:
+			x -= width
+		case AlignJustify:
+			spaces := 0
+			for _, item := range line.items {
+				if item.r == ' ' {
+					spaces++
+				}
+			}
+			if line.wrapped && spaces > 0 {
+				gap = math.Max(0, l.wrapWidth-width) / float64(spaces)
+			}
+		}
+
+		src := line.src
+		_, dot = l.placeLine(line.items, pixel.V(x, y), gap, func(i int, item layoutItem, rect, frame, bounds pixel.Rect) {
+			glyph(src+i, item, rect, frame, bounds)
+		})
+	}
+	return dot
+}
+
+// metrics returns the ascent and the descent of a line and it's distance from the baseline of the
+// previous line.
+func (l textLayout) metrics(items []layoutItem) (ascent, descent, height float64) {
+	if l.lineHeight > 0 {
+		return l.atlas.Ascent(), l.atlas.Descent(), l.lineHeight
+	}
+	if len(items) == 0 {
+		return l.atlas.Ascent() * l.scale, l.atlas.Descent() * l.scale, l.atlas.LineHeight() * l.scale
+	}
+	for _, item := range items {
+		a, d, h := item.atlas.Ascent()*item.scale, item.atlas.Descent()*item.scale, item.atlas.LineHeight()*item.scale
+		if item.image != nil {
+			a = item.image.Frame().H() * item.scale
+			h = math.Max(h, a+d)
+		}
+		ascent, descent, height = math.Max(ascent, a), math.Max(descent, d), math.Max(height, h)
+	}
+	return ascent, descent, height
+}
+
+// placeLine places the glyphs of a line starting at the dot and calls glyph for each of them, if
+// not nil. The gap is added after each space. It returns the width of the line without trailing
+// spaces and the position of the dot after it.
+//
+// Soft hyphens are only drawn at the end of the line.
+func (l textLayout) placeLine(items []layoutItem, dot pixel.Vec, gap float64, glyph func(i int, item layoutItem, rect, frame, bounds pixel.Rect)) (width float64, end pixel.Vec) {
+	x0 := dot.X
+	prev := layoutItem{r: -1}
+	for i, item := range items {
+		r := item.r
+		switch {
+		case item.image != nil:
+			frame := item.image.Frame()
+			rect := pixel.Rect{Min: dot, Max: dot.Add(pixel.V(frame.W(), frame.H()).Scaled(item.scale))}
+			if glyph != nil {
+				glyph(i, item, rect, frame, rect)
+			}
+			dot.X = rect.Max.X
+			width = dot.X - x0
+			prev = layoutItem{r: -1}
+			continue
+		case r == softHyphen && i < len(items)-1:
+			continue
+		case r == softHyphen:
+			r = '-'
+		case r == zeroWidthSpace || r == '\u2060' || r == '\ufeff':
+			continue
+		case r == '\t':
+			rem := math.Mod(dot.X-x0, l.tabWidth)
+			rem = math.Mod(rem, rem+l.tabWidth)
+			if rem == 0 {
+				rem = l.tabWidth
+			}
+			dot.X += rem
+			prev = layoutItem{r: -1}
+			continue
+		case r == '\r':
+			dot.X = x0
+			prev = layoutItem{r: -1}
+			continue
+		}
+
+		// kerning only applies between runes of the same font
+		prevR := prev.r
+		if prev.atlas != item.atlas || prev.scale != item.scale {
+			prevR = -1
+		}
+
+		var rect, frame, bounds pixel.Rect
+		if item.scale == 1 {
+			rect, frame, bounds, dot = item.atlas.DrawRune(prevR, r, dot)
+		} else {
+			var newDot pixel.Vec
+			rect, frame, bounds, newDot = item.atlas.DrawRune(prevR, r, pixel.ZV)
+			m := pixel.IM.Scaled(pixel.ZV, item.scale).Moved(dot)
+			rect = pixel.Rect{Min: m.Project(rect.Min), Max: m.Project(rect.Max)}
+			bounds = pixel.Rect{Min: m.Project(bounds.Min), Max: m.Project(bounds.Max)}
+			dot = m.Project(newDot)
+		}
+		if glyph != nil {
+			drawn := item
+			drawn.r = r
+			glyph(i, drawn, rect, frame, bounds)
+		}
+		if !isBreakSpace(r) {
+			width = dot.X - x0
+		}
+		if r == ' ' {
+			dot.X += gap
+		}
+		prev = item
+		prev.r = r
+	}
+	return width, dot
+}
+
+// wrap splits a paragraph into lines no longer than the wrap width, at line break opportunities.
+// Words longer than a whole line are hyphenated.
+func (l textLayout) wrap(par []layoutItem, src int) []layoutLine {
+	if l.wrapWidth <= 0 {
+		return []layoutLine{{items: par, src: src}}
+	}
+
+	var (
+		lines []layoutLine
+		// the current line starts at par[cur]
+		cur int
+	)
+	newLine := func(items []layoutItem, next int) {
+		lines = append(lines, layoutLine{items: trimSpace(items), wrapped: true, src: src + cur})
+		cur = next
+	}
+
+	for i := 0; i < len(par); {
+		// the next segment ends at a break opportunity
+		j := i + 1
+		for j < len(par) && !canBreak(par[j-1].r, par[j].r) {
+			j++
+		}
+
+		if cur < i && l.width(par[cur:j]) > l.wrapWidth {
+			newLine(par[cur:i], i)
+		}
+		if cur == i {
+			seg := par[i:j]
+			for len(trimSpace(seg)) > 1 && l.width(seg) > l.wrapWidth {
+				n := l.hyphenate(trimSpace(seg))
+				hyphenated := append(seg[:n:n], hyphen(seg[n-1]))
+				i += n
+				newLine(hyphenated, i)
+				seg = seg[n:]
+			}
+		}
+		i = j
+	}
+
+	lines = append(lines, layoutLine{items: par[cur:], src: src + cur})
+	return lines
+}
+
+// hyphenate returns the number of items of a word, which fit into the wrap width together with a
+// hyphen. At least one item is always returned.
+func (l textLayout) hyphenate(word []layoutItem) int {
+	n := 1
+	for n+1 < len(word) {
+		prefix := append(word[:n+1:n+1], hyphen(word[n]))
+		if l.width(prefix) > l.wrapWidth {
+			break
+		}
+		n++
+	}
+	return n
+}
+
+func (l textLayout) width(items []layoutItem) float64 {
+	width, _ := l.placeLine(items, pixel.ZV, 0, nil)
+	return width
+}
+
+// hyphen returns a soft hyphen in the style of the item.
+func hyphen(item layoutItem) layoutItem {
+	item.r = softHyphen
+	item.image = nil
+	return item
+}
+
+func trimSpace(items []layoutItem) []layoutItem {
+	for len(items) > 0 && isBreakSpace(items[len(items)-1].r) {
+		items = items[:len(items)-1]
+	}
+	return items
+}
+
+// canBreak reports whether a line can be broken between the two runes. This is a simplified
+// subset of the Unicode line breaking algorithm (UAX #14): lines break after spaces and hyphens,
+// and around ideographs, but not before closing and after opening punctuation.
+func canBreak(a, b rune) bool {
+	switch {
+	case isGlue(a) || isGlue(b):
+		return false
+	case isBreakSpace(b):
+		return false
+	case isBreakSpace(a):
+		return true
+	case a == '-' || a == '\u2010' || a == '\u2013' || a == softHyphen:
+		return !unicode.IsDigit(b)
+	case unicode.In(b, unicode.Pe, unicode.Pf) || strings.ContainsRune("!,.:;?、。，．！？：；", b):
+		return false
+	case unicode.In(a, unicode.Ps, unicode.Pi):
+		return false
+	case isIdeographic(a) || isIdeographic(b):
+		return true
+	}
+	return false
+}
+
+// isBreakSpace reports whether r is a space a line can be broken after.
+func isBreakSpace(r rune) bool {
+	return r == zeroWidthSpace || (unicode.IsSpace(r) && !isGlue(r))
+}
+
+// isGlue reports whether r prevents breaking lines around it, such as a no-break space.
+func isGlue(r rune) bool {
+	switch r {
+	case '\u00a0', '\u2007', '\u202f', '\u2060', '\ufeff':
+		return true
+	}
+	return false
+}
+
+func isIdeographic(r rune) bool {
+	return unicode.In(r, unicode.Han, unicode.Hiragana, unicode.Katakana, unicode.Hangul)
+}
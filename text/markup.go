@@ -0,0 +1,151 @@
+package text
+
+import (
+	"image/color"
+	"strconv"
+	"strings"
+
+	"github.com/faiface/pixel"
+	"github.com/pkg/errors"
+)
+
+// ParseMarkup parses rich text markup into Spans, starting in the Style of the Rich. The markup
+// is text with tags in square brackets, which change the style of the text until they are closed
+// by a matching closing tag:
+//
+//   [b]bold[/b]                   the Bold font
+//   [i]italic[/i]                 the Italic font, or BoldItalic together with [b]
+//   [color=#f00]red[/color]       color in #rgb, #rgba, #rrggbb or #rrggbbaa format
+//   [size=2]big[/size]            the Scale of the text
+//   [wave]wavy[/wave]             EffectWave
+//   [shake]shaky[/shake]          EffectShake
+//
+// The [img=name] tag inserts the image of the name from the Images of the Rich and has no closing
+// tag. Two opening brackets "[[" are written as a single bracket.
+//
+// Tags left open at the end of the markup are closed automatically.
+func (rt *Rich) ParseMarkup(markup string) ([]Span, error) {
+	type tag struct {
+		name         string
+		style        Style
+		bold, italic bool
+	}
+
+	var (
+		spans        []Span
+		text         strings.Builder
+		style        = rt.Style
+		bold, italic bool
+		open         []tag
+	)
+	flush := func() {
+		if text.Len() > 0 {
+			spans = append(spans, Span{Text: text.String(), Style: style})
+			text.Reset()
+		}
+	}
+
+	for s := markup; s != ""; {
+		i := strings.IndexByte(s, '[')
+		if i < 0 {
+			text.WriteString(s)
+			break
+		}
+		text.WriteString(s[:i])
+		s = s[i:]
+		if strings.HasPrefix(s, "[[") {
+			text.WriteByte('[')
+			s = s[2:]
+			continue
+		}
+
+		end := strings.IndexByte(s, ']')
+		if end < 0 {
+			return nil, errors.Errorf("unterminated tag %q", s)
+		}
+		name, arg := s[1:end], ""
+		if eq := strings.IndexByte(name, '='); eq >= 0 {
+			name, arg = name[:eq], name[eq+1:]
+		}
+		s = s[end+1:]
+
+		flush()
+
+		if strings.HasPrefix(name, "/") {
+			name = name[1:]
+			if len(open) == 0 || open[len(open)-1].name != name {
+				return nil, errors.Errorf("unexpected closing tag [/%s]", name)
+			}
+			t := open[len(open)-1]
+			open = open[:len(open)-1]
+			style, bold, italic = t.style, t.bold, t.italic
+			continue
+		}
+
+		if name == "img" {
+			img, ok := rt.Images[arg]
+			if !ok {
+				return nil, errors.Errorf("unknown image %q", arg)
+			}
+			spans = append(spans, Span{Style: style, Image: img})
+			continue
+		}
+
+		open = append(open, tag{name: name, style: style, bold: bold, italic: italic})
+		switch name {
+		case "b":
+			bold = true
+			style.Atlas = rt.Fonts.font(bold, italic)
+		case "i":
+			italic = true
+			style.Atlas = rt.Fonts.font(bold, italic)
+		case "color":
+			col, err := parseColor(arg)
+			if err != nil {
+				return nil, err
+			}
+			style.Color = col
+		case "size":
+			scale, err := strconv.ParseFloat(arg, 64)
+			if err != nil || scale <= 0 {
+				return nil, errors.Errorf("invalid size %q", arg)
+			}
+			style.Scale = scale
+		case "wave":
+			style.Effect = EffectWave
+		case "shake":
+			style.Effect = EffectShake
+		default:
+			return nil, errors.Errorf("unknown tag [%s]", name)
+		}
+	}
+
+	flush()
+	return spans, nil
+}
+
+// parseColor parses a hexadecimal color in the #rgb, #rgba, #rrggbb or #rrggbbaa format.
+func parseColor(s string) (color.Color, error) {
+	hex := strings.TrimPrefix(s, "#")
+	if len(hex) == 3 || len(hex) == 4 {
+		var long strings.Builder
+		for _, c := range hex {
+			long.WriteRune(c)
+			long.WriteRune(c)
+		}
+		hex = long.String()
+	}
+	if len(hex) == 6 {
+		hex += "ff"
+	}
+	v, err := strconv.ParseUint(hex, 16, 32)
+	if !strings.HasPrefix(s, "#") || len(hex) != 8 || err != nil {
+		return nil, errors.Errorf("invalid color %q", s)
+	}
+	a := float64(v&0xff) / 0xff
+	return pixel.RGB(
+		float64(v>>24)/0xff,
+		float64(v>>16&0xff)/0xff,
+		float64(v>>8&0xff)/0xff,
+	).Mul(pixel.Alpha(a)), nil
+}
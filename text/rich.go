@@ -0,0 +1,396 @@
+package text
+
+import (
+	"image/color"
+	"math"
+
+	"github.com/faiface/pixel"
+)
+
+// Effect is an animation of the glyphs of a Rich, driven by the time passed to it's Update method.
+type Effect int
+
+const (
+	// EffectNone leaves the glyphs in place.
+	EffectNone Effect = iota
+
+	// EffectWave moves the glyphs up and down in a wave travelling along the text.
+	EffectWave
+
+	// EffectShake jitters the glyphs randomly.
+	EffectShake
+)
+
+// Fonts is a family of Atlases of the same font face in different styles. Bold, Italic and
+// BoldItalic are optional, missing styles fall back to Bold and then Regular.
+type Fonts struct {
+	Regular, Bold, Italic, BoldItalic *Atlas
+}
+
+func (f Fonts) font(bold, italic bool) *Atlas {
+	switch {
+	case bold && italic && f.BoldItalic != nil:
+		return f.BoldItalic
+	case bold && f.Bold != nil:
+		return f.Bold
+	case italic && f.Italic != nil:
+		return f.Italic
+	}
+	return f.Regular
+}
+
+// Style is the style of a Span of rich text.
+type Style struct {
+	// Atlas is the font of the text. If nil, the Regular font of the Rich is used.
+	Atlas *Atlas
+
+	// Color is the color of the text. If nil, white is used.
+	Color color.Color
+
+	// Scale is the size of the text relative to the size of the Atlas. If zero, 1 is used.
+	Scale float64
+
+	// Effect is the animation of the text.
+	Effect Effect
+}
+
+// Span is a piece of rich text in a single Style.
+type Span struct {
+	Text  string
+	Style Style
+
+	// Image, if not nil, is drawn inline instead of the Text. It sits on the baseline, scaled by
+	// the Scale of the Style and multiplied by it's Color.
+	Image *pixel.Sprite
+}
+
+// Rich draws text consisting of runs in different colors, sizes and fonts, with inline images.
+// Unlike Text, which draws with a single Atlas, Rich draws glyphs from multiple Atlases and
+// Pictures, all sitting on a shared baseline of each line.
+//
+// Rich text is written either as Spans, or using a simple markup:
+//   rt := text.NewRich(pixel.ZV, text.Fonts{Regular: regular, Bold: bold})
+//   rt.Images = map[string]*pixel.Sprite{"coin": coin}
+//   err := rt.WriteMarkup("[b]Gold:[/b] 50 [img=coin] [color=#f00][wave]Hurry![/wave][/color]")
+//
+// See ParseMarkup for the supported tags.
+//
+// Rich is always laid out from the Orig, the same way as a Text with wrapping or alignment. The
+// layout is updated whenever the fields change.
+type Rich struct {
+	// Orig specifies the text origin, the first dot position of the text.
+	Orig pixel.Vec
+
+	// LineHeight is the vertical distance between two lines of text. If zero, each line is as
+	// high as it's largest font or image.
+	LineHeight float64
+
+	// TabWidth is the horizontal tab width. Tab characters will align to the multiples of this
+	// width.
+	TabWidth float64
+
+	// WrapWidth, Align and VerticalAlign work the same way as in Text.
+	WrapWidth     float64
+	Align         Alignment
+	VerticalAlign VerticalAlignment
+
+	// Fonts are the Atlases used by markup. The Regular font is also used for Spans without an
+	// Atlas and must not be nil.
+	Fonts Fonts
+
+	// Style is the style markup starts with.
+	Style Style
+
+	// Images are the images available to markup by their names.
+	Images map[string]*pixel.Sprite
+
+	items  []layoutItem
+	glyphs []richGlyph
+	bounds pixel.Rect
+	laid   textLayout
+	dirty  bool
+
+	atlases   []*Atlas
+	evictions uint64
+
+	time     float64
+	animated bool
+
+	mat     pixel.Matrix
+	col     pixel.RGBA
+	redraw  bool
+	batches []*richBatch
+}
+
+// richGlyph is a laid out item.
+type richGlyph struct {
+	item        layoutItem
+	src         int
+	rect, frame pixel.Rect
+}
+
+// richBatch holds the triangles of all glyphs drawn from a single Picture.
+type richBatch struct {
+	pic pixel.Picture
+	tri pixel.TrianglesData
+	d   pixel.Drawer
+}
+
+// NewRich creates a new empty Rich with it's Orig set to orig, using the Fonts.
+func NewRich(orig pixel.Vec, fonts Fonts) *Rich {
+	return &Rich{
+		Orig:     orig,
+		TabWidth: fonts.Regular.Glyph(' ').Advance * 4,
+		Fonts:    fonts,
+		mat:      pixel.IM,
+		col:      pixel.Alpha(1),
+		dirty:    true,
+	}
+}
+
+// Bounds returns the bounding box of the text currently written to the Rich excluding whitespace
+// and effects.
+//
+// If the Rich is empty, a zero rectangle is returned.
+func (rt *Rich) Bounds() pixel.Rect {
+	rt.layout()
+	return rt.bounds
+}
+
+// Clear removes all written text from the Rich.
+func (rt *Rich) Clear() {
+	rt.items = rt.items[:0]
+	rt.dirty = true
+}
+
+// WriteSpans writes the Spans to the Rich.
+func (rt *Rich) WriteSpans(spans ...Span) {
+	for _, span := range spans {
+		item := layoutItem{
+			col:    pixel.Alpha(1),
+			atlas:  span.Style.Atlas,
+			scale:  span.Style.Scale,
+			effect: span.Style.Effect,
+		}
+		if span.Style.Color != nil {
+			item.col = pixel.ToRGBA(span.Style.Color)
+		}
+		if item.atlas == nil {
+			item.atlas = rt.Fonts.Regular
+		}
+		if item.scale == 0 {
+			item.scale = 1
+		}
+
+		if span.Image != nil {
+			item.r = '\ufffc'
+			item.image = span.Image
+			rt.items = append(rt.items, item)
+			continue
+		}
+		for _, r := range span.Text {
+			item.r = r
+			rt.items = append(rt.items, item)
+		}
+	}
+	rt.dirty = true
+}
+
+// WriteMarkup parses the markup using ParseMarkup and writes the resulting Spans to the Rich. If
+// the markup is invalid, nothing is written.
+func (rt *Rich) WriteMarkup(markup string) error {
+	spans, err := rt.ParseMarkup(markup)
+	if err != nil {
+		return err
+	}
+	rt.WriteSpans(spans...)
+	return nil
+}
+
+// Update advances the time of the effects by dt seconds.
+func (rt *Rich) Update(dt float64) {
+	rt.time += dt
+	if rt.animated {
+		rt.redraw = true
+	}
+}
+
+// Draw draws all text written to the Rich to the provided Target. The text is transformed by the
+// provided Matrix.
+//
+// This method is equivalent to calling DrawColorMask with nil color mask.
+func (rt *Rich) Draw(t pixel.Target, matrix pixel.Matrix) {
+	rt.DrawColorMask(t, matrix, nil)
+}
+
+// DrawColorMask draws all text written to the Rich to the provided Target. The text is transformed
+// by the provided Matrix and masked by the provided color mask.
+//
+// Glyphs are drawn in batches by their Pictures, so glyphs from different Atlases or images may not
+// overlap in the order they were written.
+func (rt *Rich) DrawColorMask(t pixel.Target, matrix pixel.Matrix, mask color.Color) {
+	if mask == nil {
+		mask = pixel.Alpha(1)
+	}
+	rgba := pixel.ToRGBA(mask)
+	if matrix != rt.mat || rgba != rt.col {
+		rt.mat, rt.col = matrix, rgba
+		rt.redraw = true
+	}
+
+	rt.layout()
+	if evictions := rt.atlasEvictions(); evictions != rt.evictions {
+		rt.evictions = evictions
+		rt.redrawGlyphs()
+	}
+	if rt.redraw {
+		rt.makeBatches()
+		rt.redraw = false
+	}
+
+	for _, b := range rt.batches {
+		b.d.Draw(t)
+	}
+}
+
+func (rt *Rich) textLayout() textLayout {
+	return textLayout{
+		orig:          rt.Orig,
+		wrapWidth:     rt.WrapWidth,
+		align:         rt.Align,
+		verticalAlign: rt.VerticalAlign,
+		tabWidth:      rt.TabWidth,
+		lineHeight:    rt.LineHeight,
+		atlas:         rt.Fonts.Regular,
+		scale:         1,
+	}
+}
+
+// layout lays out the items, if they or the layout changed.
+func (rt *Rich) layout() {
+	l := rt.textLayout()
+	if !rt.dirty && l == rt.laid {
+		return
+	}
+	rt.laid = l
+	rt.dirty = false
+	rt.redraw = true
+
+	rt.atlases = rt.atlases[:0]
+	for _, item := range rt.items {
+		if !containsAtlas(rt.atlases, item.atlas) {
+			rt.atlases = append(rt.atlases, item.atlas)
+		}
+	}
+	rt.evictions = rt.atlasEvictions()
+
+	rt.glyphs = rt.glyphs[:0]
+	rt.bounds = pixel.Rect{}
+	rt.animated = false
+	l.layout(rt.items, func(src int, item layoutItem, rect, frame, bounds pixel.Rect) {
+		rt.glyphs = append(rt.glyphs, richGlyph{item: item, src: src, rect: rect, frame: frame})
+		rt.bounds = unionBounds(rt.bounds, bounds)
+		if item.effect != EffectNone {
+			rt.animated = true
+		}
+	})
+}
+
+// atlasEvictions returns the total number of evictions of all Atlases of the items.
+func (rt *Rich) atlasEvictions() uint64 {
+	var evictions uint64
+	for _, a := range rt.atlases {
+		evictions += a.evictions()
+	}
+	return evictions
+}
+
+// redrawGlyphs updates the frames of all glyphs after a dynamic Atlas evicted some of them.
+func (rt *Rich) redrawGlyphs() {
+	for i := range rt.glyphs {
+		g := &rt.glyphs[i]
+		if g.item.image == nil {
+			g.frame = g.item.atlas.Glyph(g.item.atlas.resolve(g.item.r)).Frame
+		}
+	}
+	rt.redraw = true
+}
+
+func containsAtlas(atlases []*Atlas, a *Atlas) bool {
+	for _, b := range atlases {
+		if a == b {
+			return true
+		}
+	}
+	return false
+}
+
+// makeBatches makes the triangles of all glyphs, transformed by the matrix and masked by the color
+// mask.
+func (rt *Rich) makeBatches() {
+	for _, b := range rt.batches {
+		b.tri.SetLen(0)
+	}
+
+	for _, g := range rt.glyphs {
+		pic := g.item.atlas.Picture()
+		if g.item.image != nil {
+			pic = g.item.image.Picture()
+		}
+		b := rt.batch(pic)
+
+		rect := g.rect.Moved(rt.effectOffset(g))
+		rv := [...]pixel.Vec{rect.Min, pixel.V(rect.Max.X, rect.Min.Y), rect.Max, pixel.V(rect.Min.X, rect.Max.Y)}
+		fv := [...]pixel.Vec{g.frame.Min, pixel.V(g.frame.Max.X, g.frame.Min.Y), g.frame.Max, pixel.V(g.frame.Min.X, g.frame.Max.Y)}
+		col := g.item.col.Mul(rt.col)
+		n := b.tri.Len()
+		b.tri.SetLen(n + 6)
+		for i, j := range [...]int{0, 1, 2, 0, 2, 3} {
+			v := &b.tri[n+i]
+			v.Position = rt.mat.Project(rv[j])
+			v.Color = col
+			v.Picture = fv[j]
+			v.Intensity = 1
+		}
+	}
+
+	for _, b := range rt.batches {
+		b.d.Dirty()
+	}
+}
+
+// batch returns the batch of the Picture, creating it if needed.
+func (rt *Rich) batch(pic pixel.Picture) *richBatch {
+	for _, b := range rt.batches {
+		if b.pic == pic {
+			return b
+		}
+	}
+	b := &richBatch{pic: pic}
+	b.d = pixel.Drawer{Triangles: &b.tri, Picture: pic}
+	rt.batches = append(rt.batches, b)
+	return b
+}
+
+// effectOffset returns the current displacement of a glyph by it's effect.
+func (rt *Rich) effectOffset(g richGlyph) pixel.Vec {
+	size := g.item.atlas.LineHeight() * g.item.scale
+	switch g.item.effect {
+	case EffectWave:
+		return pixel.V(0, 0.1*size*math.Sin(2*math.Pi*rt.time-0.5*float64(g.src)))
+	case EffectShake:
+		// a new random offset 20 times a second
+		step := int(rt.time * 20)
+		return pixel.V(noise(2*g.src, step)*2-1, noise(2*g.src+1, step)*2-1).Scaled(0.05 * size)
+	}
+	return pixel.ZV
+}
+
+// noise returns a pseudo-random number in [0, 1] determined by the two integers.
+func noise(a, b int) float64 {
+	x := uint32(a)*0x9e3779b1 ^ uint32(b)*0x85ebca6b
+	x ^= x >> 15
+	x *= 0x2c1b3c6d
+	x ^= x >> 12
+	return float64(x) / math.MaxUint32
+}
@@ -0,0 +1,129 @@
+package text
+
+import (
+	"image"
+	"math"
+
+	"golang.org/x/image/font"
+)
+
+// NewSDFAtlas creates a new Atlas just like NewAtlas, but the glyphs are stored as a signed
+// distance field instead of a plain bitmap. Each pixel of the Picture stores the distance to the
+// edge of the nearest glyph in it's alpha (and all other) channels: 0.5 is on the edge, larger
+// values are inside of the glyph and smaller outside, reaching 0 and 1 at the spread.
+//
+// Text drawn with a signed distance field Atlas stays crisp when scaled and can have outlines,
+// glows and shadows up to the spread wide, but it must be drawn with a special shader, such as the
+// one of pixelgl.Canvas.SetSDFStyle. A spread of a few pixels is usually enough and the face
+// should be fairly large, such as 32 or 48 pixels.
+//
+// The frames of the glyphs include the spread around them.
+func NewSDFAtlas(face font.Face, spread float64, runeSets ...[]rune) *Atlas {
+	return newAtlas(face, atlasRunes(runeSets), spread)
+}
+
+// Spread returns the distance from the edges of the glyphs, which is covered by the signed
+// distance field of the Atlas. It's zero for Atlases, which are not signed distance fields.
+func (a *Atlas) Spread() float64 {
+	return a.spread
+}
+
+// signedDistanceField replaces the image with a signed distance field of it's alpha channel. The
+// edge is where the alpha crosses 0.5.
+func signedDistanceField(img *image.RGBA, spread float64) {
+	w, h := img.Bounds().Dx(), img.Bounds().Dy()
+
+	// squared distances of the pixels outside to the nearest pixel inside and vice versa
+	outside := make([]float64, w*h)
+	inside := make([]float64, w*h)
+	for i := range outside {
+		if img.Pix[i*4+3] >= 128 {
+			inside[i] = distanceInf
+		} else {
+			outside[i] = distanceInf
+		}
+	}
+	distanceTransform(outside, w, h)
+	distanceTransform(inside, w, h)
+
+	for i := range outside {
+		// the signed distance to the edge, which lies half a pixel from the pixels next to it,
+		// positive outside of the glyph
+		var d float64
+		if a := float64(img.Pix[i*4+3]) / 255; 0 < a && a < 1 {
+			d = 0.5 - a
+		} else if outside[i] > 0 {
+			d = math.Sqrt(outside[i]) - 0.5
+		} else {
+			d = 0.5 - math.Sqrt(inside[i])
+		}
+
+		v := math.Max(0, math.Min(1, 0.5-d/(2*spread)))
+		c := uint8(math.Round(v * 255))
+		img.Pix[i*4+0] = c
+		img.Pix[i*4+1] = c
+		img.Pix[i*4+2] = c
+		img.Pix[i*4+3] = c
+	}
+}
+
+// distanceInf is the squared distance of pixels with no known distance.
+const distanceInf = 1e20
+
+// distanceTransform replaces each value of the grid with the smallest squared euclidean distance
+// to a pixel of the grid plus it's value. This is the algorithm by Felzenszwalb and Huttenlocher,
+// transforming the columns and then the rows of the grid.
+func distanceTransform(grid []float64, w, h int) {
+	n := w
+	if h > n {
+		n = h
+	}
+	f := make([]float64, n)
+	d := make([]float64, n)
+	v := make([]int, n)
+	z := make([]float64, n+1)
+
+	for x := 0; x < w; x++ {
+		for y := 0; y < h; y++ {
+			f[y] = grid[y*w+x]
+		}
+		distanceTransform1D(f[:h], d[:h], v, z)
+		for y := 0; y < h; y++ {
+			grid[y*w+x] = d[y]
+		}
+	}
+	for y := 0; y < h; y++ {
+		copy(f, grid[y*w:(y+1)*w])
+		distanceTransform1D(f[:w], d[:w], v, z)
+		copy(grid[y*w:(y+1)*w], d[:w])
+	}
+}
+
+// distanceTransform1D computes the one-dimensional distance transform of f into d, using the lower
+// envelope of parabolas rooted at each value. The v and z slices are used for the envelope.
+func distanceTransform1D(f, d []float64, v []int, z []float64) {
+	// s returns the intersection of the parabolas rooted at p and q
+	s := func(p, q int) float64 {
+		return ((f[q] + float64(q*q)) - (f[p] + float64(p*p))) / float64(2*q-2*p)
+	}
+
+	k := 0
+	v[0] = 0
+	z[0], z[1] = math.Inf(-1), math.Inf(+1)
+	for q := 1; q < len(f); q++ {
+		for s(v[k], q) <= z[k] {
+			k--
+		}
+		k++
+		v[k] = q
+		z[k], z[k+1] = s(v[k-1], q), math.Inf(+1)
+	}
+
+	k = 0
+	for q := range f {
+		for z[k+1] < float64(q) {
+			k++
+		}
+		d[q] = float64((q-v[k])*(q-v[k])) + f[v[k]]
+	}
+}
@@ -56,6 +56,14 @@ func RangeTable(table *unicode.RangeTable) []rune {
 //
 // Newlines, tabs and carriage returns are supported.
 //
+// Text can also wrap lines longer than the WrapWidth and align lines horizontally and the whole
+// block of text vertically relative to the Orig:
+//   txt.WrapWidth = 200
+//   txt.Align = text.AlignCenter
+//   txt.VerticalAlign = text.AlignMiddle
+//
+// To mix colors, fonts, sizes and images within a single piece of text, use Rich instead.
+//
 // Finally, if we want the written text to show up on some other Target, we can draw it:
 //   txt.Draw(target)
 //
@@ -88,19 +96,43 @@ type Text struct {
 	//   txt.TabWidth = 8 * txt.Atlas().Glyph(' ').Advance
 	TabWidth float64
 
+	// WrapWidth is the maximal width of lines. Longer lines are wrapped at line break opportunities,
+	// such as spaces, and words longer than a whole line are hyphenated. Zero disables wrapping.
+	WrapWidth float64
+
+	// Align is the horizontal alignment of lines and VerticalAlign is the vertical alignment of
+	// the whole block of text, both relative to the Orig.
+	//
+	// If the text is wrapped or aligned, it's laid out from the Orig once it's drawn or it's
+	// bounds are needed, and the Dot is moved after it then. Moving the Dot manually has no
+	// effect. Text written before wrapping or alignment was set is kept as it was written.
+	Align         Alignment
+	VerticalAlign VerticalAlignment
+
 	atlas *Atlas
 
 	buf    []byte
+	runes  []rune
 	prevR  rune
 	bounds pixel.Rect
 	glyph  pixel.TrianglesData
 	tris   pixel.TrianglesData
 
+	// items are the laid out runes, which follow the glyphs written before the text was laid out
+	items       []layoutItem
+	laid        textLayout
+	relayout    bool
+	plainTris   int
+	plainRunes  int
+	plainBounds pixel.Rect
+
 	mat    pixel.Matrix
 	col    pixel.RGBA
 	trans  pixel.TrianglesData
 	transD pixel.Drawer
 	dirty  bool
+
+	evictions uint64
 }
 
 // New creates a new Text capable of drawing runes contained in the provided Atlas. Orig and Dot
@@ -152,11 +184,27 @@ func (txt *Text) Atlas() *Atlas {
 //
 // If the Text is empty, a zero rectangle is returned.
 func (txt *Text) Bounds() pixel.Rect {
+	txt.layout()
 	return txt.bounds
 }
 
 // BoundsOf returns the bounding box of s if it was to be written to the Text right now.
 func (txt *Text) BoundsOf(s string) pixel.Rect {
+	if txt.laidOut() {
+		txt.layout()
+		items := txt.items[:len(txt.items):len(txt.items)]
+		for _, r := range s {
+			items = append(items, layoutItem{r: r, atlas: txt.atlas, scale: 1})
+		}
+		bounds := pixel.Rect{}
+		txt.textLayout().layout(items, func(src int, _ layoutItem, _, _, b pixel.Rect) {
+			if src >= len(txt.items) {
+				bounds = unionBounds(bounds, b)
+			}
+		})
+		return bounds
+	}
+
 	dot := txt.Dot
 	prevR := txt.prevR
 	bounds := pixel.Rect{}
@@ -170,12 +218,7 @@ func (txt *Text) BoundsOf(s string) pixel.Rect {
 
 		var b pixel.Rect
 		_, _, b, dot = txt.Atlas().DrawRune(prevR, r, dot)
-
-		if bounds.W()*bounds.H() == 0 {
-			bounds = b
-		} else {
-			bounds = bounds.Union(b)
-		}
+		bounds = unionBounds(bounds, b)
 
 		prevR = r
 	}
@@ -186,6 +229,9 @@ func (txt *Text) BoundsOf(s string) pixel.Rect {
 // Clear removes all written text from the Text. The Dot field is reset to Orig.
 func (txt *Text) Clear() {
 	txt.prevR = -1
+	txt.items = txt.items[:0]
+	txt.plainTris, txt.plainRunes, txt.plainBounds = 0, 0, pixel.Rect{}
+	txt.runes = txt.runes[:0]
 	txt.bounds = pixel.Rect{}
 	txt.tris.SetLen(0)
 	txt.dirty = true
@@ -241,6 +287,7 @@ func (txt *Text) Draw(t pixel.Target, matrix pixel.Matrix) {
 // If there's a lot of text written to the Text, changing a matrix or a color mask often might hurt
 // performance. Consider using your Target's SetMatrix or SetColorMask methods if available.
 func (txt *Text) DrawColorMask(t pixel.Target, matrix pixel.Matrix, mask color.Color) {
+	txt.layout()
 	if matrix != txt.mat {
 		txt.mat = matrix
 		txt.dirty = true
@@ -253,6 +300,10 @@ func (txt *Text) DrawColorMask(t pixel.Target, matrix pixel.Matrix, mask color.C
 		txt.col = rgba
 		txt.dirty = true
 	}
+	if evictions := txt.atlas.evictions(); evictions != txt.evictions {
+		txt.evictions = evictions
+		txt.redrawGlyphs()
+	}
 
 	if txt.dirty {
 		txt.trans.SetLen(txt.tris.Len())
@@ -298,8 +349,19 @@ func (txt *Text) drawBuf() {
 	}
 
 	rgba := pixel.ToRGBA(txt.Color)
-	for i := range txt.glyph {
-		txt.glyph[i].Color = rgba
+
+	if txt.laidOut() {
+		if len(txt.items) == 0 {
+			// keep the glyphs written so far in front of the laid out text
+			txt.plainTris, txt.plainRunes, txt.plainBounds = txt.tris.Len(), len(txt.runes), txt.bounds
+		}
+		for utf8.FullRune(txt.buf) {
+			r, size := utf8.DecodeRune(txt.buf)
+			txt.buf = txt.buf[size:]
+			txt.items = append(txt.items, layoutItem{r: r, col: rgba, atlas: txt.atlas, scale: 1})
+		}
+		txt.relayout = true
+		return
 	}
 
 	for utf8.FullRune(txt.buf) {
@@ -317,32 +379,82 @@ func (txt *Text) drawBuf() {
 
 		txt.prevR = r
 
-		rv := [...]pixel.Vec{
-			{X: rect.Min.X, Y: rect.Min.Y},
-			{X: rect.Max.X, Y: rect.Min.Y},
-			{X: rect.Max.X, Y: rect.Max.Y},
-			{X: rect.Min.X, Y: rect.Max.Y},
-		}
+		txt.addGlyph(r, rgba, rect, frame, bounds)
+	}
+}
+
+// layout lays out the written items, if they or the layout changed.
+func (txt *Text) layout() {
+	l := txt.textLayout()
+	if len(txt.items) == 0 || !txt.relayout && l == txt.laid {
+		return
+	}
+	txt.laid = l
+	txt.relayout = false
+
+	txt.tris.SetLen(txt.plainTris)
+	txt.runes = txt.runes[:txt.plainRunes]
+	txt.bounds = txt.plainBounds
+	txt.dirty = true
+	txt.Dot = l.layout(txt.items, func(_ int, item layoutItem, rect, frame, bounds pixel.Rect) {
+		txt.addGlyph(item.r, item.col, rect, frame, bounds)
+	})
+}
+
+// addGlyph adds a glyph of r drawn into the rect from the frame of the Atlas.
+func (txt *Text) addGlyph(r rune, col pixel.RGBA, rect, frame, bounds pixel.Rect) {
+	rv := [...]pixel.Vec{
+		{X: rect.Min.X, Y: rect.Min.Y},
+		{X: rect.Max.X, Y: rect.Min.Y},
+		{X: rect.Max.X, Y: rect.Max.Y},
+		{X: rect.Min.X, Y: rect.Max.Y},
+	}
+
+	fv := [...]pixel.Vec{
+		{X: frame.Min.X, Y: frame.Min.Y},
+		{X: frame.Max.X, Y: frame.Min.Y},
+		{X: frame.Max.X, Y: frame.Max.Y},
+		{X: frame.Min.X, Y: frame.Max.Y},
+	}
+
+	for i, j := range [...]int{0, 1, 2, 0, 2, 3} {
+		txt.glyph[i].Position = rv[j]
+		txt.glyph[i].Color = col
+		txt.glyph[i].Picture = fv[j]
+	}
+
+	txt.tris = append(txt.tris, txt.glyph...)
+	txt.dirty = true
 
+	txt.bounds = unionBounds(txt.bounds, bounds)
+
+	// a dynamic Atlas may move the glyph later
+	if txt.atlas.cache != nil {
+		txt.runes = append(txt.runes, txt.atlas.resolve(r))
+	}
+}
+
+// redrawGlyphs updates the frames of all glyphs after a dynamic Atlas evicted some of them.
+func (txt *Text) redrawGlyphs() {
+	for i, r := range txt.runes {
+		frame := txt.atlas.Glyph(r).Frame
 		fv := [...]pixel.Vec{
 			{X: frame.Min.X, Y: frame.Min.Y},
 			{X: frame.Max.X, Y: frame.Min.Y},
 			{X: frame.Max.X, Y: frame.Max.Y},
 			{X: frame.Min.X, Y: frame.Max.Y},
 		}
-
-		for i, j := range [...]int{0, 1, 2, 0, 2, 3} {
-			txt.glyph[i].Position = rv[j]
-			txt.glyph[i].Picture = fv[j]
+		for j, k := range [...]int{0, 1, 2, 0, 2, 3} {
+			txt.tris[6*i+j].Picture = fv[k]
 		}
+	}
+	txt.dirty = true
+}
 
-		txt.tris = append(txt.tris, txt.glyph...)
-		txt.dirty = true
-
-		if txt.bounds.W()*txt.bounds.H() == 0 {
-			txt.bounds = bounds
-		} else {
-			txt.bounds = txt.bounds.Union(bounds)
-		}
+// unionBounds returns the union of the bounds, ignoring empty ones.
+func unionBounds(a, b pixel.Rect) pixel.Rect {
+	if a.W()*a.H() == 0 {
+		return b
 	}
+	return a.Union(b)
 }
@@ -2,7 +2,9 @@ package text_test
 
 import (
 	"fmt"
+	"image/color"
 	"math/rand"
+	"reflect"
 	"testing"
 	"unicode"
 
@@ -10,6 +12,7 @@ import (
 	"golang.org/x/image/font/gofont/goregular"
 
 	"github.com/faiface/pixel"
+	"github.com/faiface/pixel/pixeltest"
 	"github.com/faiface/pixel/text"
 	"github.com/golang/freetype/truetype"
 )
@@ -34,6 +37,247 @@ func TestClear(t *testing.T) {
 	}
 }
 
+func TestText_Golden(t *testing.T) {
+	img := pixeltest.Render(pixel.R(0, 0, 96, 32), func(target pixel.Target) {
+		txt := text.New(pixel.V(4, 18), text.Atlas7x13)
+		txt.Color = pixel.RGB(1, 0.5, 0)
+		fmt.Fprintln(txt, "Hello,")
+		txt.Color = pixel.RGB(0, 0.5, 1)
+		fmt.Fprint(txt, "\tPixel!")
+		txt.Draw(target, pixel.IM)
+	})
+	pixeltest.AssertGolden(t, "testdata/text.png", img, pixeltest.Options{Threshold: 0.1})
+}
+
+func TestText_Wrap(t *testing.T) {
+	tests := []struct {
+		name  string
+		width float64
+		text  string
+		dot   pixel.Vec
+	}{
+		{"Words", 70, "hello world foo", pixel.V(63, -13)},
+		{"Hyphenation", 35, "abcdefghij", pixel.V(14, -26)},
+		{"Newline", 70, "hello\nworld", pixel.V(35, -13)},
+		{"TrailingSpace", 35, "abc def ", pixel.V(28, -13)},
+		{"Ideographs", 14, "日本語", pixel.V(7, -13)},
+		{"Space", 35, "a bb cc", pixel.V(14, -13)},
+		{"NoBreakSpace", 35, "a bb\u00a0cc", pixel.V(35, -13)},
+		{"SoftHyphen", 35, "abc\u00addef", pixel.V(21, -13)},
+		{"Hyphen", 35, "abc-def", pixel.V(21, -13)},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			txt := text.New(pixel.ZV, text.Atlas7x13)
+			txt.WrapWidth = test.width
+			fmt.Fprint(txt, test.text)
+			// the Dot is moved when the text is laid out
+			if txt.Bounds().Max.X > test.width {
+				t.Errorf("txt.Bounds() = %v, wider than %v", txt.Bounds(), test.width)
+			}
+			if !eqVectors(txt.Dot, test.dot) {
+				t.Errorf("txt.Dot = %v, want %v", txt.Dot, test.dot)
+			}
+		})
+	}
+
+	t.Run("Append", func(t *testing.T) {
+		txt := text.New(pixel.ZV, text.Atlas7x13)
+		txt.WrapWidth = 49
+		fmt.Fprint(txt, "hello ")
+		txt.Bounds()
+		if got, want := txt.Dot, pixel.V(42, 0); !eqVectors(got, want) {
+			t.Errorf("txt.Dot = %v, want %v", got, want)
+		}
+		fmt.Fprint(txt, "world")
+		txt.Bounds()
+		if got, want := txt.Dot, pixel.V(35, -13); !eqVectors(got, want) {
+			t.Errorf("txt.Dot = %v, want %v", got, want)
+		}
+		if got, want := txt.BoundsOf("!"), pixel.R(35, -15, 41, -2); got != want {
+			t.Errorf("txt.BoundsOf(\"!\") = %v, want %v", got, want)
+		}
+	})
+
+	t.Run("Change", func(t *testing.T) {
+		txt := text.New(pixel.ZV, text.Atlas7x13)
+		fmt.Fprint(txt, "ab ")
+		txt.WrapWidth = 14
+		fmt.Fprint(txt, "cd ef")
+		// the text written before wrapping stays in place
+		if got, want := txt.Bounds(), pixel.R(0, -15, 20, 11); got != want {
+			t.Errorf("txt.Bounds() = %v, want %v", got, want)
+		}
+
+		txt.WrapWidth = 0
+		if got, want := txt.Bounds(), pixel.R(0, -2, 34, 11); got != want {
+			t.Errorf("txt.Bounds() after changing WrapWidth = %v, want %v", got, want)
+		}
+		if got, want := txt.Dot, pixel.V(35, 0); !eqVectors(got, want) {
+			t.Errorf("txt.Dot = %v, want %v", got, want)
+		}
+	})
+}
+
+func TestText_Align(t *testing.T) {
+	tests := []struct {
+		name   string
+		align  text.Alignment
+		valign text.VerticalAlignment
+		text   string
+		bounds pixel.Rect
+		dot    pixel.Vec
+	}{
+		{"Left", text.AlignLeft, text.AlignTop, "ab\ncde", pixel.R(100, -15, 120, 11), pixel.V(121, -13)},
+		{"Center", text.AlignCenter, text.AlignTop, "abcd", pixel.R(86, -2, 113, 11), pixel.V(114, 0)},
+		{"Right", text.AlignRight, text.AlignTop, "ab\ncde", pixel.R(79, -15, 99, 11), pixel.V(100, -13)},
+		{"Bottom", text.AlignLeft, text.AlignBottom, "a\nb\nc", pixel.R(100, -2, 106, 37), pixel.V(107, 0)},
+		{"Middle", text.AlignLeft, text.AlignMiddle, "a\nb\nc", pixel.R(100, -19.5, 106, 19.5), pixel.V(107, -17.5)},
+		{"CenterMiddle", text.AlignCenter, text.AlignMiddle, "abcd", pixel.R(86, -6.5, 113, 6.5), pixel.V(114, -4.5)},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			txt := text.New(pixel.V(100, 0), text.Atlas7x13)
+			txt.Align = test.align
+			txt.VerticalAlign = test.valign
+			fmt.Fprint(txt, test.text)
+			if got := txt.Bounds(); got != test.bounds {
+				t.Errorf("txt.Bounds() = %v, want %v", got, test.bounds)
+			}
+			if !eqVectors(txt.Dot, test.dot) {
+				t.Errorf("txt.Dot = %v, want %v", txt.Dot, test.dot)
+			}
+		})
+	}
+
+	t.Run("Justify", func(t *testing.T) {
+		txt := text.New(pixel.ZV, text.Atlas7x13)
+		txt.WrapWidth = 70
+		txt.Align = text.AlignJustify
+		fmt.Fprint(txt, "aa bb cc dd ee")
+		// the first line is stretched to the WrapWidth (the last glyph ends 1 pixel before it's
+		// advance), the last one isn't
+		if got, want := txt.Bounds(), pixel.R(0, -15, 69, 11); got != want {
+			t.Errorf("txt.Bounds() = %v, want %v", got, want)
+		}
+		if got, want := txt.Dot, pixel.V(35, -13); !eqVectors(got, want) {
+			t.Errorf("txt.Dot = %v, want %v", got, want)
+		}
+	})
+}
+
+func TestText_LayoutGolden(t *testing.T) {
+	img := pixeltest.Render(pixel.R(0, 0, 160, 96), func(target pixel.Target) {
+		txt := text.New(pixel.V(80, 48), text.Atlas7x13)
+		txt.WrapWidth = 140
+		txt.Align = text.AlignCenter
+		txt.VerticalAlign = text.AlignMiddle
+		txt.Color = pixel.RGB(1, 0.5, 0)
+		fmt.Fprint(txt, "Pixel wraps long lines ")
+		txt.Color = pixel.RGB(0, 0.5, 1)
+		fmt.Fprint(txt, "and aligns them around the origin, even supercalifragilisticexpialidocious words.")
+		txt.Draw(target, pixel.IM)
+	})
+	pixeltest.AssertGolden(t, "testdata/layout.png", img, pixeltest.Options{Threshold: 0.1})
+}
+
+func TestRich_ParseMarkup(t *testing.T) {
+	bold := text.NewAtlas(basicfont.Face7x13, text.ASCII)
+	icon := pixel.NewSprite(pixel.MakePictureData(pixel.R(0, 0, 8, 8)), pixel.R(0, 0, 8, 8))
+	rt := text.NewRich(pixel.ZV, text.Fonts{Regular: text.Atlas7x13, Bold: bold})
+	rt.Images = map[string]*pixel.Sprite{"icon": icon}
+
+	tests := []struct {
+		markup string
+		spans  []text.Span
+	}{
+		{"plain", []text.Span{{Text: "plain"}}},
+		{"a [b]b[/b] c", []text.Span{
+			{Text: "a "},
+			{Text: "b", Style: text.Style{Atlas: bold}},
+			{Text: " c"},
+		}},
+		{"[color=#f00]r[size=2]R[/size][/color]", []text.Span{
+			{Text: "r", Style: text.Style{Color: pixel.RGB(1, 0, 0)}},
+			{Text: "R", Style: text.Style{Color: pixel.RGB(1, 0, 0), Scale: 2}},
+		}},
+		{"[b][i]bi", []text.Span{{Text: "bi", Style: text.Style{Atlas: bold}}}},
+		{"[wave]~[/wave][shake]![img=icon]", []text.Span{
+			{Text: "~", Style: text.Style{Effect: text.EffectWave}},
+			{Text: "!", Style: text.Style{Effect: text.EffectShake}},
+			{Style: text.Style{Effect: text.EffectShake}, Image: icon},
+		}},
+		{"[[b]", []text.Span{{Text: "[b]"}}},
+	}
+	for _, test := range tests {
+		spans, err := rt.ParseMarkup(test.markup)
+		if err != nil {
+			t.Errorf("ParseMarkup(%q): %v", test.markup, err)
+			continue
+		}
+		if !reflect.DeepEqual(spans, test.spans) {
+			t.Errorf("ParseMarkup(%q) = %v, want %v", test.markup, spans, test.spans)
+		}
+	}
+
+	for _, markup := range []string{"[b]x[/i]", "x[/b]", "[u]x", "[color=red]", "[size=0]", "[img=coin]", "[b"} {
+		if _, err := rt.ParseMarkup(markup); err == nil {
+			t.Errorf("ParseMarkup(%q) succeeded, want error", markup)
+		}
+	}
+}
+
+func TestRich_Baseline(t *testing.T) {
+	icon := pixel.NewSprite(pixel.MakePictureData(pixel.R(0, 0, 8, 8)), pixel.R(0, 0, 8, 8))
+
+	rt := text.NewRich(pixel.ZV, text.Fonts{Regular: text.Atlas7x13})
+	rt.WriteSpans(
+		text.Span{Text: "a"},
+		text.Span{Text: "a", Style: text.Style{Scale: 2}},
+		text.Span{Image: icon},
+	)
+	// the glyphs of Atlas7x13 span from 2 pixels below to 11 pixels above the baseline
+	if got, want := rt.Bounds(), pixel.R(0, -4, 29, 22); got != want {
+		t.Errorf("rt.Bounds() = %v, want %v", got, want)
+	}
+
+	rt.Clear()
+	rt.WriteSpans(text.Span{Text: "a\n"}, text.Span{Text: "a", Style: text.Style{Scale: 2}})
+	// the second line is as high as it's font
+	if got, want := rt.Bounds(), pixel.R(0, -30, 12, 11); got != want {
+		t.Errorf("rt.Bounds() = %v, want %v", got, want)
+	}
+
+	rt.LineHeight = 20
+	if got, want := rt.Bounds(), pixel.R(0, -24, 12, 11); got != want {
+		t.Errorf("rt.Bounds() = %v, want %v", got, want)
+	}
+}
+
+func TestRich_Golden(t *testing.T) {
+	icon := pixel.MakePictureData(pixel.R(0, 0, 8, 8))
+	for i := range icon.Pix {
+		x, y := i%8, i/8
+		if (x-4)*(x-4)+(y-4)*(y-4) < 12 {
+			icon.Pix[i] = color.RGBA{255, 200, 0, 255}
+		}
+	}
+
+	img := pixeltest.Render(pixel.R(0, 0, 128, 48), func(target pixel.Target) {
+		rt := text.NewRich(pixel.V(64, 24), text.Fonts{Regular: text.Atlas7x13})
+		rt.Align = text.AlignCenter
+		rt.VerticalAlign = text.AlignMiddle
+		rt.Style.Color = pixel.RGB(0.3, 0.3, 0.3)
+		rt.Images = map[string]*pixel.Sprite{"coin": pixel.NewSprite(icon, icon.Bounds())}
+		err := rt.WriteMarkup("[color=#f80]Gold:[/color] 50 [color=#fff][img=coin][/color]\n[size=2][color=#08f]Pixel[/color][/size]!")
+		if err != nil {
+			t.Fatal(err)
+		}
+		rt.Draw(target, pixel.IM)
+	})
+	pixeltest.AssertGolden(t, "testdata/rich.png", img, pixeltest.Options{Threshold: 0.1})
+}
+
 func BenchmarkNewAtlas(b *testing.B) {
 	runeSets := []struct {
 		name string
@@ -0,0 +1,174 @@
+package pixel
+
+import (
+	"image/color"
+	"math"
+)
+
+// TiledSprite is a drawable frame of a Picture repeated over an arbitrary rectangle. It's useful
+// for filling large areas, such as floors or scrolling backgrounds.
+//
+// The tiles are generated as regular geometry (with partial tiles clipped at the edges of the
+// rectangle), so TiledSprite works on every Target, including Batch, where texture wrapping is not
+// available.
+//
+//   floor := pixel.NewTiledSprite(pic, pixel.R(0, 0, 32, 32))
+//   floor.SetOffset(pixel.V(scroll, 0))
+//   floor.Draw(win, win.Bounds(), pixel.IM)
+//
+// Just like Sprite, TiledSprite caches the generated geometry and only recomputes it when the
+// rectangle, offset, Matrix or color mask change. It also caches the results of MakePicture, so
+// using a TiledSprite with an unbounded number of Pictures leads to a memory leak.
+type TiledSprite struct {
+	tri   *TrianglesData
+	frame Rect
+	d     Drawer
+
+	rect   Rect
+	offset Vec
+	matrix Matrix
+	mask   RGBA
+}
+
+// NewTiledSprite creates a TiledSprite repeating the supplied frame of a Picture.
+func NewTiledSprite(pic Picture, frame Rect) *TiledSprite {
+	tri := &TrianglesData{}
+	ts := &TiledSprite{
+		tri: tri,
+		d:   Drawer{Triangles: tri},
+	}
+	ts.matrix = IM
+	ts.mask = Alpha(1)
+	ts.Set(pic, frame)
+	return ts
+}
+
+// Set sets a new frame of a Picture for this TiledSprite.
+func (ts *TiledSprite) Set(pic Picture, frame Rect) {
+	ts.d.Picture = pic
+	if frame != ts.frame {
+		ts.frame = frame
+		ts.calcData()
+	}
+}
+
+// Picture returns the current TiledSprite's Picture.
+func (ts *TiledSprite) Picture() Picture {
+	return ts.d.Picture
+}
+
+// Frame returns the current TiledSprite's frame.
+func (ts *TiledSprite) Frame() Rect {
+	return ts.frame
+}
+
+// SetOffset sets the offset of the tiling pattern. With zero offset, a tile starts exactly at the
+// Min corner of the drawn rectangle. Changing the offset over time scrolls the pattern.
+func (ts *TiledSprite) SetOffset(offset Vec) {
+	if offset != ts.offset {
+		ts.offset = offset
+		ts.calcData()
+	}
+}
+
+// Offset returns the offset of the tiling pattern.
+func (ts *TiledSprite) Offset() Vec {
+	return ts.offset
+}
+
+// Draw fills the rectangle with tiles and draws them onto the provided Target. The tiles will be
+// transformed by the given Matrix.
+//
+// This method is equivalent to calling DrawColorMask with nil color mask.
+func (ts *TiledSprite) Draw(t Target, rect Rect, matrix Matrix) {
+	ts.DrawColorMask(t, rect, matrix, nil)
+}
+
+// DrawColorMask fills the rectangle with tiles and draws them onto the provided Target. The tiles
+// will be transformed by the given Matrix and all of their color will be multiplied by the given
+// mask.
+//
+// If the mask is nil, a fully opaque white mask will be used, which causes no effect.
+func (ts *TiledSprite) DrawColorMask(t Target, rect Rect, matrix Matrix, mask color.Color) {
+	dirty := false
+	if rect != ts.rect {
+		ts.rect = rect
+		dirty = true
+	}
+	if matrix != ts.matrix {
+		ts.matrix = matrix
+		dirty = true
+	}
+	if mask == nil {
+		mask = Alpha(1)
+	}
+	rgba := ToRGBA(mask)
+	if rgba != ts.mask {
+		ts.mask = rgba
+		dirty = true
+	}
+
+	if dirty {
+		ts.calcData()
+	}
+
+	ts.d.Draw(t)
+}
+
+// tileStart returns the position of the first tile start at or before min.
+func tileStart(min, offset, size float64) float64 {
+	off := math.Mod(offset, size)
+	if off > 0 {
+		off -= size
+	}
+	return min + off
+}
+
+func (ts *TiledSprite) calcData() {
+	ts.tri.SetLen(0)
+
+	var (
+		frame = ts.frame.Norm()
+		rect  = ts.rect.Norm()
+		w, h  = frame.W(), frame.H()
+	)
+	if w*h == 0 || rect.W()*rect.H() == 0 {
+		ts.d.Dirty()
+		return
+	}
+
+	startX := tileStart(rect.Min.X, ts.offset.X, w)
+	startY := tileStart(rect.Min.Y, ts.offset.Y, h)
+
+	for j := 0; startY+float64(j)*h < rect.Max.Y; j++ {
+		for i := 0; startX+float64(i)*w < rect.Max.X; i++ {
+			tile := R(
+				startX+float64(i)*w,
+				startY+float64(j)*h,
+				startX+float64(i+1)*w,
+				startY+float64(j+1)*h,
+			)
+			part := tile.Intersect(rect)
+			if part.W()*part.H() == 0 {
+				continue
+			}
+
+			// position inside the tile maps to the same position inside the frame
+			pic := part.Moved(frame.Min.Sub(tile.Min))
+
+			pos := [...]Vec{part.Min, V(part.Max.X, part.Min.Y), part.Max, V(part.Min.X, part.Max.Y)}
+			uv := [...]Vec{pic.Min, V(pic.Max.X, pic.Min.Y), pic.Max, V(pic.Min.X, pic.Max.Y)}
+
+			off := ts.tri.Len()
+			ts.tri.SetLen(off + 6)
+			for k, l := range [...]int{0, 1, 2, 0, 2, 3} {
+				(*ts.tri)[off+k].Position = ts.matrix.Project(pos[l])
+				(*ts.tri)[off+k].Color = ts.mask
+				(*ts.tri)[off+k].Picture = uv[l]
+				(*ts.tri)[off+k].Intensity = 1
+			}
+		}
+	}
+
+	ts.d.Dirty()
+}
@@ -0,0 +1,81 @@
+package pixel_test
+
+import (
+	"testing"
+
+	"github.com/faiface/pixel"
+)
+
+func TestTiledSprite_Draw(t *testing.T) {
+	pic := pixel.MakePictureData(pixel.R(0, 0, 64, 64))
+	ts := pixel.NewTiledSprite(pic, pixel.R(16, 16, 32, 32))
+
+	tests := []struct {
+		name   string
+		rect   pixel.Rect
+		offset pixel.Vec
+		tiles  int
+		first  [2]pixel.Vec // position and picture of the first vertex
+	}{
+		{
+			name:  "Exact fit",
+			rect:  pixel.R(0, 0, 32, 32),
+			tiles: 4,
+			first: [2]pixel.Vec{pixel.V(0, 0), pixel.V(16, 16)},
+		},
+		{
+			name:  "Partial tiles",
+			rect:  pixel.R(0, 0, 40, 20),
+			tiles: 6,
+			first: [2]pixel.Vec{pixel.V(0, 0), pixel.V(16, 16)},
+		},
+		{
+			name:   "Offset",
+			rect:   pixel.R(0, 0, 32, 16),
+			offset: pixel.V(4, 0),
+			tiles:  3,
+			first:  [2]pixel.Vec{pixel.V(0, 0), pixel.V(28, 16)},
+		},
+		{
+			name:   "Negative offset",
+			rect:   pixel.R(0, 0, 32, 16),
+			offset: pixel.V(-4, 0),
+			tiles:  3,
+			first:  [2]pixel.Vec{pixel.V(0, 0), pixel.V(20, 16)},
+		},
+		{
+			name:  "Empty",
+			rect:  pixel.ZR,
+			tiles: 0,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ts.SetOffset(tt.offset)
+			out := &pixel.TrianglesData{}
+			ts.Draw(pixel.NewBatch(out, pic), tt.rect, pixel.IM)
+
+			if got, want := out.Len(), 6*tt.tiles; got != want {
+				t.Fatalf("out.Len() = %d, want %d", got, want)
+			}
+			if tt.tiles == 0 {
+				return
+			}
+			if got := (*out)[0].Position; got != tt.first[0] {
+				t.Errorf("Position = %v, want %v", got, tt.first[0])
+			}
+			if got, _ := out.Picture(0); got != tt.first[1] {
+				t.Errorf("Picture = %v, want %v", got, tt.first[1])
+			}
+			for i := range *out {
+				pos := (*out)[i].Position
+				if !tt.rect.Contains(pos) {
+					t.Errorf("vertex %d at %v is outside of %v", i, pos, tt.rect)
+				}
+				if pic := (*out)[i].Picture; !pixel.R(16, 16, 32, 32).Contains(pic) {
+					t.Errorf("vertex %d picture %v is outside of the frame", i, pic)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,78 @@
+package tilemap
+
+import (
+	"bytes"
+	"compress/gzip"
+	"compress/zlib"
+	"encoding/base64"
+	"encoding/binary"
+	"io"
+	"io/ioutil"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// decodeCSV decodes tile data stored as comma-separated global tile IDs.
+func decodeCSV(data string) ([]Tile, error) {
+	fields := strings.FieldsFunc(data, func(r rune) bool {
+		return r == ',' || r == ' ' || r == '\n' || r == '\r' || r == '\t'
+	})
+	tiles := make([]Tile, len(fields))
+	for i, f := range fields {
+		gid, err := strconv.ParseUint(f, 10, 32)
+		if err != nil {
+			return nil, errors.Wrap(err, "invalid csv tile data")
+		}
+		tiles[i] = Tile(gid)
+	}
+	return tiles, nil
+}
+
+// decodeBase64 decodes tile data stored as base64 encoded (and optionally compressed) little
+// endian 32-bit global tile IDs.
+func decodeBase64(data, compression string) ([]Tile, error) {
+	raw, err := base64.StdEncoding.DecodeString(strings.TrimSpace(data))
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid base64 tile data")
+	}
+
+	var r io.Reader = bytes.NewReader(raw)
+	switch compression {
+	case "":
+		// not compressed
+	case "zlib":
+		if r, err = zlib.NewReader(r); err != nil {
+			return nil, errors.Wrap(err, "invalid zlib tile data")
+		}
+	case "gzip":
+		if r, err = gzip.NewReader(r); err != nil {
+			return nil, errors.Wrap(err, "invalid gzip tile data")
+		}
+	default:
+		return nil, errors.Errorf("unsupported tile data compression: %s", compression)
+	}
+
+	b, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to decompress tile data")
+	}
+	if len(b)%4 != 0 {
+		return nil, errors.New("invalid tile data length")
+	}
+
+	tiles := make([]Tile, len(b)/4)
+	for i := range tiles {
+		tiles[i] = Tile(binary.LittleEndian.Uint32(b[i*4:]))
+	}
+	return tiles, nil
+}
+
+// checkTiles verifies that the number of tiles matches the size of a layer.
+func checkTiles(name string, tiles []Tile, width, height int) error {
+	if len(tiles) != width*height {
+		return errors.Errorf("layer %q: got %d tiles, want %d", name, len(tiles), width*height)
+	}
+	return nil
+}
@@ -0,0 +1,14 @@
+// Package tilemap implements loading and efficient drawing of tile maps created with the Tiled map
+// editor (https://www.mapeditor.org) for the Pixel library.
+//
+// Both TMX (XML) and JSON map formats are supported, currently only with orthogonal orientation.
+// Layers inside of groups are read as if they weren't grouped, with the offset, opacity and
+// visibility of their groups applied. Image layers are not supported.
+// Tile layers are drawn by a Renderer, which splits them into chunks, so that only the chunks
+// visible on the screen get drawn. Object layers are exposed as pixel.Rect and polygon geometry
+// usable for collision detection.
+//
+// Tiled uses a coordinate system with the Y axis pointing down and the origin in the top-left
+// corner of the map. This package converts all geometry to Pixel's coordinate system, with the
+// Y axis pointing up and the origin in the bottom-left corner of the map.
+package tilemap
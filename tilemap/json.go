@@ -0,0 +1,280 @@
+package tilemap
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/faiface/pixel"
+	"github.com/pkg/errors"
+)
+
+type jsonProperty struct {
+	Name  string      `json:"name"`
+	Type  string      `json:"type"`
+	Value interface{} `json:"value"`
+}
+
+type jsonProperties []jsonProperty
+
+func (jp jsonProperties) convert() Properties {
+	props := make(Properties)
+	for _, p := range jp {
+		props[p.Name] = fmt.Sprint(p.Value)
+	}
+	return props
+}
+
+type jsonTile struct {
+	ID         int            `json:"id"`
+	Properties jsonProperties `json:"properties"`
+}
+
+type jsonTileset struct {
+	FirstGID    int            `json:"firstgid"`
+	Source      string         `json:"source"`
+	Name        string         `json:"name"`
+	TileWidth   int            `json:"tilewidth"`
+	TileHeight  int            `json:"tileheight"`
+	Spacing     int            `json:"spacing"`
+	Margin      int            `json:"margin"`
+	TileCount   int            `json:"tilecount"`
+	Columns     int            `json:"columns"`
+	Image       string         `json:"image"`
+	ImageWidth  int            `json:"imagewidth"`
+	ImageHeight int            `json:"imageheight"`
+	Tiles       []jsonTile     `json:"tiles"`
+	Properties  jsonProperties `json:"properties"`
+}
+
+func (jt *jsonTileset) convert() *Tileset {
+	ts := &Tileset{
+		FirstGID:    jt.FirstGID,
+		Name:        jt.Name,
+		TileWidth:   jt.TileWidth,
+		TileHeight:  jt.TileHeight,
+		Spacing:     jt.Spacing,
+		Margin:      jt.Margin,
+		TileCount:   jt.TileCount,
+		Columns:     jt.Columns,
+		Source:      jt.Source,
+		Image:       jt.Image,
+		ImageWidth:  jt.ImageWidth,
+		ImageHeight: jt.ImageHeight,
+		Tiles:       make(map[int]Properties),
+	}
+	for _, t := range jt.Tiles {
+		ts.Tiles[t.ID] = t.Properties.convert()
+	}
+	return ts
+}
+
+type jsonPoint struct {
+	X float64 `json:"x"`
+	Y float64 `json:"y"`
+}
+
+func convertPoints(jp []jsonPoint) []pixel.Vec {
+	if jp == nil {
+		return nil
+	}
+	points := make([]pixel.Vec, len(jp))
+	for i, p := range jp {
+		points[i] = pixel.V(p.X, p.Y)
+	}
+	return points
+}
+
+type jsonObject struct {
+	ID         int            `json:"id"`
+	Name       string         `json:"name"`
+	Type       string         `json:"type"`
+	Class      string         `json:"class"`
+	X          float64        `json:"x"`
+	Y          float64        `json:"y"`
+	Width      float64        `json:"width"`
+	Height     float64        `json:"height"`
+	Rotation   float64        `json:"rotation"`
+	GID        uint32         `json:"gid"`
+	Visible    *bool          `json:"visible"`
+	Ellipse    bool           `json:"ellipse"`
+	Point      bool           `json:"point"`
+	Polygon    []jsonPoint    `json:"polygon"`
+	Polyline   []jsonPoint    `json:"polyline"`
+	Properties jsonProperties `json:"properties"`
+}
+
+type jsonLayer struct {
+	Type        string          `json:"type"`
+	Name        string          `json:"name"`
+	Width       int             `json:"width"`
+	Height      int             `json:"height"`
+	OffsetX     float64         `json:"offsetx"`
+	OffsetY     float64         `json:"offsety"`
+	Opacity     *float64        `json:"opacity"`
+	Visible     *bool           `json:"visible"`
+	Data        json.RawMessage `json:"data"`
+	Encoding    string          `json:"encoding"`
+	Compression string          `json:"compression"`
+	Objects     []jsonObject    `json:"objects"`
+	Layers      []jsonLayer     `json:"layers"`
+	Properties  jsonProperties  `json:"properties"`
+}
+
+func (jl *jsonLayer) decode() ([]Tile, error) {
+	switch jl.Encoding {
+	case "", "csv":
+		var gids []uint32
+		if err := json.Unmarshal(jl.Data, &gids); err != nil {
+			return nil, errors.Wrap(err, "invalid tile data")
+		}
+		tiles := make([]Tile, len(gids))
+		for i, gid := range gids {
+			tiles[i] = Tile(gid)
+		}
+		return tiles, nil
+	case "base64":
+		var data string
+		if err := json.Unmarshal(jl.Data, &data); err != nil {
+			return nil, errors.Wrap(err, "invalid tile data")
+		}
+		return decodeBase64(data, jl.Compression)
+	default:
+		return nil, errors.Errorf("unsupported tile data encoding: %s", jl.Encoding)
+	}
+}
+
+type jsonMap struct {
+	Orientation string         `json:"orientation"`
+	Width       int            `json:"width"`
+	Height      int            `json:"height"`
+	TileWidth   int            `json:"tilewidth"`
+	TileHeight  int            `json:"tileheight"`
+	Infinite    bool           `json:"infinite"`
+	Tilesets    []jsonTileset  `json:"tilesets"`
+	Layers      []jsonLayer    `json:"layers"`
+	Properties  jsonProperties `json:"properties"`
+}
+
+func visible(v *bool) bool {
+	return v == nil || *v
+}
+
+// ReadJSON reads a map in the Tiled JSON format.
+//
+// Tileset images and external tilesets are not loaded, Tileset.Picture of all Tilesets has to be
+// set manually before drawing the map. Use Load to load a map including tilesets and images.
+func ReadJSON(r io.Reader) (*Map, error) {
+	var jm jsonMap
+	if err := json.NewDecoder(r).Decode(&jm); err != nil {
+		return nil, errors.Wrap(err, "failed to decode JSON map")
+	}
+	if jm.Infinite {
+		return nil, errors.New("infinite maps are not supported")
+	}
+
+	m := &Map{
+		Width:       jm.Width,
+		Height:      jm.Height,
+		TileWidth:   jm.TileWidth,
+		TileHeight:  jm.TileHeight,
+		Orientation: jm.Orientation,
+		Properties:  jm.Properties.convert(),
+	}
+	if m.Orientation != "orthogonal" {
+		return nil, errors.Errorf("unsupported map orientation: %s", m.Orientation)
+	}
+
+	for i := range jm.Tilesets {
+		m.Tilesets = append(m.Tilesets, jm.Tilesets[i].convert())
+	}
+
+	if err := m.addJSONLayers(jm.Layers, rootGroup); err != nil {
+		return nil, err
+	}
+
+	return m, nil
+}
+
+// addJSONLayers adds the layers inside of the group to the Map, in order.
+func (m *Map) addJSONLayers(layers []jsonLayer, g group) error {
+	for i := range layers {
+		jl := &layers[i]
+		lg := g.nest(pixel.V(jl.OffsetX, -jl.OffsetY), opacity(jl.Opacity), visible(jl.Visible))
+
+		switch jl.Type {
+		case "tilelayer":
+			tiles, err := jl.decode()
+			if err != nil {
+				return errors.Wrapf(err, "layer %q", jl.Name)
+			}
+			if err := checkTiles(jl.Name, tiles, jl.Width, jl.Height); err != nil {
+				return err
+			}
+			m.addTileLayer(&TileLayer{
+				Name:       jl.Name,
+				Width:      jl.Width,
+				Height:     jl.Height,
+				Offset:     lg.offset,
+				Opacity:    lg.opacity,
+				Visible:    lg.visible,
+				Tiles:      tiles,
+				Properties: jl.Properties.convert(),
+			})
+
+		case "objectgroup":
+			ol := &ObjectLayer{
+				Name:       jl.Name,
+				Offset:     lg.offset,
+				Opacity:    lg.opacity,
+				Visible:    lg.visible,
+				Properties: jl.Properties.convert(),
+			}
+			for _, jo := range jl.Objects {
+				raw := rawObject{
+					id:         jo.ID,
+					name:       jo.Name,
+					typ:        jo.Type,
+					x:          jo.X,
+					y:          jo.Y,
+					width:      jo.Width,
+					height:     jo.Height,
+					rotation:   jo.Rotation,
+					gid:        Tile(jo.GID),
+					visible:    visible(jo.Visible),
+					ellipse:    jo.Ellipse,
+					point:      jo.Point,
+					polygon:    convertPoints(jo.Polygon),
+					polyline:   convertPoints(jo.Polyline),
+					properties: jo.Properties.convert(),
+				}
+				if raw.typ == "" {
+					raw.typ = jo.Class
+				}
+				ol.Objects = append(ol.Objects, m.makeObject(raw, ol.Offset))
+			}
+			m.addObjectLayer(ol)
+
+		case "group":
+			if err := m.addJSONLayers(jl.Layers, lg); err != nil {
+				return errors.Wrapf(err, "group %q", jl.Name)
+			}
+
+		case "imagelayer":
+			return errors.Errorf("image layer %q: image layers are not supported", jl.Name)
+
+		default:
+			return errors.Errorf("layer %q: unsupported layer type: %s", jl.Name, jl.Type)
+		}
+	}
+	return nil
+}
+
+// readJSONTileset reads an external tileset in the Tiled JSON format.
+func readJSONTileset(r io.Reader) (*Tileset, error) {
+	var jt jsonTileset
+	if err := json.NewDecoder(r).Decode(&jt); err != nil {
+		return nil, errors.Wrap(err, "failed to decode JSON tileset")
+	}
+	return jt.convert(), nil
+}
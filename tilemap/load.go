@@ -0,0 +1,109 @@
+package tilemap
+
+import (
+	"image"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	// register the PNG format, the most common format of tileset images
+	_ "image/png"
+
+	"github.com/faiface/pixel"
+	"github.com/pkg/errors"
+)
+
+// Load loads a map from a TMX (.tmx) or JSON (.json, .tmj) file, including external tilesets
+// (.tsx, .json, .tsj) and tileset images. Image paths are resolved relative to the file that
+// references them.
+//
+// Tileset images are decoded using the image package, PNG is supported by default. Register
+// other formats by importing their packages.
+func Load(path string) (*Map, error) {
+	var read func(io.Reader) (*Map, error)
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".tmx":
+		read = ReadTMX
+	case ".json", ".tmj":
+		read = ReadJSON
+	default:
+		return nil, errors.Errorf("unknown map format: %s", path)
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	m, err := read(f)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to load %s", path)
+	}
+
+	dir := filepath.Dir(path)
+	for i, ts := range m.Tilesets {
+		imgDir := dir
+		if ts.Source != "" {
+			tsPath := filepath.Join(dir, ts.Source)
+			ext, err := loadTileset(tsPath)
+			if err != nil {
+				return nil, err
+			}
+			ext.FirstGID = ts.FirstGID
+			ext.Source = ts.Source
+			m.Tilesets[i] = ext
+			ts = ext
+			imgDir = filepath.Dir(tsPath)
+		}
+		if ts.Image == "" {
+			continue
+		}
+		pic, err := loadPicture(filepath.Join(imgDir, ts.Image))
+		if err != nil {
+			return nil, err
+		}
+		ts.Picture = pic
+	}
+
+	return m, nil
+}
+
+func loadTileset(path string) (*Tileset, error) {
+	var read func(io.Reader) (*Tileset, error)
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".tsx":
+		read = readTSX
+	case ".json", ".tsj":
+		read = readJSONTileset
+	default:
+		return nil, errors.Errorf("unknown tileset format: %s", path)
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	ts, err := read(f)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to load %s", path)
+	}
+	return ts, nil
+}
+
+func loadPicture(path string) (pixel.Picture, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	img, _, err := image.Decode(f)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to decode %s", path)
+	}
+	return pixel.PictureDataFromImage(img), nil
+}
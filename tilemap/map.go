@@ -0,0 +1,422 @@
+package tilemap
+
+import (
+	"math"
+	"strconv"
+
+	"github.com/faiface/pixel"
+)
+
+// Map is a tile map loaded from a Tiled map file.
+type Map struct {
+	// Width and Height are the size of the map in tiles.
+	Width, Height int
+
+	// TileWidth and TileHeight are the size of a single tile of the map grid.
+	TileWidth, TileHeight int
+
+	// Orientation is the orientation of the map, currently always "orthogonal".
+	Orientation string
+
+	Tilesets     []*Tileset
+	TileLayers   []*TileLayer
+	ObjectLayers []*ObjectLayer
+
+	// Layers lists all TileLayers and ObjectLayers in the order they are drawn in Tiled, bottom
+	// layer first, so objects can be drawn between the tile layers. Layers inside of groups are
+	// listed in place of their group.
+	Layers []Layer
+
+	Properties Properties
+}
+
+// Layer is a TileLayer or an ObjectLayer of a Map, exactly one of Tile and Object is set. Index is
+// the index of the layer in Map.TileLayers or Map.ObjectLayers.
+type Layer struct {
+	Tile   *TileLayer
+	Object *ObjectLayer
+	Index  int
+}
+
+// addTileLayer appends the layer to the TileLayers and the Layers of the Map.
+func (m *Map) addTileLayer(l *TileLayer) {
+	m.Layers = append(m.Layers, Layer{Tile: l, Index: len(m.TileLayers)})
+	m.TileLayers = append(m.TileLayers, l)
+}
+
+// addObjectLayer appends the layer to the ObjectLayers and the Layers of the Map.
+func (m *Map) addObjectLayer(l *ObjectLayer) {
+	m.Layers = append(m.Layers, Layer{Object: l, Index: len(m.ObjectLayers)})
+	m.ObjectLayers = append(m.ObjectLayers, l)
+}
+
+// group is the combined offset, opacity and visibility of the groups containing a layer. Tiled
+// applies them to all layers inside of a group, so they're applied to the layers when reading a
+// map and groups don't show up in the Map.
+type group struct {
+	offset  pixel.Vec
+	opacity float64
+	visible bool
+}
+
+// rootGroup contains the top-level layers of a map.
+var rootGroup = group{opacity: 1, visible: true}
+
+// nest returns the group combined with a layer or a group inside of it.
+func (g group) nest(offset pixel.Vec, opacity float64, visible bool) group {
+	return group{
+		offset:  g.offset.Add(offset),
+		opacity: g.opacity * opacity,
+		visible: g.visible && visible,
+	}
+}
+
+// Bounds returns the rectangle covered by the map, in Pixel's coordinates.
+func (m *Map) Bounds() pixel.Rect {
+	return pixel.R(0, 0, float64(m.Width*m.TileWidth), float64(m.Height*m.TileHeight))
+}
+
+// TileLayer returns the tile layer with the given name, or nil if there's no such layer.
+func (m *Map) TileLayer(name string) *TileLayer {
+	for _, l := range m.TileLayers {
+		if l.Name == name {
+			return l
+		}
+	}
+	return nil
+}
+
+// ObjectLayer returns the object layer with the given name, or nil if there's no such layer.
+func (m *Map) ObjectLayer(name string) *ObjectLayer {
+	for _, l := range m.ObjectLayers {
+		if l.Name == name {
+			return l
+		}
+	}
+	return nil
+}
+
+// Tileset returns the Tileset containing the given tile and the local ID of the tile within the
+// Tileset. If the tile is empty or not contained in any Tileset, nil is returned.
+func (m *Map) Tileset(t Tile) (ts *Tileset, id int) {
+	gid := t.GID()
+	if gid == 0 {
+		return nil, 0
+	}
+	for _, s := range m.Tilesets {
+		if s.FirstGID <= gid && (ts == nil || s.FirstGID > ts.FirstGID) {
+			ts = s
+		}
+	}
+	if ts == nil || (ts.TileCount > 0 && gid-ts.FirstGID >= ts.TileCount) {
+		return nil, 0
+	}
+	return ts, gid - ts.FirstGID
+}
+
+// TileRect returns the rectangle covered by the tile at the given grid position (in Tiled's
+// coordinates, row 0 being the top row) in Pixel's coordinates.
+func (m *Map) TileRect(x, y int) pixel.Rect {
+	h := float64(m.Height * m.TileHeight)
+	return pixel.R(
+		float64(x*m.TileWidth),
+		h-float64((y+1)*m.TileHeight),
+		float64((x+1)*m.TileWidth),
+		h-float64(y*m.TileHeight),
+	)
+}
+
+// toPixel converts a point from Tiled's coordinates to Pixel's coordinates.
+func (m *Map) toPixel(x, y float64) pixel.Vec {
+	return pixel.V(x, float64(m.Height*m.TileHeight)-y)
+}
+
+// Tileset is a set of tiles sharing one image.
+type Tileset struct {
+	// FirstGID is the global ID of the first tile of the Tileset.
+	FirstGID int
+
+	Name string
+
+	// TileWidth and TileHeight are the size of a tile in the Tileset.
+	TileWidth, TileHeight int
+
+	// Spacing is the space between the tiles in the image, Margin is the space around the tiles.
+	Spacing, Margin int
+
+	TileCount, Columns int
+
+	// Source is the path of the external tileset file, if the Tileset was stored externally.
+	Source string
+
+	// Image is the path of the Tileset's image, relative to the file that defined the Tileset.
+	Image                   string
+	ImageWidth, ImageHeight int
+
+	// Picture is the Picture containing the Tileset's image. It's set by Load, when reading a map
+	// from an io.Reader, it has to be set manually before creating a Renderer.
+	Picture pixel.Picture
+
+	// Tiles contains the custom properties of individual tiles by their local ID.
+	Tiles map[int]Properties
+}
+
+// Frame returns the frame of the tile with the given local ID inside the Tileset's Picture.
+func (ts *Tileset) Frame(id int) pixel.Rect {
+	columns := ts.Columns
+	if columns <= 0 {
+		columns = 1
+	}
+	col, row := id%columns, id/columns
+	x := ts.Margin + col*(ts.TileWidth+ts.Spacing)
+	y := ts.Margin + row*(ts.TileHeight+ts.Spacing)
+
+	// the image is flipped, Picture's Y axis points up
+	var bounds pixel.Rect
+	if ts.Picture != nil {
+		bounds = ts.Picture.Bounds()
+	} else {
+		bounds = pixel.R(0, 0, float64(ts.ImageWidth), float64(ts.ImageHeight))
+	}
+	return pixel.R(
+		bounds.Min.X+float64(x),
+		bounds.Max.Y-float64(y+ts.TileHeight),
+		bounds.Min.X+float64(x+ts.TileWidth),
+		bounds.Max.Y-float64(y),
+	)
+}
+
+// Tile is a reference to a tile as stored in a Tiled map: a global tile ID combined with flip
+// flags. Zero is an empty tile.
+type Tile uint32
+
+const (
+	flipH    = 0x80000000
+	flipV    = 0x40000000
+	flipD    = 0x20000000
+	flipHex  = 0x10000000
+	flipMask = flipH | flipV | flipD | flipHex
+)
+
+// GID returns the global ID of the tile without the flip flags.
+func (t Tile) GID() int {
+	return int(t &^ flipMask)
+}
+
+// Empty reports whether the Tile is empty.
+func (t Tile) Empty() bool {
+	return t.GID() == 0
+}
+
+// FlippedH reports whether the tile is flipped horizontally.
+func (t Tile) FlippedH() bool {
+	return t&flipH != 0
+}
+
+// FlippedV reports whether the tile is flipped vertically.
+func (t Tile) FlippedV() bool {
+	return t&flipV != 0
+}
+
+// FlippedD reports whether the tile is flipped diagonally (it's X and Y axes are swapped). The
+// diagonal flip is applied before the horizontal and vertical flips.
+func (t Tile) FlippedD() bool {
+	return t&flipD != 0
+}
+
+// TileLayer is a layer of tiles covering the map grid.
+type TileLayer struct {
+	Name string
+
+	// Width and Height are the size of the layer in tiles.
+	Width, Height int
+
+	// Offset is the offset of the whole layer in Pixel's coordinates.
+	Offset pixel.Vec
+
+	Opacity float64
+	Visible bool
+
+	// Tiles are the tiles of the layer, row by row, starting with the top row (as in Tiled).
+	Tiles []Tile
+
+	Properties Properties
+}
+
+// Tile returns the tile at the given grid position (in Tiled's coordinates, row 0 being the top
+// row). Positions outside of the layer return an empty tile.
+func (l *TileLayer) Tile(x, y int) Tile {
+	if x < 0 || y < 0 || x >= l.Width || y >= l.Height {
+		return 0
+	}
+	return l.Tiles[y*l.Width+x]
+}
+
+// ObjectLayer is a layer of free-form objects, such as collision shapes and spawn points.
+type ObjectLayer struct {
+	Name       string
+	Offset     pixel.Vec
+	Opacity    float64
+	Visible    bool
+	Objects    []*Object
+	Properties Properties
+}
+
+// ObjectKind specifies the shape of an Object.
+type ObjectKind int
+
+// Here's the list of all kinds of Objects.
+const (
+	RectObject ObjectKind = iota
+	EllipseObject
+	PointObject
+	PolygonObject
+	PolylineObject
+	TileObject
+)
+
+// Object is an object of an ObjectLayer. All geometry is converted to Pixel's coordinates,
+// including the offset of the layer and the rotation of the object.
+type Object struct {
+	ID         int
+	Name, Type string
+	Kind       ObjectKind
+	Visible    bool
+
+	// Rotation is the rotation in radians, counter-clockwise (converted from Tiled's clockwise
+	// degrees).
+	Rotation float64
+
+	// Bounds is the bounding box of the object.
+	Bounds pixel.Rect
+
+	// Polygon contains the vertices of the object for RectObject, TileObject (the four corners),
+	// PolygonObject and PolylineObject. It's nil for other kinds.
+	Polygon []pixel.Vec
+
+	// Tile is the tile of a TileObject.
+	Tile Tile
+
+	Properties Properties
+}
+
+// rawObject is an object as stored in Tiled's coordinates, before conversion.
+type rawObject struct {
+	id                  int
+	name, typ           string
+	x, y, width, height float64
+	rotation            float64
+	gid                 Tile
+	visible             bool
+	ellipse, point      bool
+	polygon, polyline   []pixel.Vec
+	properties          Properties
+}
+
+// makeObject converts an object from Tiled's coordinates.
+func (m *Map) makeObject(raw rawObject, offset pixel.Vec) *Object {
+	obj := &Object{
+		ID:         raw.id,
+		Name:       raw.name,
+		Type:       raw.typ,
+		Visible:    raw.visible,
+		Rotation:   -raw.rotation * math.Pi / 180,
+		Tile:       raw.gid,
+		Properties: raw.properties,
+	}
+
+	var local []pixel.Vec
+	switch {
+	case raw.gid != 0:
+		// tile objects are anchored at their bottom-left corner
+		obj.Kind = TileObject
+		local = []pixel.Vec{
+			pixel.V(0, 0),
+			pixel.V(raw.width, 0),
+			pixel.V(raw.width, -raw.height),
+			pixel.V(0, -raw.height),
+		}
+	case raw.point:
+		obj.Kind = PointObject
+		local = []pixel.Vec{pixel.ZV}
+	case raw.polygon != nil:
+		obj.Kind = PolygonObject
+		local = raw.polygon
+	case raw.polyline != nil:
+		obj.Kind = PolylineObject
+		local = raw.polyline
+	default:
+		obj.Kind = RectObject
+		if raw.ellipse {
+			obj.Kind = EllipseObject
+		}
+		local = []pixel.Vec{
+			pixel.V(0, raw.height),
+			pixel.V(raw.width, raw.height),
+			pixel.V(raw.width, 0),
+			pixel.V(0, 0),
+		}
+	}
+
+	// rotate around the object's position in Tiled's coordinates (clockwise with the Y axis
+	// pointing down), then convert to Pixel's coordinates
+	sin, cos := math.Sincos(raw.rotation * math.Pi / 180)
+	points := make([]pixel.Vec, len(local))
+	for i, p := range local {
+		x := raw.x + p.X*cos - p.Y*sin
+		y := raw.y + p.X*sin + p.Y*cos
+		points[i] = m.toPixel(x, y).Add(offset)
+	}
+
+	obj.Bounds = pixel.Rect{Min: points[0], Max: points[0]}
+	for _, p := range points[1:] {
+		obj.Bounds = obj.Bounds.Union(pixel.Rect{Min: p, Max: p})
+	}
+	if obj.Kind != PointObject && obj.Kind != EllipseObject {
+		obj.Polygon = points
+	}
+
+	return obj
+}
+
+// Properties are custom properties of a map, layer, object or tile. Values are stored in their
+// textual representation.
+type Properties map[string]string
+
+// String returns the value of the property, or an empty string if it doesn't exist.
+func (p Properties) String(name string) string {
+	return p[name]
+}
+
+// Int returns the value of the property as an int. If the property doesn't exist or isn't an
+// integer, false is returned.
+func (p Properties) Int(name string) (int, bool) {
+	v, ok := p[name]
+	if !ok {
+		return 0, false
+	}
+	i, err := strconv.Atoi(v)
+	return i, err == nil
+}
+
+// Float returns the value of the property as a float64. If the property doesn't exist or isn't a
+// number, false is returned.
+func (p Properties) Float(name string) (float64, bool) {
+	v, ok := p[name]
+	if !ok {
+		return 0, false
+	}
+	f, err := strconv.ParseFloat(v, 64)
+	return f, err == nil
+}
+
+// Bool returns the value of the property as a bool. If the property doesn't exist or isn't a
+// boolean, false is returned as the second value.
+func (p Properties) Bool(name string) (value, ok bool) {
+	v, ok := p[name]
+	if !ok {
+		return false, false
+	}
+	b, err := strconv.ParseBool(v)
+	return b, err == nil
+}
@@ -0,0 +1,215 @@
+package tilemap
+
+import (
+	"github.com/faiface/pixel"
+	"github.com/pkg/errors"
+)
+
+// Renderer draws the tile layers of a Map efficiently.
+//
+// Each tile layer is split into square chunks of tiles. Every chunk holds one pixel.Batch per
+// Tileset used within the chunk, so drawing a chunk takes one draw call per Tileset. Chunks that
+// don't intersect the view rectangle are not drawn at all.
+//
+//   r, err := tilemap.NewRenderer(m, 16)
+//   if err != nil {
+//       panic(err)
+//   }
+//   view := pixel.Rect{Min: cam.Unproject(win.Bounds().Min), Max: cam.Unproject(win.Bounds().Max)}
+//   r.Draw(win, view)
+//
+// Within a chunk, tiles from different Tilesets are drawn Tileset by Tileset, so overlapping tiles
+// of different Tilesets within one layer may not be drawn in the original order.
+type Renderer struct {
+	m         *Map
+	chunkSize int
+	layers    [][]*chunk
+}
+
+type chunk struct {
+	bounds  pixel.Rect
+	batches []*pixel.Batch
+}
+
+// NewRenderer creates a Renderer of the Map's tile layers with chunks of chunkSize x chunkSize
+// tiles.
+//
+// All Tilesets used by the tile layers must have a Picture set, otherwise an error is returned.
+func NewRenderer(m *Map, chunkSize int) (*Renderer, error) {
+	if chunkSize <= 0 {
+		return nil, errors.Errorf("invalid chunk size: %d", chunkSize)
+	}
+	r := &Renderer{
+		m:         m,
+		chunkSize: chunkSize,
+	}
+	if err := r.Rebuild(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// Map returns the Map drawn by the Renderer.
+func (r *Renderer) Map() *Map {
+	return r.m
+}
+
+// Rebuild regenerates the geometry of all chunks. Call it after changing the tiles, layers or
+// Tilesets of the Map.
+func (r *Renderer) Rebuild() error {
+	r.layers = make([][]*chunk, len(r.m.TileLayers))
+	for i, l := range r.m.TileLayers {
+		chunks, err := r.buildLayer(l)
+		if err != nil {
+			return err
+		}
+		r.layers[i] = chunks
+	}
+	return nil
+}
+
+// Draw draws all visible tile layers onto the Target, in the order they were defined in the Map.
+// Only chunks intersecting the view rectangle (in the Map's coordinates) are drawn. A view of zero
+// area draws all chunks.
+//
+// To draw objects between the tile layers, go through the Map's Layers instead:
+//
+//   for _, l := range m.Layers {
+//       if l.Tile != nil && l.Tile.Visible {
+//           r.DrawLayer(win, l.Index, view)
+//       }
+//       if l.Object != nil {
+//           // draw the objects of l.Object
+//       }
+//   }
+func (r *Renderer) Draw(t pixel.Target, view pixel.Rect) {
+	for i, l := range r.m.TileLayers {
+		if l.Visible {
+			r.DrawLayer(t, i, view)
+		}
+	}
+}
+
+// DrawLayer draws the i-th tile layer of the Map onto the Target, regardless of it's visibility.
+// Only chunks intersecting the view rectangle (in the Map's coordinates) are drawn. A view of zero
+// area draws all chunks.
+func (r *Renderer) DrawLayer(t pixel.Target, i int, view pixel.Rect) {
+	cull := view.W()*view.H() != 0
+	view = view.Norm()
+	for _, c := range r.layers[i] {
+		if cull && !c.bounds.Intersects(view) {
+			continue
+		}
+		for _, b := range c.batches {
+			b.Draw(t)
+		}
+	}
+}
+
+func (r *Renderer) buildLayer(l *TileLayer) ([]*chunk, error) {
+	var chunks []*chunk
+	for cy := 0; cy < l.Height; cy += r.chunkSize {
+		for cx := 0; cx < l.Width; cx += r.chunkSize {
+			c, err := r.buildChunk(l, cx, cy)
+			if err != nil {
+				return nil, err
+			}
+			if c != nil {
+				chunks = append(chunks, c)
+			}
+		}
+	}
+	return chunks, nil
+}
+
+// buildChunk generates the geometry of the chunk with the top-left tile at (cx, cy). If the chunk
+// is empty, nil is returned.
+func (r *Renderer) buildChunk(l *TileLayer, cx, cy int) (*chunk, error) {
+	var (
+		c        = &chunk{}
+		tilesets []*Tileset
+		tris     = make(map[*Tileset]*pixel.TrianglesData)
+		empty    = true
+	)
+
+	for y := cy; y < cy+r.chunkSize && y < l.Height; y++ {
+		for x := cx; x < cx+r.chunkSize && x < l.Width; x++ {
+			tile := l.Tile(x, y)
+			ts, id := r.m.Tileset(tile)
+			if ts == nil {
+				continue
+			}
+			if ts.Picture == nil {
+				return nil, errors.Errorf("tileset %q has no Picture", ts.Name)
+			}
+
+			td := tris[ts]
+			if td == nil {
+				td = &pixel.TrianglesData{}
+				tris[ts] = td
+				tilesets = append(tilesets, ts)
+			}
+
+			// tiles are anchored at the bottom-left corner of their cell
+			cell := r.m.TileRect(x, y).Moved(l.Offset)
+			rect := cell.ResizedMin(pixel.V(float64(ts.TileWidth), float64(ts.TileHeight)))
+			appendTile(td, rect, ts.Frame(id), tile, pixel.Alpha(l.Opacity))
+
+			if empty {
+				c.bounds = rect
+				empty = false
+			} else {
+				c.bounds = c.bounds.Union(rect)
+			}
+		}
+	}
+
+	if empty {
+		return nil, nil
+	}
+	for _, ts := range tilesets {
+		c.batches = append(c.batches, pixel.NewBatch(tris[ts], ts.Picture))
+	}
+	return c, nil
+}
+
+// appendTile appends two triangles drawing the frame into the rectangle, applying the flip flags
+// of the tile.
+func appendTile(td *pixel.TrianglesData, rect, frame pixel.Rect, tile Tile, col pixel.RGBA) {
+	// bottom-left, bottom-right, top-right, top-left
+	pos := [...]pixel.Vec{
+		rect.Min,
+		pixel.V(rect.Max.X, rect.Min.Y),
+		rect.Max,
+		pixel.V(rect.Min.X, rect.Max.Y),
+	}
+	// the same corners in Tiled's texture coordinates, Y axis pointing down
+	uv := [...]pixel.Vec{{X: 0, Y: 1}, {X: 1, Y: 1}, {X: 1, Y: 0}, {X: 0, Y: 0}}
+
+	var pic [4]pixel.Vec
+	for i, p := range uv {
+		// inverse of the flips, which are applied diagonal first, then horizontal and vertical
+		if tile.FlippedV() {
+			p.Y = 1 - p.Y
+		}
+		if tile.FlippedH() {
+			p.X = 1 - p.X
+		}
+		if tile.FlippedD() {
+			p.X, p.Y = p.Y, p.X
+		}
+		pic[i] = pixel.V(
+			frame.Min.X+p.X*frame.W(),
+			frame.Max.Y-p.Y*frame.H(),
+		)
+	}
+
+	off := td.Len()
+	td.SetLen(off + 6)
+	for k, l := range [...]int{0, 1, 2, 0, 2, 3} {
+		(*td)[off+k].Position = pos[l]
+		(*td)[off+k].Color = col
+		(*td)[off+k].Picture = pic[l]
+		(*td)[off+k].Intensity = 1
+	}
+}
@@ -0,0 +1,352 @@
+package tilemap_test
+
+import (
+	"io"
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/faiface/pixel"
+	"github.com/faiface/pixel/tilemap"
+)
+
+// a 4x3 map with 16x16 tiles and a single 64x32 tileset (4 columns, 2 rows)
+const testTMX = `<?xml version="1.0" encoding="UTF-8"?>
+<map version="1.2" orientation="orthogonal" renderorder="right-down" width="4" height="3" tilewidth="16" tileheight="16" infinite="0">
+ <properties>
+  <property name="music" value="theme.ogg"/>
+  <property name="gravity" type="float" value="9.8"/>
+ </properties>
+ <tileset firstgid="1" name="ground" tilewidth="16" tileheight="16" tilecount="8" columns="4">
+  <image source="ground.png" width="64" height="32"/>
+  <tile id="3">
+   <properties>
+    <property name="solid" type="bool" value="true"/>
+   </properties>
+  </tile>
+ </tileset>
+ <layer id="1" name="ground" width="4" height="3">
+  <data encoding="csv">
+1,2,0,3,
+2147483649,4,4,536870914,
+5,0,0,1
+</data>
+ </layer>
+ <layer id="2" name="zlib" width="4" height="3" opacity="0.5" visible="0">
+  <data encoding="base64" compression="zlib">eJxjZGBgYGKAAGYgZmRgaGAB0iwQcQVWBgQAyjEAABMwALg=</data>
+ </layer>
+ <objectgroup id="3" name="collision">
+  <object id="1" name="wall" type="solid" x="0" y="0" width="32" height="16"/>
+  <object id="2" name="spawn" x="8" y="40">
+   <point/>
+  </object>
+  <object id="3" x="16" y="16">
+   <polygon points="0,0 16,0 16,16"/>
+  </object>
+  <object id="4" x="0" y="48" width="16" height="16" rotation="90"/>
+ </objectgroup>
+</map>
+`
+
+const testJSON = `{
+ "orientation": "orthogonal",
+ "width": 4, "height": 3, "tilewidth": 16, "tileheight": 16, "infinite": false,
+ "properties": [{"name": "music", "type": "string", "value": "theme.ogg"}],
+ "tilesets": [{
+  "firstgid": 1, "name": "ground", "tilewidth": 16, "tileheight": 16, "tilecount": 8, "columns": 4,
+  "image": "ground.png", "imagewidth": 64, "imageheight": 32,
+  "tiles": [{"id": 3, "properties": [{"name": "solid", "type": "bool", "value": true}]}]
+ }],
+ "layers": [
+  {"type": "tilelayer", "name": "ground", "width": 4, "height": 3, "opacity": 1, "visible": true,
+   "data": [1, 2, 0, 3, 2147483649, 4, 4, 536870914, 5, 0, 0, 1]},
+  {"type": "tilelayer", "name": "base64", "width": 4, "height": 3, "opacity": 1, "visible": true,
+   "encoding": "base64", "data": "AQAAAAIAAAAAAAAAAwAAAAEAAIAEAAAABAAAAAIAACAFAAAAAAAAAAAAAAABAAAA"},
+  {"type": "objectgroup", "name": "collision", "opacity": 1, "visible": true, "objects": [
+   {"id": 1, "name": "wall", "type": "solid", "x": 0, "y": 0, "width": 32, "height": 16, "visible": true},
+   {"id": 2, "name": "spawn", "x": 8, "y": 40, "point": true, "visible": true}
+  ]}
+ ]
+}`
+
+func TestReadTMX(t *testing.T) {
+	m, err := tilemap.ReadTMX(strings.NewReader(testTMX))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got, want := m.Bounds(), pixel.R(0, 0, 64, 48); got != want {
+		t.Errorf("Bounds() = %v, want %v", got, want)
+	}
+	if got, want := m.Properties.String("music"), "theme.ogg"; got != want {
+		t.Errorf("music = %q, want %q", got, want)
+	}
+	if got, ok := m.Properties.Float("gravity"); !ok || got != 9.8 {
+		t.Errorf("gravity = %v, %v, want 9.8", got, ok)
+	}
+	if solid, ok := m.Tilesets[0].Tiles[3].Bool("solid"); !ok || !solid {
+		t.Errorf("tile 3 is not solid")
+	}
+
+	ground := m.TileLayer("ground")
+	zlib := m.TileLayer("zlib")
+	if ground == nil || zlib == nil {
+		t.Fatalf("missing tile layers")
+	}
+	for i := range ground.Tiles {
+		if ground.Tiles[i] != zlib.Tiles[i] {
+			t.Errorf("tile %d: csv %v != zlib %v", i, ground.Tiles[i], zlib.Tiles[i])
+		}
+	}
+	if zlib.Visible || zlib.Opacity != 0.5 {
+		t.Errorf("zlib: Visible = %v, Opacity = %v", zlib.Visible, zlib.Opacity)
+	}
+
+	tile := ground.Tile(0, 1)
+	if tile.GID() != 1 || !tile.FlippedH() || tile.FlippedV() || tile.FlippedD() {
+		t.Errorf("tile (0, 1) = %d, flipped %v %v %v", tile.GID(), tile.FlippedH(), tile.FlippedV(), tile.FlippedD())
+	}
+	if tile := ground.Tile(3, 1); tile.GID() != 2 || !tile.FlippedD() {
+		t.Errorf("tile (3, 1) = %d, diagonal %v", tile.GID(), tile.FlippedD())
+	}
+	if ts, id := m.Tileset(ground.Tile(2, 2)); ts != nil || id != 0 {
+		t.Errorf("empty tile has tileset %v", ts)
+	}
+	if ts, id := m.Tileset(ground.Tile(0, 2)); ts != m.Tilesets[0] || id != 4 {
+		t.Errorf("tile (0, 2) has local id %d", id)
+	}
+
+	// tile with local ID 4 is the first tile of the second row
+	if got, want := m.Tilesets[0].Frame(4), pixel.R(0, 0, 16, 16); got != want {
+		t.Errorf("Frame(4) = %v, want %v", got, want)
+	}
+	if got, want := m.Tilesets[0].Frame(1), pixel.R(16, 16, 32, 32); got != want {
+		t.Errorf("Frame(1) = %v, want %v", got, want)
+	}
+}
+
+func TestReadTMX_Objects(t *testing.T) {
+	m, err := tilemap.ReadTMX(strings.NewReader(testTMX))
+	if err != nil {
+		t.Fatal(err)
+	}
+	objs := m.ObjectLayer("collision").Objects
+	if len(objs) != 4 {
+		t.Fatalf("got %d objects, want 4", len(objs))
+	}
+
+	wall := objs[0]
+	if wall.Kind != tilemap.RectObject || wall.Type != "solid" {
+		t.Errorf("wall: Kind = %v, Type = %q", wall.Kind, wall.Type)
+	}
+	if got, want := wall.Bounds, pixel.R(0, 32, 32, 48); got != want {
+		t.Errorf("wall: Bounds = %v, want %v", got, want)
+	}
+
+	spawn := objs[1]
+	if spawn.Kind != tilemap.PointObject || spawn.Bounds.Min != pixel.V(8, 8) {
+		t.Errorf("spawn: Kind = %v, Bounds = %v", spawn.Kind, spawn.Bounds)
+	}
+
+	poly := objs[2]
+	want := []pixel.Vec{pixel.V(16, 32), pixel.V(32, 32), pixel.V(32, 16)}
+	if poly.Kind != tilemap.PolygonObject || len(poly.Polygon) != len(want) {
+		t.Fatalf("poly: Kind = %v, Polygon = %v", poly.Kind, poly.Polygon)
+	}
+	for i := range want {
+		if poly.Polygon[i] != want[i] {
+			t.Errorf("poly: vertex %d = %v, want %v", i, poly.Polygon[i], want[i])
+		}
+	}
+
+	// rotated by 90 degrees clockwise around the top-left corner at (0, 0) in Pixel's coordinates
+	rotated := objs[3]
+	bounds := rotated.Bounds
+	for _, v := range []float64{bounds.Min.X, bounds.Min.Y, bounds.Max.X, bounds.Max.Y} {
+		if math.Abs(v-math.Round(v)) > 1e-9 {
+			t.Fatalf("rotated: Bounds = %v", bounds)
+		}
+	}
+	bounds = pixel.R(math.Round(bounds.Min.X), math.Round(bounds.Min.Y), math.Round(bounds.Max.X), math.Round(bounds.Max.Y))
+	if got, want := bounds, pixel.R(-16, -16, 0, 0); got != want {
+		t.Errorf("rotated: Bounds = %v, want %v", got, want)
+	}
+}
+
+func TestReadJSON(t *testing.T) {
+	tmx, err := tilemap.ReadTMX(strings.NewReader(testTMX))
+	if err != nil {
+		t.Fatal(err)
+	}
+	m, err := tilemap.ReadJSON(strings.NewReader(testJSON))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got, want := m.Properties.String("music"), "theme.ogg"; got != want {
+		t.Errorf("music = %q, want %q", got, want)
+	}
+	if solid, ok := m.Tilesets[0].Tiles[3].Bool("solid"); !ok || !solid {
+		t.Errorf("tile 3 is not solid")
+	}
+	for _, name := range []string{"ground", "base64"} {
+		l := m.TileLayer(name)
+		if l == nil {
+			t.Fatalf("missing layer %q", name)
+		}
+		for i, tile := range tmx.TileLayer("ground").Tiles {
+			if l.Tiles[i] != tile {
+				t.Errorf("%s: tile %d = %v, want %v", name, i, l.Tiles[i], tile)
+			}
+		}
+	}
+	for i, obj := range m.ObjectLayer("collision").Objects {
+		if want := tmx.ObjectLayer("collision").Objects[i]; obj.Bounds != want.Bounds || obj.Kind != want.Kind {
+			t.Errorf("object %d: %v %v, want %v %v", i, obj.Kind, obj.Bounds, want.Kind, want.Bounds)
+		}
+	}
+}
+
+// a 1x1 map with a group of layers between two top-level layers
+const testGroupTMX = `<?xml version="1.0" encoding="UTF-8"?>
+<map version="1.2" orientation="orthogonal" width="1" height="1" tilewidth="16" tileheight="16" infinite="0">
+ <layer id="1" name="bottom" width="1" height="1"><data encoding="csv">0</data></layer>
+ <group id="2" name="group" offsetx="4" offsety="2" opacity="0.5">
+  <objectgroup id="3" name="objects" offsetx="1" opacity="0.5">
+   <object id="1" x="0" y="0" width="8" height="8"/>
+  </objectgroup>
+  <group id="4" name="hidden" visible="0">
+   <layer id="5" name="middle" width="1" height="1"><data encoding="csv">0</data></layer>
+  </group>
+ </group>
+ <layer id="6" name="top" width="1" height="1"><data encoding="csv">0</data></layer>
+</map>
+`
+
+const testGroupJSON = `{
+ "orientation": "orthogonal", "width": 1, "height": 1, "tilewidth": 16, "tileheight": 16,
+ "layers": [
+  {"type": "tilelayer", "name": "bottom", "width": 1, "height": 1, "data": [0]},
+  {"type": "group", "name": "group", "offsetx": 4, "offsety": 2, "opacity": 0.5, "layers": [
+   {"type": "objectgroup", "name": "objects", "offsetx": 1, "opacity": 0.5, "objects": [
+    {"id": 1, "x": 0, "y": 0, "width": 8, "height": 8}
+   ]},
+   {"type": "group", "name": "hidden", "visible": false, "layers": [
+    {"type": "tilelayer", "name": "middle", "width": 1, "height": 1, "data": [0]}
+   ]}
+  ]},
+  {"type": "tilelayer", "name": "top", "width": 1, "height": 1, "data": [0]}
+ ]
+}`
+
+func TestReadGroups(t *testing.T) {
+	for _, tt := range []struct {
+		name string
+		read func(r io.Reader) (*tilemap.Map, error)
+		data string
+	}{
+		{"TMX", tilemap.ReadTMX, testGroupTMX},
+		{"JSON", tilemap.ReadJSON, testGroupJSON},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			m, err := tt.read(strings.NewReader(tt.data))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var names []string
+			for _, l := range m.Layers {
+				switch {
+				case l.Tile != nil && m.TileLayers[l.Index] == l.Tile:
+					names = append(names, l.Tile.Name)
+				case l.Object != nil && m.ObjectLayers[l.Index] == l.Object:
+					names = append(names, l.Object.Name)
+				default:
+					t.Fatalf("invalid layer %+v", l)
+				}
+			}
+			if got, want := strings.Join(names, " "), "bottom objects middle top"; got != want {
+				t.Errorf("Layers = %s, want %s", got, want)
+			}
+
+			objects := m.ObjectLayer("objects")
+			if got, want := objects.Offset, pixel.V(5, -2); got != want {
+				t.Errorf("objects: Offset = %v, want %v", got, want)
+			}
+			if objects.Opacity != 0.25 || !objects.Visible {
+				t.Errorf("objects: Opacity = %v, Visible = %v", objects.Opacity, objects.Visible)
+			}
+			if got, want := objects.Objects[0].Bounds, pixel.R(5, 6, 13, 14); got != want {
+				t.Errorf("object: Bounds = %v, want %v", got, want)
+			}
+
+			middle := m.TileLayer("middle")
+			if got, want := middle.Offset, pixel.V(4, -2); got != want || middle.Opacity != 0.5 || middle.Visible {
+				t.Errorf("middle: Offset = %v, Opacity = %v, Visible = %v", middle.Offset, middle.Opacity, middle.Visible)
+			}
+		})
+	}
+
+	imageTMX := strings.Replace(testGroupTMX, `<layer id="6" name="top" width="1" height="1"><data encoding="csv">0</data></layer>`,
+		`<imagelayer id="6" name="top"><image source="sky.png"/></imagelayer>`, 1)
+	if _, err := tilemap.ReadTMX(strings.NewReader(imageTMX)); err == nil {
+		t.Errorf("ReadTMX succeeded with an image layer")
+	}
+	imageJSON := strings.Replace(testGroupJSON, `"type": "tilelayer", "name": "top"`, `"type": "imagelayer", "name": "top"`, 1)
+	if _, err := tilemap.ReadJSON(strings.NewReader(imageJSON)); err == nil {
+		t.Errorf("ReadJSON succeeded with an image layer")
+	}
+}
+
+func TestRenderer(t *testing.T) {
+	m, err := tilemap.ReadTMX(strings.NewReader(testTMX))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := tilemap.NewRenderer(m, 2); err == nil {
+		t.Fatalf("NewRenderer succeeded without a tileset Picture")
+	}
+
+	pic := pixel.MakePictureData(pixel.R(0, 0, 64, 32))
+	m.Tilesets[0].Picture = pic
+
+	r, err := tilemap.NewRenderer(m, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name  string
+		view  pixel.Rect
+		tiles int
+	}{
+		{"All", pixel.ZR, 9},
+		{"Top-left chunk", pixel.R(0, 40, 8, 48), 4},
+		{"Bottom chunks", pixel.R(0, 0, 64, 8), 2},
+		{"Outside", pixel.R(100, 100, 200, 200), 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out := &pixel.TrianglesData{}
+			// the second layer is invisible and not drawn
+			r.Draw(pixel.NewBatch(out, pic), tt.view)
+			if got, want := out.Len(), 6*tt.tiles; got != want {
+				t.Errorf("out.Len() = %d, want %d", got, want)
+			}
+		})
+	}
+
+	// the horizontally flipped tile (0, 1) shows the frame mirrored
+	out := &pixel.TrianglesData{}
+	r.DrawLayer(pixel.NewBatch(out, pic), 0, pixel.R(0, 16, 16, 32))
+	for i := 0; i < out.Len(); i++ {
+		if (*out)[i].Position == pixel.V(0, 16) {
+			if got, want := (*out)[i].Picture, pixel.V(16, 16); got != want {
+				t.Errorf("flipped tile: Picture = %v, want %v", got, want)
+			}
+			return
+		}
+	}
+	t.Errorf("flipped tile not drawn")
+}
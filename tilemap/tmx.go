@@ -0,0 +1,328 @@
+package tilemap
+
+import (
+	"encoding/xml"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/faiface/pixel"
+	"github.com/pkg/errors"
+)
+
+type tmxProperty struct {
+	Name  string `xml:"name,attr"`
+	Value string `xml:"value,attr"`
+	Text  string `xml:",chardata"`
+}
+
+type tmxProperties struct {
+	Properties []tmxProperty `xml:"property"`
+}
+
+func (tp *tmxProperties) convert() Properties {
+	props := make(Properties)
+	if tp == nil {
+		return props
+	}
+	for _, p := range tp.Properties {
+		if p.Value == "" {
+			// multi-line string properties are stored as the element's content
+			props[p.Name] = p.Text
+			continue
+		}
+		props[p.Name] = p.Value
+	}
+	return props
+}
+
+type tmxImage struct {
+	Source string `xml:"source,attr"`
+	Width  int    `xml:"width,attr"`
+	Height int    `xml:"height,attr"`
+}
+
+type tmxTile struct {
+	ID         int            `xml:"id,attr"`
+	Properties *tmxProperties `xml:"properties"`
+}
+
+type tmxTileset struct {
+	FirstGID   int       `xml:"firstgid,attr"`
+	Source     string    `xml:"source,attr"`
+	Name       string    `xml:"name,attr"`
+	TileWidth  int       `xml:"tilewidth,attr"`
+	TileHeight int       `xml:"tileheight,attr"`
+	Spacing    int       `xml:"spacing,attr"`
+	Margin     int       `xml:"margin,attr"`
+	TileCount  int       `xml:"tilecount,attr"`
+	Columns    int       `xml:"columns,attr"`
+	Image      tmxImage  `xml:"image"`
+	Tiles      []tmxTile `xml:"tile"`
+}
+
+func (tt *tmxTileset) convert() *Tileset {
+	ts := &Tileset{
+		FirstGID:    tt.FirstGID,
+		Name:        tt.Name,
+		TileWidth:   tt.TileWidth,
+		TileHeight:  tt.TileHeight,
+		Spacing:     tt.Spacing,
+		Margin:      tt.Margin,
+		TileCount:   tt.TileCount,
+		Columns:     tt.Columns,
+		Source:      tt.Source,
+		Image:       tt.Image.Source,
+		ImageWidth:  tt.Image.Width,
+		ImageHeight: tt.Image.Height,
+		Tiles:       make(map[int]Properties),
+	}
+	for _, t := range tt.Tiles {
+		ts.Tiles[t.ID] = t.Properties.convert()
+	}
+	return ts
+}
+
+type tmxData struct {
+	Encoding    string `xml:"encoding,attr"`
+	Compression string `xml:"compression,attr"`
+	Text        string `xml:",chardata"`
+	Tiles       []struct {
+		GID uint32 `xml:"gid,attr"`
+	} `xml:"tile"`
+}
+
+func (td *tmxData) decode() ([]Tile, error) {
+	switch td.Encoding {
+	case "csv":
+		return decodeCSV(td.Text)
+	case "base64":
+		return decodeBase64(td.Text, td.Compression)
+	case "":
+		tiles := make([]Tile, len(td.Tiles))
+		for i, t := range td.Tiles {
+			tiles[i] = Tile(t.GID)
+		}
+		return tiles, nil
+	default:
+		return nil, errors.Errorf("unsupported tile data encoding: %s", td.Encoding)
+	}
+}
+
+// tmxLayer is any layer of a TMX map: a tile layer, an object group, an image layer or a group of
+// layers. The kind of the layer is the name of it's element.
+type tmxLayer struct {
+	XMLName    xml.Name
+	Name       string         `xml:"name,attr"`
+	Width      int            `xml:"width,attr"`
+	Height     int            `xml:"height,attr"`
+	OffsetX    float64        `xml:"offsetx,attr"`
+	OffsetY    float64        `xml:"offsety,attr"`
+	Opacity    *float64       `xml:"opacity,attr"`
+	Visible    *int           `xml:"visible,attr"`
+	Data       tmxData        `xml:"data"`
+	Objects    []tmxObject    `xml:"object"`
+	Properties *tmxProperties `xml:"properties"`
+
+	// Layers are the layers of a group, in order, along with any other unknown elements
+	Layers []tmxLayer `xml:",any"`
+}
+
+type tmxPoints string
+
+func (tp tmxPoints) parse() ([]pixel.Vec, error) {
+	var points []pixel.Vec
+	for _, pair := range strings.Fields(string(tp)) {
+		xy := strings.Split(pair, ",")
+		if len(xy) != 2 {
+			return nil, errors.Errorf("invalid point: %s", pair)
+		}
+		x, err := strconv.ParseFloat(xy[0], 64)
+		if err != nil {
+			return nil, errors.Wrap(err, "invalid point")
+		}
+		y, err := strconv.ParseFloat(xy[1], 64)
+		if err != nil {
+			return nil, errors.Wrap(err, "invalid point")
+		}
+		points = append(points, pixel.V(x, y))
+	}
+	return points, nil
+}
+
+type tmxObject struct {
+	ID         int            `xml:"id,attr"`
+	Name       string         `xml:"name,attr"`
+	Type       string         `xml:"type,attr"`
+	Class      string         `xml:"class,attr"`
+	X          float64        `xml:"x,attr"`
+	Y          float64        `xml:"y,attr"`
+	Width      float64        `xml:"width,attr"`
+	Height     float64        `xml:"height,attr"`
+	Rotation   float64        `xml:"rotation,attr"`
+	GID        uint32         `xml:"gid,attr"`
+	Visible    *int           `xml:"visible,attr"`
+	Ellipse    *struct{}      `xml:"ellipse"`
+	Point      *struct{}      `xml:"point"`
+	Polygon    *tmxPolyPoints `xml:"polygon"`
+	Polyline   *tmxPolyPoints `xml:"polyline"`
+	Properties *tmxProperties `xml:"properties"`
+}
+
+type tmxPolyPoints struct {
+	Points tmxPoints `xml:"points,attr"`
+}
+
+func (to *tmxObject) convert() (rawObject, error) {
+	raw := rawObject{
+		id:         to.ID,
+		name:       to.Name,
+		typ:        to.Type,
+		x:          to.X,
+		y:          to.Y,
+		width:      to.Width,
+		height:     to.Height,
+		rotation:   to.Rotation,
+		gid:        Tile(to.GID),
+		visible:    to.Visible == nil || *to.Visible != 0,
+		ellipse:    to.Ellipse != nil,
+		point:      to.Point != nil,
+		properties: to.Properties.convert(),
+	}
+	if raw.typ == "" {
+		raw.typ = to.Class
+	}
+	var err error
+	if to.Polygon != nil {
+		if raw.polygon, err = to.Polygon.Points.parse(); err != nil {
+			return raw, err
+		}
+	}
+	if to.Polyline != nil {
+		if raw.polyline, err = to.Polyline.Points.parse(); err != nil {
+			return raw, err
+		}
+	}
+	return raw, nil
+}
+
+type tmxMap struct {
+	Orientation string         `xml:"orientation,attr"`
+	Width       int            `xml:"width,attr"`
+	Height      int            `xml:"height,attr"`
+	TileWidth   int            `xml:"tilewidth,attr"`
+	TileHeight  int            `xml:"tileheight,attr"`
+	Infinite    int            `xml:"infinite,attr"`
+	Tilesets    []tmxTileset   `xml:"tileset"`
+	Properties  *tmxProperties `xml:"properties"`
+
+	// Layers are all layers of the map in order, along with any other unknown elements
+	Layers []tmxLayer `xml:",any"`
+}
+
+func opacity(o *float64) float64 {
+	if o == nil {
+		return 1
+	}
+	return *o
+}
+
+// ReadTMX reads a map in the TMX (XML) format.
+//
+// Tileset images and external tilesets are not loaded, Tileset.Picture of all Tilesets has to be
+// set manually before drawing the map. Use Load to load a map including tilesets and images.
+func ReadTMX(r io.Reader) (*Map, error) {
+	var tm tmxMap
+	if err := xml.NewDecoder(r).Decode(&tm); err != nil {
+		return nil, errors.Wrap(err, "failed to decode TMX map")
+	}
+	if tm.Infinite != 0 {
+		return nil, errors.New("infinite maps are not supported")
+	}
+
+	m := &Map{
+		Width:       tm.Width,
+		Height:      tm.Height,
+		TileWidth:   tm.TileWidth,
+		TileHeight:  tm.TileHeight,
+		Orientation: tm.Orientation,
+		Properties:  tm.Properties.convert(),
+	}
+	if m.Orientation != "orthogonal" {
+		return nil, errors.Errorf("unsupported map orientation: %s", m.Orientation)
+	}
+
+	for i := range tm.Tilesets {
+		m.Tilesets = append(m.Tilesets, tm.Tilesets[i].convert())
+	}
+
+	if err := m.addTMXLayers(tm.Layers, rootGroup); err != nil {
+		return nil, err
+	}
+
+	return m, nil
+}
+
+// addTMXLayers adds the layers inside of the group to the Map, in order.
+func (m *Map) addTMXLayers(layers []tmxLayer, g group) error {
+	for i := range layers {
+		tl := &layers[i]
+		lg := g.nest(pixel.V(tl.OffsetX, -tl.OffsetY), opacity(tl.Opacity), tl.Visible == nil || *tl.Visible != 0)
+
+		switch tl.XMLName.Local {
+		case "layer":
+			tiles, err := tl.Data.decode()
+			if err != nil {
+				return errors.Wrapf(err, "layer %q", tl.Name)
+			}
+			if err := checkTiles(tl.Name, tiles, tl.Width, tl.Height); err != nil {
+				return err
+			}
+			m.addTileLayer(&TileLayer{
+				Name:       tl.Name,
+				Width:      tl.Width,
+				Height:     tl.Height,
+				Offset:     lg.offset,
+				Opacity:    lg.opacity,
+				Visible:    lg.visible,
+				Tiles:      tiles,
+				Properties: tl.Properties.convert(),
+			})
+
+		case "objectgroup":
+			ol := &ObjectLayer{
+				Name:       tl.Name,
+				Offset:     lg.offset,
+				Opacity:    lg.opacity,
+				Visible:    lg.visible,
+				Properties: tl.Properties.convert(),
+			}
+			for i := range tl.Objects {
+				raw, err := tl.Objects[i].convert()
+				if err != nil {
+					return errors.Wrapf(err, "object layer %q", tl.Name)
+				}
+				ol.Objects = append(ol.Objects, m.makeObject(raw, ol.Offset))
+			}
+			m.addObjectLayer(ol)
+
+		case "group":
+			if err := m.addTMXLayers(tl.Layers, lg); err != nil {
+				return errors.Wrapf(err, "group %q", tl.Name)
+			}
+
+		case "imagelayer":
+			return errors.Errorf("image layer %q: image layers are not supported", tl.Name)
+		}
+	}
+	return nil
+}
+
+// readTSX reads an external tileset in the TSX (XML) format.
+func readTSX(r io.Reader) (*Tileset, error) {
+	var tt tmxTileset
+	if err := xml.NewDecoder(r).Decode(&tt); err != nil {
+		return nil, errors.Wrap(err, "failed to decode TSX tileset")
+	}
+	return tt.convert(), nil
+}